@@ -36,6 +36,9 @@ interface Category {
   parentId: number | null;
   displayOrder: number;
   imageUrl: string | null;
+  metaTitle?: string | null;
+  metaDescription?: string | null;
+  h1?: string | null;
   isActive: boolean;
   children: Category[];
 }
@@ -84,6 +87,9 @@ export const CategoryList = () => {
       description: cat.description || "",
       parentId: cat.parentId || undefined,
       isActive: cat.isActive,
+      metaTitle: cat.metaTitle || "",
+      metaDescription: cat.metaDescription || "",
+      h1: cat.h1 || "",
     });
     setModalOpen(true);
   };
@@ -154,15 +160,33 @@ export const CategoryList = () => {
       newParentId = dropKey;
     }
 
-    // Calculate display order based on drop position
-    const displayOrder = dropToGap
-      ? Math.max(0, info.dropPosition)
-      : 0;
+    // Siblings in the new parent, in their current order, without the dragged node
+    const siblings = (
+      newParentId === null
+        ? categories
+        : findCategory(categories, newParentId)?.children ?? []
+    ).filter((c) => c.id !== dragKey);
+
+    // Insert position among siblings
+    let index = 0;
+    if (dropToGap) {
+      const dropIndex = siblings.findIndex((c) => c.id === dropKey);
+      const pos = info.node.pos.split("-");
+      const relative = info.dropPosition - Number(pos[pos.length - 1]);
+      index = relative === -1 ? dropIndex : dropIndex + 1;
+    }
+    const ordered = [
+      ...siblings.slice(0, index).map((c) => c.id),
+      dragKey,
+      ...siblings.slice(index).map((c) => c.id),
+    ];
 
     try {
-      await api.put(`/admin/categories/${dragKey}`, {
+      await api.post(`/admin/categories/${dragKey}/move`, {
         parentId: newParentId === null ? 0 : newParentId,
-        displayOrder,
+      });
+      await api.post("/admin/categories/reorder", {
+        items: ordered.map((id, i) => ({ id, displayOrder: i })),
       });
       message.success("Порядок обновлён");
       fetchCategories();
@@ -173,6 +197,7 @@ export const CategoryList = () => {
       message.error(
         axiosErr.response?.data?.error?.message || "Ошибка при перемещении"
       );
+      fetchCategories();
     }
   };
 
@@ -373,6 +398,22 @@ export const CategoryList = () => {
             />
           </Form.Item>
 
+          <Form.Item name="h1" label="Заголовок H1">
+            <Input placeholder="По умолчанию — название категории" />
+          </Form.Item>
+
+          <Form.Item
+            name="metaTitle"
+            label="Meta title"
+            rules={[{ max: 255, message: "Максимум 255 символов" }]}
+          >
+            <Input />
+          </Form.Item>
+
+          <Form.Item name="metaDescription" label="Meta description">
+            <Input.TextArea rows={2} />
+          </Form.Item>
+
           {editing && (
             <Form.Item
               name="isActive"
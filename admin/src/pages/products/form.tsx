@@ -15,6 +15,7 @@ import {
   Spin,
   App,
   Typography,
+  Tag,
 } from "antd";
 import {
   SaveOutlined,
@@ -22,6 +23,7 @@ import {
   PlusOutlined,
   DeleteOutlined,
   StarOutlined,
+  ReloadOutlined,
   StarFilled,
 } from "@ant-design/icons";
 import type { UploadFile } from "antd";
@@ -38,6 +40,8 @@ interface ProductImage {
   urlMedium?: string;
   isMain: boolean;
   displayOrder: number;
+  status: "pending" | "processing" | "ready" | "failed";
+  error?: string;
 }
 
 interface CategoryOption {
@@ -55,6 +59,12 @@ const MATERIALS = [
   { value: "Nylon", label: "Нейлон (Nylon)" },
 ];
 
+const FULFILLMENT_MODES = [
+  { value: "in_stock", label: "Со склада" },
+  { value: "made_to_order", label: "Под заказ" },
+  { value: "hybrid", label: "Склад, затем под заказ" },
+];
+
 export const ProductForm = () => {
   const { message } = App.useApp();
   const navigate = useNavigate();
@@ -98,6 +108,7 @@ export const ProductForm = () => {
         dimensionHeight: p.dimensions?.height,
         material: p.material,
         printTime: p.printTime,
+        fulfillmentMode: p.fulfillmentMode || "in_stock",
         categoryId: p.categoryId,
         isActive: p.isActive,
         isFeatured: p.isFeatured,
@@ -143,6 +154,7 @@ export const ProductForm = () => {
       if (values.material) body.material = values.material;
       if (values.printTime !== undefined && values.printTime !== null)
         body.printTime = values.printTime;
+      if (values.fulfillmentMode) body.fulfillmentMode = values.fulfillmentMode;
       if (values.categoryId) body.categoryId = values.categoryId;
       if (values.isFeatured !== undefined) body.isFeatured = values.isFeatured;
       if (isEdit && values.isActive !== undefined)
@@ -224,6 +236,20 @@ export const ProductForm = () => {
     }
   };
 
+  const handleReprocessImage = async (imageId: number) => {
+    try {
+      await api.post(`/admin/products/images/${imageId}/reprocess`);
+      setImages((prev) =>
+        prev.map((img) =>
+          img.id === imageId ? { ...img, status: "pending", error: undefined } : img
+        )
+      );
+      message.success("Изображение поставлено в очередь");
+    } catch {
+      message.error("Ошибка при повторной обработке");
+    }
+  };
+
   const handleDeleteImage = async (imageId: number) => {
     try {
       await api.delete(`/admin/products/images/${imageId}`);
@@ -271,7 +297,12 @@ export const ProductForm = () => {
       <Form
         form={form}
         layout="vertical"
-        initialValues={{ isActive: true, isFeatured: false, stockQuantity: 0 }}
+        initialValues={{
+          isActive: true,
+          isFeatured: false,
+          stockQuantity: 0,
+          fulfillmentMode: "in_stock",
+        }}
       >
         <Row gutter={24}>
           {/* Left column: main fields */}
@@ -394,6 +425,15 @@ export const ProductForm = () => {
                         height={120}
                         style={{ objectFit: "cover" }}
                       />
+                      {img.status && img.status !== "ready" && (
+                        <Tag
+                          color={img.status === "failed" ? "red" : "processing"}
+                          title={img.error}
+                          style={{ position: "absolute", top: 4, left: 4 }}
+                        >
+                          {img.status === "failed" ? "Ошибка" : "Обработка"}
+                        </Tag>
+                      )}
                       <div
                         style={{
                           position: "absolute",
@@ -420,6 +460,15 @@ export const ProductForm = () => {
                           onClick={() => handleSetMain(img.id)}
                           title="Сделать главным"
                         />
+                        {img.status === "failed" && (
+                          <Button
+                            type="text"
+                            size="small"
+                            icon={<ReloadOutlined style={{ color: "#fff" }} />}
+                            onClick={() => handleReprocessImage(img.id)}
+                            title="Обработать заново"
+                          />
+                        )}
                         <Button
                           type="text"
                           size="small"
@@ -505,6 +554,10 @@ export const ProductForm = () => {
               <Form.Item name="stockQuantity" label="Остаток (шт)">
                 <InputNumber min={0} style={{ width: "100%" }} />
               </Form.Item>
+
+              <Form.Item name="fulfillmentMode" label="Наличие">
+                <Select options={FULFILLMENT_MODES} />
+              </Form.Item>
             </Card>
 
             <Card title="Настройки" style={{ marginBottom: 16 }}>
@@ -85,13 +85,21 @@ func main() {
 	authService := service.NewAuthService(userRepo, authTokenService, cfg.Telegram.BotToken, log)
 	categoryService := service.NewCategoryService(categoryRepo, cacheStore, log)
 	productService := service.NewProductService(productRepo, categoryRepo, cacheStore, log)
-	imageService := service.NewImageService(productImageRepo, productRepo, s3Client, log)
+	imageService := service.NewImageService(productImageRepo, productRepo, s3Client, cfg.Image, log)
+	imageService.SetCache(cacheStore)
 	cartService := service.NewCartService(cartRepo, productRepo, log)
+	cartService.SetGuestCarts(cacheStore, cfg.Cart)
+	authService.SetCartService(cartService)
 	promoService := service.NewPromoService(promoRepo, log)
 	orderRepo := postgres.NewOrderRepo(db)
 	customOrderRepo := postgres.NewCustomOrderRepo(db)
 	orderService := service.NewOrderService(orderRepo, productRepo, userRepo, promoService, db, log)
+	orderService.SetOrderSettingsRepo(postgres.NewOrderSettingsRepo(db))
+	orderService.SetOrderChangeRepo(postgres.NewOrderChangeRepo(db))
+	orderNumbers := service.NewOrderNumberGenerator(orderRepo, cfg.OrderNumber)
+	orderService.SetOrderNumberGenerator(orderNumbers)
 	customOrderService := service.NewCustomOrderService(orderRepo, customOrderRepo, userRepo, db, log)
+	customOrderService.SetOrderNumberGenerator(orderNumbers)
 
 	// Delivery
 	deliveryZoneRepo := postgres.NewDeliveryZoneRepo(db)
@@ -100,6 +108,20 @@ func main() {
 	deliveryService := service.NewDeliveryService(mockProvider, deliveryZoneRepo, pickupPointRepo, log)
 	orderService.SetDeliveryService(deliveryService)
 
+	// Address book (also used to fill orders placed with addressId)
+	addressService := service.NewAddressService(postgres.NewAddressRepo(db), pickupPointRepo, log)
+	orderService.SetAddressService(addressService)
+	orderService.SetCartService(cartService)
+
+	// B2B company requisites (orders paid by bank-transfer invoice are billed to them)
+	companyService := service.NewCompanyService(postgres.NewCompanyRepo(db), log)
+	orderService.SetCompanyService(companyService)
+
+	// Quantity price breaks and customer price lists (wholesale, partner)
+	priceListService := service.NewPriceListService(postgres.NewPriceListRepo(db), productRepo, log)
+	orderService.SetPriceListService(priceListService)
+	cartService.SetPriceListService(priceListService)
+
 	// Payment (provider-agnostic; swap mockpayment for yookassa/tinkoff when ready)
 	paymentProvider := mockpayment.New(cfg.Payment.AppURL)
 	paymentService := service.NewPaymentService(paymentProvider, orderRepo, db, log, cfg.Payment.AppURL)
@@ -124,6 +146,12 @@ func main() {
 		}
 	}
 
+	// PDF documents: packing slips, customer invoices, bank-transfer invoices
+	documentService, err := service.NewDocumentService(orderRepo, pickupPointRepo, cfg.Company, log)
+	if err != nil {
+		log.Fatal("failed to initialize document service", zap.Error(err))
+	}
+
 	// Email (optional)
 	var emailService *service.EmailService
 	if cfg.SMTP.IsConfigured() {
@@ -133,11 +161,23 @@ func main() {
 		} else {
 			log.Info("email service initialized", zap.String("from", cfg.SMTP.FromEmail))
 			emailService = es
+			emailService.SetDocumentService(documentService)
 			orderService.SetEmailService(emailService)
 			customOrderService.SetEmailService(emailService)
 		}
 	}
 
+	// Digital products (download links are issued by the payment service)
+	digitalRepo := postgres.NewDigitalRepo(db)
+	digitalService := service.NewDigitalService(digitalRepo, productRepo, orderRepo, s3Client, cfg.Digital, log)
+	if emailService != nil {
+		digitalService.SetEmailService(emailService)
+	}
+	if telegramBot != nil {
+		digitalService.SetNotifier(telegramBot)
+	}
+	paymentService.SetDigitalService(digitalService)
+
 	// Loyalty
 	bonusTransactionRepo := postgres.NewBonusTransactionRepo(db)
 	loyaltySettingsRepo := postgres.NewLoyaltySettingsRepo(db)
@@ -158,10 +198,76 @@ func main() {
 	contentBlockRepo := postgres.NewContentBlockRepo(db)
 	contentService := service.NewContentService(contentBlockRepo, cacheStore, log)
 
+	// Translations: catalog text in the visitor's language, falling back to the default locale
+	translationService := service.NewTranslationService(postgres.NewTranslationRepo(db), productRepo, categoryRepo, contentBlockRepo, cfg.I18n, cacheStore, log)
+	productService.SetTranslationService(translationService)
+	categoryService.SetTranslationService(translationService)
+	contentService.SetTranslationService(translationService)
+
 	// Analytics
 	analyticsRepo := postgres.NewAnalyticsRepo(db)
 	analyticsService := service.NewAnalyticsService(analyticsRepo, db, log)
 
+	// Sale campaigns and price history
+	saleCampaignRepo := postgres.NewSaleCampaignRepo(db)
+	priceHistoryRepo := postgres.NewPriceHistoryRepo(db)
+	saleCampaignService := service.NewSaleCampaignService(saleCampaignRepo, priceHistoryRepo, db, cacheStore, log)
+	productService.SetPriceHistoryRepo(priceHistoryRepo)
+	productService.SetRevisionRepo(postgres.NewProductRevisionRepo(db))
+
+	// View and sales counters
+	productStatsService := service.NewProductStatsService(redisClient, db, productRepo, log)
+	orderService.SetProductStatsService(productStatsService)
+	paymentService.SetProductStatsService(productStatsService)
+
+	// Wishlists and back-in-stock notifications
+	wishlistService := service.NewWishlistService(postgres.NewWishlistRepo(db), productRepo, log)
+	stockAlertService := service.NewStockAlertService(postgres.NewStockSubscriptionRepo(db), productRepo, userRepo, cfg.Payment.AppURL, log)
+	if emailService != nil {
+		stockAlertService.SetEmailService(emailService)
+	}
+	if telegramBot != nil {
+		stockAlertService.SetNotifier(telegramBot)
+	}
+
+	// Stock ledger: every change of product stock is a movement
+	stockService := service.NewStockService(postgres.NewStockMovementRepo(db), productRepo, db, cacheStore, log)
+	stockService.SetStockAlertService(stockAlertService)
+	productService.SetStockService(stockService)
+	orderService.SetStockService(stockService)
+
+	// Returns (RMA): refunds go through the payment provider or to bonuses
+	returnService := service.NewReturnService(postgres.NewReturnRepo(db), orderRepo, db, log)
+	returnService.SetPaymentService(paymentService)
+	returnService.SetLoyaltyService(loyaltyService)
+	returnService.SetStockService(stockService)
+	if s3Client != nil {
+		returnService.SetS3Client(s3Client)
+	}
+
+	// Abandoned cart reminders
+	cartReminderService := service.NewCartReminderService(postgres.NewCartReminderRepo(db), cartRepo, userRepo, promoRepo, cfg.CartReminder, cfg.Payment.AppURL, cfg.Digital.APIURL, log)
+	if emailService != nil {
+		cartReminderService.SetEmailService(emailService)
+	}
+	if telegramBot != nil {
+		cartReminderService.SetNotifier(telegramBot)
+	}
+	orderService.SetCartReminderService(cartReminderService)
+
+	// Slug history: old product and category links redirect to the new slug
+	slugRedirectService := service.NewSlugRedirectService(postgres.NewSlugRedirectRepo(db), productRepo, categoryRepo, log)
+	productService.SetSlugRedirectService(slugRedirectService)
+	categoryService.SetSlugRedirectService(slugRedirectService)
+
+	// Sitemap, robots.txt and structured data
+	seoService := service.NewSEOService(db, productRepo, cacheStore, cfg.Payment.AppURL, log)
+
+	// Production queue (made-to-order lead times)
+	productionService := service.NewProductionService(db, cfg.Production, log)
+	productService.SetProductionService(productionService)
+	orderService.SetProductionService(productionService)
+
 	// Bitrix24 service (optional; route registered after router is created)
 	var bitrixHandler *handler.BitrixHandler
 	if cfg.Bitrix.IsConfigured() {
@@ -177,8 +283,9 @@ func main() {
 	// Handlers
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
+	addressHandler := handler.NewAddressHandler(addressService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	productHandler := handler.NewProductHandler(productService)
+	productHandler := handler.NewProductHandler(productService, productStatsService, priceListService)
 	imageHandler := handler.NewImageHandler(imageService)
 	cartHandler := handler.NewCartHandler(cartService)
 	promoHandler := handler.NewPromoHandler(promoService)
@@ -187,8 +294,26 @@ func main() {
 	reviewHandler := handler.NewReviewHandler(reviewService)
 	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService)
 	contentHandler := handler.NewContentHandler(contentService)
+	translationHandler := handler.NewTranslationHandler(translationService)
 	analyticsHandler := handler.NewAnalyticsHandler(analyticsService)
 	customOrderHandler := handler.NewCustomOrderHandler(customOrderService)
+	saleCampaignHandler := handler.NewSaleCampaignHandler(saleCampaignService)
+	digitalHandler := handler.NewDigitalHandler(digitalService)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService, stockAlertService)
+	seoHandler := handler.NewSEOHandler(seoService)
+	slugRedirectHandler := handler.NewSlugRedirectHandler(slugRedirectService)
+	cartReminderHandler := handler.NewCartReminderHandler(cartReminderService)
+	returnHandler := handler.NewReturnHandler(returnService)
+	documentHandler := handler.NewDocumentHandler(documentService)
+	companyHandler := handler.NewCompanyHandler(companyService)
+	priceListHandler := handler.NewPriceListHandler(priceListService)
+	stockHandler := handler.NewStockHandler(stockService)
+
+	// Idempotency-Key support for requests that create orders or payments
+	idempotencyMw := middleware.Idempotency(cacheStore, cfg.Idempotency.TTL, log)
+	orderHandler.SetIdempotency(idempotencyMw)
+	customOrderHandler.SetIdempotency(idempotencyMw)
+	paymentHandler.SetIdempotency(idempotencyMw)
 
 	// Set Gin mode
 	if cfg.IsProduction() {
@@ -204,8 +329,8 @@ func main() {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CORS.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Session-ID", "X-Cart-Token", "Idempotency-Key"},
+		ExposeHeaders:    []string{"Content-Length", "X-Cart-Token", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -218,30 +343,49 @@ func main() {
 		})
 	})
 
+	// Sitemap and robots.txt are served from the root for crawlers
+	seoHandler.RegisterRootRoutes(router)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	// Язык ответа: ?lang= или Accept-Language, по умолчанию — I18N_DEFAULT_LOCALE.
+	v1.Use(middleware.Locale(cfg.I18n.Locales, cfg.I18n.DefaultLocale))
 	v1.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "pong"})
 	})
 	authHandler.RegisterRoutes(v1)
 	categoryHandler.RegisterPublicRoutes(v1)
-	productHandler.RegisterPublicRoutes(v1)
 	promoHandler.RegisterPublicRoutes(v1)
-	orderHandler.RegisterPublicRoutes(v1)
 	deliveryHandler.RegisterPublicRoutes(v1)
 	reviewHandler.RegisterPublicRoutes(v1)
 	contentHandler.RegisterPublicRoutes(v1)
+	digitalHandler.RegisterPublicRoutes(v1)
+	seoHandler.RegisterPublicRoutes(v1)
+	cartReminderHandler.RegisterPublicRoutes(v1)
 	// Публичные роуты custom-orders с опциональной авторизацией:
 	// если токен есть — userID попадает в контекст и заказ привязывается к аккаунту.
 	optionalAuthMw := middleware.OptionalAuth(jwtManager)
 	customOrderHandler.RegisterPublicRoutes(v1.Group("", optionalAuthMw))
+	// Заказы: авторизованный покупатель получает заказ в аккаунт и может оформить его по адресу из адресной книги.
+	orderHandler.RegisterPublicRoutes(v1.Group("", optionalAuthMw))
+	documentHandler.RegisterPublicRoutes(v1)
+	// Товары: просмотры авторизованных пользователей учитываются по userID.
+	productHandler.RegisterPublicRoutes(v1.Group("", optionalAuthMw))
+	// Подписка «сообщить о поступлении»: гости — по email, пользователи — также в Telegram.
+	wishlistHandler.RegisterPublicRoutes(v1.Group("", optionalAuthMw))
 	authMw := middleware.AuthRequired(jwtManager)
 	customOrderHandler.RegisterProtectedRoutes(v1.Group("", authMw))
 	userHandler.RegisterProtectedRoutes(v1.Group("", authMw))
+	addressHandler.RegisterProtectedRoutes(v1.Group("", authMw))
+	companyHandler.RegisterProtectedRoutes(v1.Group("", authMw))
 	reviewHandler.RegisterProtectedRoutes(v1.Group("", authMw))
 	orderHandler.RegisterProtectedRoutes(v1.Group("", authMw))
 	loyaltyHandler.RegisterProtectedRoutes(v1.Group("", authMw))
-	cartHandler.RegisterRoutes(v1, authMw)
+	digitalHandler.RegisterProtectedRoutes(v1.Group("", authMw))
+	wishlistHandler.RegisterProtectedRoutes(v1.Group("", authMw))
+	returnHandler.RegisterProtectedRoutes(v1.Group("", authMw))
+	// Корзина: гости работают с корзиной в Redis по X-Cart-Token, при входе она переносится в аккаунт.
+	cartHandler.RegisterRoutes(v1, optionalAuthMw)
 
 	// Protected admin routes
 	admin := v1.Group("/admin")
@@ -263,6 +407,16 @@ func main() {
 	contentHandler.RegisterAdminRoutes(admin)
 	analyticsHandler.RegisterAdminRoutes(admin)
 	customOrderHandler.RegisterAdminRoutes(admin)
+	saleCampaignHandler.RegisterAdminRoutes(admin)
+	digitalHandler.RegisterAdminRoutes(admin)
+	slugRedirectHandler.RegisterAdminRoutes(admin)
+	translationHandler.RegisterAdminRoutes(admin)
+	cartReminderHandler.RegisterAdminRoutes(admin)
+	returnHandler.RegisterAdminRoutes(admin)
+	documentHandler.RegisterAdminRoutes(admin)
+	companyHandler.RegisterAdminRoutes(admin)
+	priceListHandler.RegisterAdminRoutes(admin)
+	stockHandler.RegisterAdminRoutes(admin)
 
 	// Payment routes
 	paymentHandler.RegisterWebhookRoute(router)        // POST /webhook/payment
@@ -288,9 +442,14 @@ func main() {
 		IdleTimeout:  30 * time.Second,
 	}
 
-	// Background stats aggregation
-	aggCtx, aggCancel := context.WithCancel(context.Background())
-	go analyticsService.StartBackgroundAggregation(aggCtx)
+	// Background jobs: stats aggregation, sale campaign scheduler, view counter flush
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	go analyticsService.StartBackgroundAggregation(bgCtx)
+	go saleCampaignService.StartScheduler(bgCtx)
+	go productStatsService.StartViewFlusher(bgCtx)
+	go imageService.StartWorkers(bgCtx)
+	go orderService.StartUnpaidOrderCanceller(bgCtx)
+	go cartReminderService.StartScheduler(bgCtx)
 
 	// Start server in goroutine
 	go func() {
@@ -307,7 +466,7 @@ func main() {
 
 	log.Info("shutting down server...")
 
-	aggCancel()
+	bgCancel()
 
 	if telegramBot != nil {
 		telegramBot.Stop()
@@ -42,6 +42,16 @@ func (s *Store) Set(ctx context.Context, key string, value interface{}, ttl time
 	return s.client.Set(ctx, key, data, ttl).Err()
 }
 
+// SetNX stores a value with TTL only if the key does not exist yet.
+// Returns false if the key was already set.
+func (s *Store) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return s.client.SetNX(ctx, key, data, ttl).Result()
+}
+
 // Delete removes a key from cache.
 func (s *Store) Delete(ctx context.Context, key string) error {
 	return s.client.Del(ctx, key).Err()
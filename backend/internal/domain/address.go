@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAddressNotFound   = errors.New("address not found")
+	ErrAddressIncomplete = errors.New("address is missing required fields")
+)
+
+// Address types match the delivery methods they are used with.
+const (
+	AddressTypeCourier     = "courier"
+	AddressTypePickupPoint = "pickup_point"
+)
+
+// UserAddress is an entry of a customer's address book: a courier address or
+// a preferred pickup point, with the recipient's contacts.
+type UserAddress struct {
+	ID            int          `gorm:"primaryKey" json:"id"`
+	UserID        int          `gorm:"not null" json:"-"`
+	Type          string       `gorm:"not null" json:"type"`
+	Label         *string      `json:"label,omitempty"`
+	RecipientName *string      `json:"recipientName,omitempty"`
+	Phone         *string      `json:"phone,omitempty"`
+	City          *string      `json:"city,omitempty"`
+	Address       *string      `json:"address,omitempty"`
+	PickupPointID *int         `json:"pickupPointId,omitempty"`
+	PickupPoint   *PickupPoint `gorm:"foreignKey:PickupPointID" json:"pickupPoint,omitempty"`
+	IsDefault     bool         `gorm:"not null;default:false" json:"isDefault"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+func (UserAddress) TableName() string {
+	return "user_addresses"
+}
+
+// AddressRepository defines data access for the address book.
+type AddressRepository interface {
+	ListByUserID(ctx context.Context, userID int) ([]UserAddress, error)
+	FindByID(ctx context.Context, id, userID int) (*UserAddress, error)
+	// Save creates or updates an address. A default address replaces the
+	// previous default of the same type.
+	Save(ctx context.Context, address *UserAddress) error
+	Delete(ctx context.Context, id, userID int) error
+}
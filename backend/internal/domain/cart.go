@@ -14,18 +14,24 @@ type CartItem struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 	Product   Product   `gorm:"foreignKey:ProductID" json:"product"`
+	// UnitPrice and TotalPrice are the customer's price for this quantity, see CartService.
+	UnitPrice  float64 `gorm:"-" json:"unitPrice"`
+	TotalPrice float64 `gorm:"-" json:"totalPrice"`
 }
 
 type Cart struct {
 	Items      []CartItem `json:"items"`
 	TotalItems int        `json:"totalItems"`
 	TotalPrice float64    `json:"totalPrice"`
+	// Token identifies a guest cart; empty for carts of logged-in users.
+	Token string `json:"cartToken,omitempty"`
 }
 
 var (
 	ErrCartItemNotFound  = errors.New("cart item not found")
 	ErrInsufficientStock = errors.New("insufficient stock")
 	ErrProductInactive   = errors.New("product is not active")
+	ErrInvalidCartToken  = errors.New("invalid cart token")
 )
 
 type CartRepository interface {
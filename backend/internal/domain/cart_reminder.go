@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrCartReminderCampaignNotFound = errors.New("cart reminder campaign not found")
+	ErrCartReminderNotFound         = errors.New("cart reminder not found")
+	ErrCartReminderInvalidPromo     = errors.New("invalid cart reminder promo settings")
+)
+
+// Cart reminder channels.
+const (
+	CartReminderChannelTelegram = "telegram"
+	CartReminderChannelEmail    = "email"
+)
+
+// CartReminderCampaign describes when to remind about an abandoned cart and
+// whether to attach a single-use promo code. Promo fields are nil for
+// reminders without a discount.
+type CartReminderCampaign struct {
+	ID                 int       `gorm:"primaryKey" json:"id"`
+	Name               string    `gorm:"not null" json:"name"`
+	IsActive           bool      `gorm:"not null;default:true" json:"isActive"`
+	DelayHours         int       `gorm:"not null" json:"delayHours"`
+	MinCartTotal       float64   `gorm:"type:decimal(10,2);not null;default:0" json:"minCartTotal"`
+	PromoDiscountType  *string   `json:"promoDiscountType,omitempty"`
+	PromoDiscountValue *float64  `gorm:"type:decimal(10,2)" json:"promoDiscountValue,omitempty"`
+	PromoValidHours    int       `gorm:"not null;default:72" json:"promoValidHours"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+func (CartReminderCampaign) TableName() string {
+	return "cart_reminder_campaigns"
+}
+
+// HasPromo reports whether reminders of the campaign carry a promo code.
+func (c *CartReminderCampaign) HasPromo() bool {
+	return c.PromoDiscountType != nil && c.PromoDiscountValue != nil
+}
+
+// CartReminder is a reminder sent to one user.
+type CartReminder struct {
+	ID          int        `gorm:"primaryKey" json:"id"`
+	CampaignID  *int       `json:"campaignId,omitempty"`
+	UserID      int        `gorm:"not null" json:"userId"`
+	Channel     string     `gorm:"not null" json:"channel"`
+	Token       string     `gorm:"not null" json:"-"`
+	CartTotal   float64    `gorm:"type:decimal(10,2);not null" json:"cartTotal"`
+	PromoCodeID *int       `json:"promoCodeId,omitempty"`
+	SentAt      time.Time  `json:"sentAt"`
+	ClickedAt   *time.Time `json:"clickedAt,omitempty"`
+	ConvertedAt *time.Time `json:"convertedAt,omitempty"`
+	OrderID     *int       `json:"orderId,omitempty"`
+}
+
+func (CartReminder) TableName() string {
+	return "cart_reminders"
+}
+
+// AbandonedCart is a user's cart that has not changed since UpdatedAt.
+// Total counts active products only.
+type AbandonedCart struct {
+	UserID    int
+	UpdatedAt time.Time
+	Total     float64
+}
+
+// CartReminderMessage is the content of one reminder.
+type CartReminderMessage struct {
+	Items          []CartItem
+	Total          float64
+	CartURL        string
+	UnsubscribeURL string
+	// Promo is the single-use code created for this reminder, if any.
+	Promo *PromoCode
+}
+
+// CartReminderNotifier delivers abandoned cart reminders via Telegram.
+type CartReminderNotifier interface {
+	NotifyAbandonedCart(ctx context.Context, telegramID int64, msg *CartReminderMessage) error
+}
+
+// CartReminderStats are the counters of one campaign.
+type CartReminderStats struct {
+	CampaignID int     `json:"campaignId"`
+	Name       string  `json:"name"`
+	Sent       int64   `json:"sent"`
+	Clicked    int64   `json:"clicked"`
+	Converted  int64   `json:"converted"`
+	Revenue    float64 `json:"revenue"`
+}
+
+// CartReminderRepository defines data access for abandoned cart reminders.
+type CartReminderRepository interface {
+	CreateCampaign(ctx context.Context, campaign *CartReminderCampaign) error
+	FindCampaignByID(ctx context.Context, id int) (*CartReminderCampaign, error)
+	ListCampaigns(ctx context.Context, onlyActive bool) ([]CartReminderCampaign, error)
+	UpdateCampaign(ctx context.Context, campaign *CartReminderCampaign) error
+	DeleteCampaign(ctx context.Context, id int) error
+
+	// FindAbandoned returns carts worth at least minTotal of active, reachable,
+	// not opted-out users that have not changed since before idleBefore, where
+	// the user has placed no order since the last change, the campaign has not
+	// reminded about this cart state yet and no reminder at all was sent after
+	// cooldownAfter.
+	FindAbandoned(ctx context.Context, campaignID int, minTotal float64, idleBefore, cooldownAfter time.Time, limit int) ([]AbandonedCart, error)
+
+	Create(ctx context.Context, reminder *CartReminder) error
+	Delete(ctx context.Context, id int) error
+	FindByToken(ctx context.Context, token string) (*CartReminder, error)
+	// MarkClicked sets clicked_at on the first click only.
+	MarkClicked(ctx context.Context, id int, at time.Time) error
+	// MarkConverted attributes an order to the user's latest unconverted
+	// reminder sent after since. Returns false if there is none.
+	MarkConverted(ctx context.Context, userID, orderID int, since, at time.Time) (bool, error)
+	Stats(ctx context.Context) ([]CartReminderStats, error)
+}
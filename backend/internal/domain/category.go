@@ -7,25 +7,30 @@ import (
 )
 
 var (
-	ErrCategoryNotFound  = errors.New("category not found")
-	ErrCategoryHasChildren = errors.New("category has subcategories")
-	ErrCategoryHasProducts = errors.New("category has products")
-	ErrCategorySlugExists  = errors.New("category slug already exists")
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryHasChildren    = errors.New("category has subcategories")
+	ErrCategoryHasProducts    = errors.New("category has products")
+	ErrCategorySlugExists     = errors.New("category slug already exists")
+	ErrCategoryParentNotFound = errors.New("parent category not found")
+	ErrCategoryCycle          = errors.New("category cannot be moved into its own subtree")
 )
 
 // Category represents a product category.
 type Category struct {
-	ID           int         `gorm:"primaryKey" json:"id"`
-	Name         string      `gorm:"not null" json:"name"`
-	Slug         string      `gorm:"uniqueIndex;not null" json:"slug"`
-	Description  *string     `json:"description,omitempty"`
-	ParentID     *int        `json:"parentId,omitempty"`
-	DisplayOrder int         `gorm:"default:0" json:"displayOrder"`
-	ImageURL     *string     `json:"imageUrl,omitempty"`
-	IsActive     bool        `gorm:"default:true" json:"isActive"`
-	CreatedAt    time.Time   `json:"createdAt"`
-	UpdatedAt    time.Time   `json:"updatedAt"`
-	Children     []Category  `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+	ID              int        `gorm:"primaryKey" json:"id"`
+	Name            string     `gorm:"not null" json:"name"`
+	Slug            string     `gorm:"uniqueIndex;not null" json:"slug"`
+	Description     *string    `json:"description,omitempty"`
+	ParentID        *int       `json:"parentId,omitempty"`
+	DisplayOrder    int        `gorm:"default:0" json:"displayOrder"`
+	ImageURL        *string    `json:"imageUrl,omitempty"`
+	MetaTitle       *string    `json:"metaTitle,omitempty"`
+	MetaDescription *string    `json:"metaDescription,omitempty"`
+	H1              *string    `gorm:"column:h1" json:"h1,omitempty"`
+	IsActive        bool       `gorm:"default:true" json:"isActive"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	Children        []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
 }
 
 func (Category) TableName() string {
@@ -38,8 +43,26 @@ type CategoryRepository interface {
 	FindByID(ctx context.Context, id int) (*Category, error)
 	FindBySlug(ctx context.Context, slug string) (*Category, error)
 	FindAll(ctx context.Context) ([]Category, error)
+	// Update saves the category. A parent that does not exist or lies inside
+	// the category's own subtree is rejected atomically with the save
+	// (ErrCategoryParentNotFound, ErrCategoryCycle).
 	Update(ctx context.Context, category *Category) error
 	Delete(ctx context.Context, id int) error
 	HasChildren(ctx context.Context, id int) (bool, error)
 	HasProducts(ctx context.Context, id int) (bool, error)
+	// FindRootIDs returns top-level category IDs in display order.
+	FindRootIDs(ctx context.Context) ([]int, error)
+	// FindTree returns the category with all its descendants nested.
+	FindTree(ctx context.Context, rootID int) (*Category, error)
+	// FindSubtreeIDs returns the category ID followed by all descendant IDs.
+	FindSubtreeIDs(ctx context.Context, id int) ([]int, error)
+	// FindAncestors returns the path from the root down to the category itself.
+	FindAncestors(ctx context.Context, id int) ([]Category, error)
+	UpdateDisplayOrders(ctx context.Context, orders []CategoryOrder) error
+}
+
+// CategoryOrder sets the display order of a single category in a bulk reorder.
+type CategoryOrder struct {
+	ID           int `json:"id" binding:"required,min=1"`
+	DisplayOrder int `json:"displayOrder"`
 }
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrCompanyNotFound = errors.New("company not found")
+	ErrInvalidINN      = errors.New("invalid INN")
+	ErrInvalidKPP      = errors.New("invalid KPP")
+	ErrInvalidOGRN     = errors.New("invalid OGRN")
+	// ErrCompanyRequired is returned when an invoice order is placed by a user without company requisites.
+	ErrCompanyRequired = errors.New("company requisites are required for invoice payment")
+)
+
+// Company holds the requisites of a legal entity or individual entrepreneur
+// a user orders for. Orders paid by invoice are billed to it.
+type Company struct {
+	ID           int       `gorm:"primaryKey" json:"id"`
+	UserID       int       `gorm:"not null;uniqueIndex" json:"userId"`
+	LegalName    string    `gorm:"not null" json:"legalName"`
+	INN          string    `gorm:"column:inn;not null" json:"inn"`
+	KPP          *string   `gorm:"column:kpp" json:"kpp,omitempty"`
+	OGRN         string    `gorm:"column:ogrn;not null" json:"ogrn"`
+	LegalAddress string    `gorm:"not null" json:"legalAddress"`
+	PriceListID  *int      `json:"priceListId,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (Company) TableName() string {
+	return "companies"
+}
+
+// IsEntrepreneur reports whether the company is an individual entrepreneur (12-digit INN).
+func (c *Company) IsEntrepreneur() bool {
+	return len(c.INN) == 12
+}
+
+// CompanyFilter selects companies in the admin list.
+type CompanyFilter struct {
+	// Search matches the legal name or INN.
+	Search string
+	Page   int
+	Limit  int
+}
+
+// CompanyRepository defines data access for company requisites.
+type CompanyRepository interface {
+	FindByID(ctx context.Context, id int) (*Company, error)
+	FindByUserID(ctx context.Context, userID int) (*Company, error)
+	// Save creates or updates the company of company.UserID.
+	Save(ctx context.Context, company *Company) error
+	DeleteByUserID(ctx context.Context, userID int) error
+	List(ctx context.Context, filter CompanyFilter) ([]Company, int64, error)
+}
+
+var (
+	innWeights10 = []int{2, 4, 10, 3, 5, 9, 4, 6, 8}
+	innWeights11 = []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+	innWeights12 = []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+
+	digitsRe = regexp.MustCompile(`^[0-9]+$`)
+	kppRe    = regexp.MustCompile(`^[0-9]{4}[0-9A-Z]{2}[0-9]{3}$`)
+)
+
+// ValidateINN checks the length and check digits of an INN: 10 digits for
+// organizations, 12 for individual entrepreneurs.
+func ValidateINN(inn string) error {
+	if !digitsRe.MatchString(inn) {
+		return ErrInvalidINN
+	}
+	switch len(inn) {
+	case 10:
+		if innCheckDigit(inn, innWeights10) != digitAt(inn, 9) {
+			return ErrInvalidINN
+		}
+	case 12:
+		if innCheckDigit(inn, innWeights11) != digitAt(inn, 10) ||
+			innCheckDigit(inn, innWeights12) != digitAt(inn, 11) {
+			return ErrInvalidINN
+		}
+	default:
+		return ErrInvalidINN
+	}
+	return nil
+}
+
+// ValidateKPP checks the KPP format. KPP has no check digit: 4 digits of the
+// tax office, 2 digits or letters of the reason code and a 3-digit number.
+func ValidateKPP(kpp string) error {
+	if !kppRe.MatchString(kpp) {
+		return ErrInvalidKPP
+	}
+	return nil
+}
+
+// ValidateOGRN checks an OGRN (13 digits, organizations) or OGRNIP (15 digits,
+// individual entrepreneurs): the last digit is the remainder of the rest
+// divided by 11 or 13, taken modulo 10.
+func ValidateOGRN(ogrn string) error {
+	if !digitsRe.MatchString(ogrn) {
+		return ErrInvalidOGRN
+	}
+	var divisor int64
+	switch len(ogrn) {
+	case 13:
+		divisor = 11
+	case 15:
+		divisor = 13
+	default:
+		return ErrInvalidOGRN
+	}
+	body, err := strconv.ParseInt(ogrn[:len(ogrn)-1], 10, 64)
+	if err != nil {
+		return ErrInvalidOGRN
+	}
+	if int(body%divisor%10) != digitAt(ogrn, len(ogrn)-1) {
+		return ErrInvalidOGRN
+	}
+	return nil
+}
+
+func innCheckDigit(inn string, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += w * digitAt(inn, i)
+	}
+	return sum % 11 % 10
+}
+
+func digitAt(s string, i int) int {
+	return int(s[i] - '0')
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateINN(t *testing.T) {
+	tests := []struct {
+		inn   string
+		valid bool
+	}{
+		{"7707083893", true},    // organization
+		{"7736207543", true},    // organization
+		{"500100732259", true},  // individual entrepreneur
+		{"773173084809", true},  // individual entrepreneur
+		{"7707083894", false},   // wrong check digit
+		{"500100732258", false}, // wrong second check digit
+		{"500100732269", false}, // wrong first check digit
+		{"770708389", false},
+		{"77070838933", false},
+		{"77070838a3", false},
+		{" 7707083893", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := ValidateINN(tt.inn)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateINN(%q) = %v, want nil", tt.inn, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidINN) {
+			t.Errorf("ValidateINN(%q) = %v, want ErrInvalidINN", tt.inn, err)
+		}
+	}
+}
+
+func TestValidateOGRN(t *testing.T) {
+	tests := []struct {
+		ogrn  string
+		valid bool
+	}{
+		{"1027700132195", true},   // OGRN
+		{"1027700229193", true},   // OGRN
+		{"304500116000157", true}, // OGRNIP
+		{"1027700132194", false},
+		{"304500116000158", false},
+		{"10277001321950", false},
+		{"102770013219", false},
+		{"10277OO132195", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := ValidateOGRN(tt.ogrn)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateOGRN(%q) = %v, want nil", tt.ogrn, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidOGRN) {
+			t.Errorf("ValidateOGRN(%q) = %v, want ErrInvalidOGRN", tt.ogrn, err)
+		}
+	}
+}
+
+func TestValidateKPP(t *testing.T) {
+	tests := []struct {
+		kpp   string
+		valid bool
+	}{
+		{"773601001", true},
+		{"7736AB001", true},
+		{"77360100", false},
+		{"7736010011", false},
+		{"773ab1001", false},
+		{"77360100A", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := ValidateKPP(tt.kpp)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateKPP(%q) = %v, want nil", tt.kpp, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidKPP) {
+			t.Errorf("ValidateKPP(%q) = %v, want ErrInvalidKPP", tt.kpp, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrProductFileNotFound  = errors.New("product file not found")
+	ErrDownloadLinkNotFound = errors.New("download link not found")
+	ErrDownloadLinkExpired  = errors.New("download link has expired")
+	ErrDownloadLimitReached = errors.New("download limit reached")
+)
+
+// ProductFile is a private downloadable file of a digital product (STL, 3MF, ZIP...).
+type ProductFile struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	ProductID   int       `gorm:"not null" json:"productId"`
+	FileName    string    `gorm:"not null" json:"fileName"`
+	S3Key       string    `gorm:"column:s3_key;not null" json:"-"`
+	ContentType string    `gorm:"not null" json:"contentType"`
+	SizeBytes   int64     `gorm:"default:0" json:"sizeBytes"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (ProductFile) TableName() string {
+	return "product_files"
+}
+
+// DownloadLink grants a buyer a limited number of downloads of one file until ExpiresAt.
+type DownloadLink struct {
+	ID               int          `gorm:"primaryKey" json:"id"`
+	Token            string       `gorm:"uniqueIndex;not null" json:"-"`
+	OrderID          int          `gorm:"not null" json:"orderId"`
+	UserID           *int         `json:"-"`
+	ProductID        int          `gorm:"not null" json:"productId"`
+	ProductFileID    int          `gorm:"not null" json:"productFileId"`
+	DownloadCount    int          `gorm:"default:0" json:"downloadCount"`
+	MaxDownloads     int          `gorm:"not null" json:"maxDownloads"`
+	ExpiresAt        time.Time    `gorm:"not null" json:"expiresAt"`
+	LastDownloadedAt *time.Time   `json:"lastDownloadedAt,omitempty"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	Product          *Product     `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	File             *ProductFile `gorm:"foreignKey:ProductFileID" json:"file,omitempty"`
+	Order            *Order       `gorm:"foreignKey:OrderID" json:"-"`
+	// URL is the public download endpoint, built by the service.
+	URL string `gorm:"-" json:"url"`
+}
+
+func (DownloadLink) TableName() string {
+	return "download_links"
+}
+
+// DigitalRepository defines data access for digital product files and download links.
+type DigitalRepository interface {
+	CreateFile(ctx context.Context, file *ProductFile) error
+	FindFileByID(ctx context.Context, id int) (*ProductFile, error)
+	ListFilesByProductIDs(ctx context.Context, productIDs []int) ([]ProductFile, error)
+	DeleteFile(ctx context.Context, id int) error
+
+	CreateLinks(ctx context.Context, links []DownloadLink) error
+	FindLinkByToken(ctx context.Context, token string) (*DownloadLink, error)
+	ListLinksByOrderID(ctx context.Context, orderID int) ([]DownloadLink, error)
+	ListLinksByUserID(ctx context.Context, userID int) ([]DownloadLink, error)
+	// ConsumeDownload atomically increments the download counter if the link is
+	// still valid. Returns false if the link is expired or used up.
+	ConsumeDownload(ctx context.Context, id int, now time.Time) (bool, error)
+}
+
+// DigitalDownloadNotifier delivers download links to the buyer.
+type DigitalDownloadNotifier interface {
+	NotifyDigitalDownloads(ctx context.Context, order *Order, links []DownloadLink) error
+}
@@ -23,11 +23,24 @@ type Order struct {
 	DeliveryAddress *string     `json:"deliveryAddress,omitempty"`
 	PaymentMethod   string      `gorm:"not null;default:card" json:"paymentMethod"`
 	IsPaid          bool        `gorm:"default:false" json:"isPaid"`
+	// PaidAmount is what the customer has paid so far. It differs from
+	// TotalPrice after an admin edit until the difference is paid or refunded.
+	PaidAmount      float64     `gorm:"type:decimal(10,2);default:0" json:"paidAmount"`
+	// SalesCounted is true once item quantities were added to products.sales_count.
+	SalesCounted    bool        `gorm:"default:false" json:"-"`
 	// Payment gateway fields (populated after InitiatePayment).
 	PaymentLink       *string    `json:"paymentLink,omitempty"`
 	PaymentProvider   *string    `json:"paymentProvider,omitempty"`
 	PaymentProviderID *string    `gorm:"column:payment_provider_id" json:"-"` // internal, not exposed to clients
+	// CapturedPaymentID is the provider payment the order was first paid with.
+	// Refunds go against it; a later payment for an edit's difference does not replace it.
+	CapturedPaymentID *string    `json:"-"`
 	PaymentExpiresAt  *time.Time `json:"paymentExpiresAt,omitempty"`
+	// PaymentReference is the bank payment order number an invoice was paid with.
+	PaymentReference  *string    `json:"paymentReference,omitempty"`
+	// CompanyID is set on orders placed for a company (B2B); it is billed on invoices.
+	CompanyID         *int       `json:"companyId,omitempty"`
+	Company           *Company   `gorm:"foreignKey:CompanyID" json:"company,omitempty"`
 	CustomerName    string      `gorm:"not null" json:"customerName"`
 	CustomerPhone   string      `gorm:"not null" json:"customerPhone"`
 	CustomerEmail   *string     `json:"customerEmail,omitempty"`
@@ -37,9 +50,13 @@ type Order struct {
 	DeliveryProvider  *string      `json:"deliveryProvider,omitempty"`
 	EstimatedDelivery *string      `json:"estimatedDelivery,omitempty"`
 	Notes             *string      `json:"notes,omitempty"`
+	CancelReason      *string      `json:"cancelReason,omitempty"`
+	CancelledAt       *time.Time   `json:"cancelledAt,omitempty"`
 	Items           []OrderItem         `gorm:"foreignKey:OrderID" json:"items"`
 	// CustomDetails is populated only for order_type == "custom".
 	CustomDetails   *CustomOrderDetails `gorm:"foreignKey:OrderID" json:"customDetails,omitempty"`
+	// Returns are the customer's return requests for this order.
+	Returns         []ReturnRequest     `gorm:"foreignKey:OrderID" json:"returns,omitempty"`
 	CreatedAt       time.Time   `json:"createdAt"`
 	UpdatedAt       time.Time   `json:"updatedAt"`
 }
@@ -53,6 +70,11 @@ type OrderItem struct {
 	CustomItemName        *string `json:"customItemName,omitempty"`
 	CustomItemDescription *string `json:"customItemDescription,omitempty"`
 	Quantity   int      `gorm:"not null" json:"quantity"`
+	// ProductionQuantity is the part of Quantity that has to be printed (not taken from stock).
+	ProductionQuantity int `gorm:"default:0" json:"productionQuantity"`
+	// StockQuantity is the part of Quantity taken from stock; it goes back on cancellation.
+	// Digital and printed units never touch stock.
+	StockQuantity int `gorm:"default:0" json:"-"`
 	UnitPrice  float64  `gorm:"type:decimal(10,2);not null" json:"unitPrice"`
 	TotalPrice float64  `gorm:"type:decimal(10,2);not null" json:"totalPrice"`
 	Product    *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
@@ -60,15 +82,35 @@ type OrderItem struct {
 }
 
 type OrderFilter struct {
-	Status    string
-	OrderType string // "regular" | "custom" | "" (all)
-	Page      int
-	Limit     int
+	Status        string
+	OrderType     string // "regular" | "custom" | "" (all)
+	PaymentMethod string
+	CustomerType  string // "company" | "individual" | "" (all)
+	CompanyID     int
+	Page          int
+	Limit         int
 }
 
+// Customer types of OrderFilter.
+const (
+	CustomerTypeCompany    = "company"
+	CustomerTypeIndividual = "individual"
+)
+
+// DeliveryMethodDigital is set on orders that contain only digital products.
+const DeliveryMethodDigital = "digital"
+
+// PaymentMethodInvoice orders are paid by bank transfer against a bank-transfer invoice.
+const PaymentMethodInvoice = "invoice"
+
 var (
 	ErrOrderNotFound       = errors.New("order not found")
+	ErrDeliveryMethodRequired = errors.New("delivery method is required")
+	ErrCustomerContactRequired = errors.New("customer name and phone are required")
 	ErrOrderStatusInvalid  = errors.New("invalid status transition")
+	ErrOrderAlreadyPaid    = errors.New("order is already paid")
+	ErrPaymentAmountInvalid = errors.New("payment amount exceeds the amount due")
+	ErrRefundDeclined       = errors.New("payment provider declined the refund")
 )
 
 type OrderRepository interface {
@@ -79,5 +121,11 @@ type OrderRepository interface {
 	ListByUserID(ctx context.Context, userID int) ([]Order, error)
 	UpdateStatus(ctx context.Context, id int, status string) error
 	UpdateTracking(ctx context.Context, id int, trackingNumber string) error
-	NextOrderNumber(ctx context.Context) (string, error)
+	// NextOrderSequence atomically increments and returns the counter of a
+	// number series for the given day. Counters start at 1 every day.
+	NextOrderSequence(ctx context.Context, series string, day time.Time) (int, error)
+	// FindExpiredUnpaid returns new card orders with nothing paid whose
+	// payment link expired before the given time, oldest first. Orders
+	// awaiting a surcharge after an edit are not included.
+	FindExpiredUnpaid(ctx context.Context, before time.Time, limit int) ([]Order, error)
 }
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrOrderNotEditable = errors.New("order cannot be edited")
+	ErrOrderChanged     = errors.New("order was changed while being edited")
+)
+
+// EditableOrderStatuses are the statuses in which an admin may change the
+// items and delivery of an order: anything before it is shipped.
+var EditableOrderStatuses = []string{"new", "confirmed", "processing"}
+
+// Payment actions taken after an order edit changed its total.
+const (
+	OrderPaymentActionNone = "none"
+	// OrderPaymentActionLink: a payment link for the amount due was issued.
+	OrderPaymentActionLink = "payment_link"
+	// OrderPaymentActionRefund: the overpaid amount is returned to the customer.
+	OrderPaymentActionRefund = "refund"
+)
+
+// OrderSnapshotItem is an order line as recorded in the edit log.
+type OrderSnapshotItem struct {
+	ProductID *int    `json:"productId"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unitPrice"`
+}
+
+// OrderSnapshot holds the fields of an order an admin edit can change.
+type OrderSnapshot struct {
+	Items           []OrderSnapshotItem `json:"items"`
+	DeliveryMethod  string              `json:"deliveryMethod"`
+	DeliveryAddress *string             `json:"deliveryAddress"`
+	PickupPointID   *int                `json:"pickupPointId"`
+	PromoCode       *string             `json:"promoCode"`
+	Subtotal        float64             `json:"subtotal"`
+	DiscountAmount  float64             `json:"discountAmount"`
+	DeliveryCost    float64             `json:"deliveryCost"`
+	BonusDiscount   float64             `json:"bonusDiscount"`
+	TotalPrice      float64             `json:"totalPrice"`
+}
+
+// SnapshotOrder captures the editable fields of o.
+func SnapshotOrder(o *Order) OrderSnapshot {
+	items := make([]OrderSnapshotItem, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, OrderSnapshotItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		})
+	}
+	return OrderSnapshot{
+		Items:           items,
+		DeliveryMethod:  o.DeliveryMethod,
+		DeliveryAddress: o.DeliveryAddress,
+		PickupPointID:   o.PickupPointID,
+		PromoCode:       o.PromoCode,
+		Subtotal:        o.Subtotal,
+		DiscountAmount:  o.DiscountAmount,
+		DeliveryCost:    o.DeliveryCost,
+		BonusDiscount:   o.BonusDiscount,
+		TotalPrice:      o.TotalPrice,
+	}
+}
+
+// DiffOrderSnapshots lists fields that differ between two snapshots, in field order.
+func DiffOrderSnapshots(from, to OrderSnapshot) FieldChanges {
+	return diffFields(from, to)
+}
+
+// OrderChange is one admin edit of an order.
+type OrderChange struct {
+	ID            int          `gorm:"primaryKey" json:"id"`
+	OrderID       int          `gorm:"not null" json:"orderId"`
+	UserID        *int         `json:"userId,omitempty"`
+	Changes       FieldChanges `gorm:"type:jsonb;not null;default:'[]'" json:"changes"`
+	OldTotal      float64      `gorm:"type:decimal(10,2);not null" json:"oldTotal"`
+	NewTotal      float64      `gorm:"type:decimal(10,2);not null" json:"newTotal"`
+	PaymentAction string       `gorm:"not null;default:none" json:"paymentAction"`
+	PaymentAmount float64      `gorm:"type:decimal(10,2);not null;default:0" json:"paymentAmount"`
+	Comment       *string      `json:"comment,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+func (OrderChange) TableName() string {
+	return "order_changes"
+}
+
+// OrderChangeRepository defines data access for the order edit log.
+type OrderChangeRepository interface {
+	ListByOrderID(ctx context.Context, orderID int) ([]OrderChange, error)
+}
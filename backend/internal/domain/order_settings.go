@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// OrderSettings holds admin-editable order handling options (single row).
+type OrderSettings struct {
+	ID int `gorm:"primaryKey" json:"id"`
+	// UnpaidCancelEnabled turns on automatic cancellation of card orders whose
+	// payment link expired UnpaidGraceMinutes ago without payment.
+	UnpaidCancelEnabled bool      `gorm:"not null;default:true" json:"unpaidCancelEnabled"`
+	UnpaidGraceMinutes  int       `gorm:"not null;default:30" json:"unpaidGraceMinutes"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+func (OrderSettings) TableName() string {
+	return "order_settings"
+}
+
+type OrderSettingsRepository interface {
+	Get(ctx context.Context) (*OrderSettings, error)
+	Update(ctx context.Context, settings *OrderSettings) error
+}
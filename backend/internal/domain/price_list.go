@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrPriceListNotFound   = errors.New("price list not found")
+	ErrPriceListNameExists = errors.New("price list name already exists")
+	// ErrInvalidPriceTiers is returned for tiers with duplicate or out-of-range quantities or non-positive prices.
+	ErrInvalidPriceTiers = errors.New("invalid price tiers")
+)
+
+// PriceTier is a quantity price break of a product: the unit price when at
+// least MinQuantity items are ordered. Smaller quantities cost Product.Price.
+type PriceTier struct {
+	ID          int     `gorm:"primaryKey" json:"-"`
+	ProductID   int     `gorm:"not null" json:"-"`
+	MinQuantity int     `gorm:"not null" json:"minQuantity"`
+	Price       float64 `gorm:"type:decimal(10,2);not null" json:"price"`
+}
+
+func (PriceTier) TableName() string {
+	return "product_price_tiers"
+}
+
+// PriceList is a named set of prices (wholesale, partner) assigned to users
+// or companies. Products without items in the list get DiscountPercent off
+// the retail price.
+type PriceList struct {
+	ID              int             `gorm:"primaryKey" json:"id"`
+	Name            string          `gorm:"not null;uniqueIndex" json:"name"`
+	Description     *string         `json:"description,omitempty"`
+	DiscountPercent float64         `gorm:"type:decimal(5,2);not null;default:0" json:"discountPercent"`
+	IsActive        bool            `gorm:"not null;default:true" json:"isActive"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+	Items           []PriceListItem `gorm:"foreignKey:PriceListID" json:"items,omitempty"`
+}
+
+func (PriceList) TableName() string {
+	return "price_lists"
+}
+
+// PriceListItem is the unit price of a product in a price list from MinQuantity items.
+type PriceListItem struct {
+	ID          int     `gorm:"primaryKey" json:"id"`
+	PriceListID int     `gorm:"not null" json:"priceListId"`
+	ProductID   int     `gorm:"not null" json:"productId"`
+	MinQuantity int     `gorm:"not null;default:1" json:"minQuantity"`
+	Price       float64 `gorm:"type:decimal(10,2);not null" json:"price"`
+}
+
+func (PriceListItem) TableName() string {
+	return "price_list_items"
+}
+
+// TierPrice returns the price of the tier with the largest MinQuantity not
+// above qty. ok is false when no tier applies.
+func TierPrice(tiers []PriceTier, qty int) (price float64, ok bool) {
+	best := 0
+	for _, t := range tiers {
+		if t.MinQuantity <= qty && t.MinQuantity > best {
+			best, price, ok = t.MinQuantity, t.Price, true
+		}
+	}
+	return price, ok
+}
+
+// PriceListRepository defines data access for price lists and quantity price breaks.
+type PriceListRepository interface {
+	Create(ctx context.Context, list *PriceList) error
+	FindByID(ctx context.Context, id int) (*PriceList, error)
+	FindByName(ctx context.Context, name string) (*PriceList, error)
+	List(ctx context.Context) ([]PriceList, error)
+	Update(ctx context.Context, list *PriceList) error
+	Delete(ctx context.Context, id int) error
+	// ReplaceItems replaces all product prices of a list.
+	ReplaceItems(ctx context.Context, listID int, items []PriceListItem) error
+	ItemsForProducts(ctx context.Context, listID int, productIDs []int) ([]PriceListItem, error)
+	// FindForUser returns the active price list of the user or, failing that,
+	// of the user's company. ErrPriceListNotFound if there is none.
+	FindForUser(ctx context.Context, userID int) (*PriceList, error)
+	// AssignToUser and AssignToCompany set or, with a nil listID, clear the price list.
+	AssignToUser(ctx context.Context, userID int, listID *int) error
+	AssignToCompany(ctx context.Context, companyID int, listID *int) error
+
+	TiersForProducts(ctx context.Context, productIDs []int) ([]PriceTier, error)
+	// ReplaceTiers replaces all quantity price breaks of a product.
+	ReplaceTiers(ctx context.Context, productID int, tiers []PriceTier) error
+}
@@ -56,6 +56,14 @@ type Product struct {
 	Dimensions       *Dimensions `gorm:"type:jsonb" json:"dimensions,omitempty"`
 	Material         *string     `json:"material,omitempty"`
 	PrintTime        *int        `json:"printTime,omitempty"`
+	// FulfillmentMode: "in_stock" | "made_to_order" | "hybrid" (stock first, then print the rest).
+	FulfillmentMode  string      `gorm:"not null;default:in_stock" json:"fulfillmentMode"`
+	// IsDigital marks downloadable model files: no stock, no delivery.
+	IsDigital        bool        `gorm:"default:false" json:"isDigital"`
+	// LeadTimeDays is the current production estimate, filled only on the product page.
+	LeadTimeDays     *int        `gorm:"-" json:"leadTimeDays,omitempty"`
+	// PriceTiers are the quantity price breaks for the current customer, filled only in catalog responses.
+	PriceTiers       []PriceTier `gorm:"-" json:"priceTiers,omitempty"`
 	CategoryID       *int        `json:"categoryId,omitempty"`
 	Category         *Category      `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	Images           []ProductImage `gorm:"foreignKey:ProductID" json:"images,omitempty"`
@@ -73,6 +81,41 @@ func (Product) TableName() string {
 	return "products"
 }
 
+// Fulfillment modes.
+const (
+	FulfillmentInStock     = "in_stock"
+	FulfillmentMadeToOrder = "made_to_order"
+	FulfillmentHybrid      = "hybrid"
+)
+
+// StockSplit returns how many of qty units are shipped from stock and how many
+// have to be printed. ok is false when the quantity cannot be fulfilled at all.
+func (p *Product) StockSplit(qty int) (fromStock, toProduce int, ok bool) {
+	if p.IsDigital {
+		return 0, 0, true
+	}
+	switch p.FulfillmentMode {
+	case FulfillmentMadeToOrder:
+		return 0, qty, true
+	case FulfillmentHybrid:
+		fromStock = min(max(p.StockQuantity, 0), qty)
+		return fromStock, qty - fromStock, true
+	default:
+		if p.StockQuantity < qty {
+			return 0, 0, false
+		}
+		return qty, 0, true
+	}
+}
+
+// ProductionMinutes returns the print time needed for qty units.
+func (p *Product) ProductionMinutes(qty int) int {
+	if p.PrintTime == nil || qty <= 0 {
+		return 0
+	}
+	return *p.PrintTime * qty
+}
+
 // ProductFilter defines filters for listing products.
 type ProductFilter struct {
 	CategorySlug    string   // filter by category slug (includes subcategories)
@@ -84,6 +127,8 @@ type ProductFilter struct {
 	Page            int      // page number (1-based)
 	Limit           int      // items per page
 	IncludeInactive bool     // when true, includes inactive products (admin)
+	OnlyInactive    bool     // when true, lists only inactive products (admin trash)
+	Locale          string   // response language; empty or default means untranslated
 }
 
 // ProductListResult contains paginated product list.
@@ -104,5 +149,7 @@ type ProductRepository interface {
 	Update(ctx context.Context, product *Product) error
 	SoftDelete(ctx context.Context, id int) error
 	FindByIDs(ctx context.Context, ids []int) ([]Product, error)
+	// FindBySKUs matches SKUs case-insensitively.
+	FindBySKUs(ctx context.Context, skus []string) ([]Product, error)
 	SearchSuggestions(ctx context.Context, query string, limit int) ([]string, error)
 }
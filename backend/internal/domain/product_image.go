@@ -2,26 +2,81 @@ package domain
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
 var (
-	ErrImageNotFound = errors.New("image not found")
+	ErrImageNotFound      = errors.New("image not found")
+	ErrImageOrderMismatch = errors.New("image order must list every product image exactly once")
 )
 
+// Image processing statuses. Uploads start as pending; a background worker
+// renders the variants and marks the image ready or failed.
+const (
+	ImageStatusPending    = "pending"
+	ImageStatusProcessing = "processing"
+	ImageStatusReady      = "ready"
+	ImageStatusFailed     = "failed"
+)
+
+// ImageVariant is one resized rendition of an image.
+type ImageVariant struct {
+	Width int    `json:"width"`
+	URL   string `json:"url"`
+	WebP  string `json:"webp,omitempty"`
+}
+
+// ImageVariants maps a size preset name to its rendition, stored as JSONB.
+type ImageVariants map[string]ImageVariant
+
+// Scan implements sql.Scanner for JSONB.
+func (v *ImageVariants) Scan(value interface{}) error {
+	if value == nil {
+		*v = ImageVariants{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan ImageVariants: expected []byte, got %T", value)
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// Value implements driver.Valuer for JSONB.
+func (v ImageVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
 // ProductImage represents an image associated with a product.
+// URL, URLLarge, URLMedium and URLThumbnail are kept for existing clients;
+// Variants holds every configured size with its WebP counterpart.
 type ProductImage struct {
-	ID           int       `gorm:"primaryKey" json:"id"`
-	ProductID    int       `gorm:"not null" json:"productId"`
-	URL          string    `gorm:"not null" json:"url"`
-	URLLarge     *string   `json:"urlLarge,omitempty"`
-	URLMedium    *string   `json:"urlMedium,omitempty"`
-	URLThumbnail *string   `json:"urlThumbnail,omitempty"`
-	S3Key        string    `gorm:"not null" json:"s3Key"`
-	IsMain       bool      `gorm:"default:false" json:"isMain"`
-	DisplayOrder int       `gorm:"default:0" json:"displayOrder"`
-	CreatedAt    time.Time `json:"createdAt"`
+	ID                  int           `gorm:"primaryKey" json:"id"`
+	ProductID           int           `gorm:"not null" json:"productId"`
+	URL                 string        `gorm:"not null" json:"url"`
+	URLWebP             *string       `gorm:"column:url_webp" json:"urlWebp,omitempty"`
+	URLLarge            *string       `json:"urlLarge,omitempty"`
+	URLMedium           *string       `json:"urlMedium,omitempty"`
+	URLThumbnail        *string       `json:"urlThumbnail,omitempty"`
+	Variants            ImageVariants `gorm:"type:jsonb;not null;default:'{}'" json:"variants"`
+	S3Key               string        `gorm:"not null" json:"s3Key"`
+	SourceKey           *string       `json:"-"`
+	Status              string        `gorm:"not null;default:ready" json:"status"`
+	Error               *string       `json:"error,omitempty"`
+	PresetSignature     *string       `json:"-"`
+	Attempts            int           `gorm:"default:0" json:"-"`
+	IsMain              bool          `gorm:"default:false" json:"isMain"`
+	DisplayOrder        int           `gorm:"default:0" json:"displayOrder"`
+	ProcessingStartedAt *time.Time    `json:"-"`
+	ProcessedAt         *time.Time    `json:"processedAt,omitempty"`
+	CreatedAt           time.Time     `json:"createdAt"`
 }
 
 func (ProductImage) TableName() string {
@@ -36,4 +91,19 @@ type ProductImageRepository interface {
 	SetMain(ctx context.Context, productID int, imageID int) error
 	Delete(ctx context.Context, id int) error
 	CountByProductID(ctx context.Context, productID int) (int64, error)
+	// UpdateDisplayOrders sets display_order to the position of each ID in imageIDs.
+	UpdateDisplayOrders(ctx context.Context, productID int, imageIDs []int) error
+	// ClaimPending atomically moves the oldest pending image to processing.
+	// Returns nil without error when the queue is empty.
+	ClaimPending(ctx context.Context) (*ProductImage, error)
+	// SaveProcessing writes processing results without touching ordering or the main flag.
+	SaveProcessing(ctx context.Context, image *ProductImage) error
+	// Requeue moves a single image back to pending.
+	Requeue(ctx context.Context, id int) error
+	// RequeueStale moves all ready and failed images whose preset signature
+	// differs from signature back to pending; an empty signature requeues all.
+	RequeueStale(ctx context.Context, signature string) (int64, error)
+	// RequeueStuck returns images left in processing since before the given time to pending.
+	RequeueStuck(ctx context.Context, startedBefore time.Time) (int64, error)
+	CountByStatus(ctx context.Context) (map[string]int64, error)
 }
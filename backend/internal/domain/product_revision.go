@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var ErrProductRevisionNotFound = errors.New("product revision not found")
+
+// Revision actions.
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionDelete  = "delete"
+	RevisionActionRestore = "restore"
+)
+
+// ProductSnapshot holds the editable fields of a product at one point in time.
+type ProductSnapshot struct {
+	Name             string      `json:"name"`
+	Slug             string      `json:"slug"`
+	Description      *string     `json:"description"`
+	ShortDescription *string     `json:"shortDescription"`
+	Price            float64     `json:"price"`
+	OldPrice         *float64    `json:"oldPrice"`
+	StockQuantity    int         `json:"stockQuantity"`
+	SKU              *string     `json:"sku"`
+	Weight           *float64    `json:"weight"`
+	Dimensions       *Dimensions `json:"dimensions"`
+	Material         *string     `json:"material"`
+	PrintTime        *int        `json:"printTime"`
+	FulfillmentMode  string      `json:"fulfillmentMode"`
+	IsDigital        bool        `json:"isDigital"`
+	CategoryID       *int        `json:"categoryId"`
+	IsActive         bool        `json:"isActive"`
+	IsFeatured       bool        `json:"isFeatured"`
+}
+
+// SnapshotProduct captures the editable fields of p.
+func SnapshotProduct(p *Product) ProductSnapshot {
+	return ProductSnapshot{
+		Name:             p.Name,
+		Slug:             p.Slug,
+		Description:      p.Description,
+		ShortDescription: p.ShortDescription,
+		Price:            p.Price,
+		OldPrice:         p.OldPrice,
+		StockQuantity:    p.StockQuantity,
+		SKU:              p.SKU,
+		Weight:           p.Weight,
+		Dimensions:       p.Dimensions,
+		Material:         p.Material,
+		PrintTime:        p.PrintTime,
+		FulfillmentMode:  p.FulfillmentMode,
+		IsDigital:        p.IsDigital,
+		CategoryID:       p.CategoryID,
+		IsActive:         p.IsActive,
+		IsFeatured:       p.IsFeatured,
+	}
+}
+
+// ApplyTo writes the snapshot back to p. Stock is left alone: it moves with
+// orders after the snapshot was taken, so restoring it would be wrong.
+func (s ProductSnapshot) ApplyTo(p *Product) {
+	p.Name = s.Name
+	p.Slug = s.Slug
+	p.Description = s.Description
+	p.ShortDescription = s.ShortDescription
+	p.Price = s.Price
+	p.OldPrice = s.OldPrice
+	p.SKU = s.SKU
+	p.Weight = s.Weight
+	p.Dimensions = s.Dimensions
+	p.Material = s.Material
+	p.PrintTime = s.PrintTime
+	p.FulfillmentMode = s.FulfillmentMode
+	p.IsDigital = s.IsDigital
+	p.CategoryID = s.CategoryID
+	p.IsActive = s.IsActive
+	p.IsFeatured = s.IsFeatured
+}
+
+// Scan implements sql.Scanner for JSONB.
+func (s *ProductSnapshot) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan ProductSnapshot: expected []byte, got %T", value)
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Value implements driver.Valuer for JSONB.
+func (s ProductSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// FieldChange is the old and new JSON value of one field.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// FieldChanges is a list of field changes stored as JSONB.
+type FieldChanges []FieldChange
+
+// Scan implements sql.Scanner for JSONB.
+func (c *FieldChanges) Scan(value interface{}) error {
+	if value == nil {
+		*c = FieldChanges{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan FieldChanges: expected []byte, got %T", value)
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Value implements driver.Valuer for JSONB.
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+// DiffSnapshots lists fields that differ between two snapshots, in field order.
+func DiffSnapshots(from, to ProductSnapshot) FieldChanges {
+	return diffFields(from, to)
+}
+
+// diffFields compares two structs of the same type by the JSON of each field.
+func diffFields(from, to interface{}) FieldChanges {
+	changes := FieldChanges{}
+	fv, tv := reflect.ValueOf(from), reflect.ValueOf(to)
+	t := fv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		oldJSON, _ := json.Marshal(fv.Field(i).Interface())
+		newJSON, _ := json.Marshal(tv.Field(i).Interface())
+		if bytes.Equal(oldJSON, newJSON) {
+			continue
+		}
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		changes = append(changes, FieldChange{Field: name, Old: oldJSON, New: newJSON})
+	}
+	return changes
+}
+
+// ProductRevision is one entry of a product's edit history.
+type ProductRevision struct {
+	ID           int             `gorm:"primaryKey" json:"id"`
+	ProductID    int             `gorm:"not null" json:"productId"`
+	Revision     int             `gorm:"not null" json:"revision"`
+	Action       string          `gorm:"not null" json:"action"`
+	Changes      FieldChanges    `gorm:"type:jsonb;not null;default:'[]'" json:"changes"`
+	Snapshot     ProductSnapshot `gorm:"type:jsonb;not null" json:"snapshot"`
+	RestoredFrom *int            `json:"restoredFrom,omitempty"`
+	UserID       *int            `json:"userId,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+func (ProductRevision) TableName() string {
+	return "product_revisions"
+}
+
+// ProductRevisionRepository defines data access for product revisions.
+type ProductRevisionRepository interface {
+	// Create assigns the next revision number of the product and inserts the entry.
+	Create(ctx context.Context, revision *ProductRevision) error
+	ListByProductID(ctx context.Context, productID, page, limit int) ([]ProductRevision, int64, error)
+	FindByRevision(ctx context.Context, productID, revision int) (*ProductRevision, error)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestProductStockSplit(t *testing.T) {
+	tests := []struct {
+		name          string
+		product       Product
+		qty           int
+		wantFromStock int
+		wantToProduce int
+		wantOK        bool
+	}{
+		{"in stock, enough", Product{FulfillmentMode: FulfillmentInStock, StockQuantity: 5}, 5, 5, 0, true},
+		{"in stock, short", Product{FulfillmentMode: FulfillmentInStock, StockQuantity: 4}, 5, 0, 0, false},
+		{"default mode is in stock", Product{StockQuantity: 1}, 2, 0, 0, false},
+		{"made to order ignores stock", Product{FulfillmentMode: FulfillmentMadeToOrder, StockQuantity: 10}, 3, 0, 3, true},
+		{"hybrid, enough stock", Product{FulfillmentMode: FulfillmentHybrid, StockQuantity: 10}, 3, 3, 0, true},
+		{"hybrid, partly printed", Product{FulfillmentMode: FulfillmentHybrid, StockQuantity: 2}, 5, 2, 3, true},
+		{"hybrid, no stock", Product{FulfillmentMode: FulfillmentHybrid}, 4, 0, 4, true},
+		{"hybrid, negative stock", Product{FulfillmentMode: FulfillmentHybrid, StockQuantity: -1}, 2, 0, 2, true},
+		{"digital", Product{IsDigital: true}, 3, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromStock, toProduce, ok := tt.product.StockSplit(tt.qty)
+			if fromStock != tt.wantFromStock || toProduce != tt.wantToProduce || ok != tt.wantOK {
+				t.Errorf("StockSplit(%d) = (%d, %d, %v), want (%d, %d, %v)",
+					tt.qty, fromStock, toProduce, ok, tt.wantFromStock, tt.wantToProduce, tt.wantOK)
+			}
+		})
+	}
+}
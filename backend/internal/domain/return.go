@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrReturnNotFound      = errors.New("return request not found")
+	ErrReturnNotAllowed    = errors.New("order cannot be returned")
+	ErrReturnInvalidItems  = errors.New("invalid return items")
+	ErrReturnInvalidStatus = errors.New("invalid return status transition")
+	ErrReturnRefundAmount  = errors.New("invalid refund amount")
+)
+
+// Return request statuses.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusShipped   = "shipped"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunding = "refunding" // card refund sent to the provider, not yet recorded
+	ReturnStatusRefunded  = "refunded"
+)
+
+// Refund methods of a return.
+const (
+	ReturnRefundPayment = "payment"
+	ReturnRefundBonuses = "bonuses"
+)
+
+// Inspection results of a returned item.
+const (
+	ReturnConditionResellable = "resellable"
+	ReturnConditionDamaged    = "damaged"
+	ReturnConditionDefective  = "defective"
+)
+
+// ReturnRequest is a customer's request to return items of a delivered order.
+type ReturnRequest struct {
+	ID      int     `gorm:"primaryKey" json:"id"`
+	OrderID int     `gorm:"not null" json:"orderId"`
+	UserID  int     `gorm:"not null" json:"userId"`
+	Status  string  `gorm:"not null;default:requested" json:"status"`
+	Reason  string  `gorm:"not null" json:"reason"`
+	Comment *string `json:"comment,omitempty"`
+	// PhotoURLs: JSON array of S3 URLs of photos attached by the customer.
+	PhotoURLs    json.RawMessage `gorm:"type:jsonb;not null;default:'[]'" json:"photoUrls"`
+	AdminComment *string         `json:"adminComment,omitempty"`
+
+	ReturnTrackingNumber *string    `json:"returnTrackingNumber,omitempty"`
+	ShippedAt            *time.Time `json:"shippedAt,omitempty"`
+	InspectionNotes      *string    `json:"inspectionNotes,omitempty"`
+	ReceivedAt           *time.Time `json:"receivedAt,omitempty"`
+	RefundMethod         *string    `json:"refundMethod,omitempty"`
+	RefundAmount         float64    `gorm:"type:decimal(10,2);not null;default:0" json:"refundAmount"`
+	RefundedAt           *time.Time `json:"refundedAt,omitempty"`
+
+	Items     []ReturnItem `gorm:"foreignKey:ReturnID" json:"items"`
+	Order     *Order       `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
+
+// ReturnItem is an order item (or part of its quantity) being returned.
+// Condition is set on inspection.
+type ReturnItem struct {
+	ID          int        `gorm:"primaryKey" json:"id"`
+	ReturnID    int        `gorm:"not null" json:"returnId"`
+	OrderItemID int        `gorm:"not null" json:"orderItemId"`
+	Quantity    int        `gorm:"not null" json:"quantity"`
+	Condition   *string    `json:"condition,omitempty"`
+	Restocked   bool       `gorm:"not null;default:false" json:"restocked"`
+	OrderItem   *OrderItem `gorm:"foreignKey:OrderItemID" json:"orderItem,omitempty"`
+}
+
+func (ReturnItem) TableName() string {
+	return "return_items"
+}
+
+// ReturnFilter selects return requests in the admin list.
+type ReturnFilter struct {
+	Status string
+	Page   int
+	Limit  int
+}
+
+// ReturnRepository defines data access for return requests.
+type ReturnRepository interface {
+	// Create inserts the request together with its items.
+	Create(ctx context.Context, r *ReturnRequest) error
+	FindByID(ctx context.Context, id int) (*ReturnRequest, error)
+	ListByUserID(ctx context.Context, userID int) ([]ReturnRequest, error)
+	List(ctx context.Context, filter ReturnFilter) ([]ReturnRequest, int64, error)
+	// Update saves the request fields, not its items.
+	Update(ctx context.Context, r *ReturnRequest) error
+	// ReturnedQuantities sums, per order item, the quantities of the order's
+	// return requests that were not rejected.
+	ReturnedQuantities(ctx context.Context, orderID int) (map[int]int, error)
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSaleCampaignNotFound        = errors.New("sale campaign not found")
+	ErrSaleCampaignNotEditable     = errors.New("sale campaign can only be edited while scheduled")
+	ErrSaleCampaignActive          = errors.New("sale campaign is active")
+	ErrSaleCampaignEmpty           = errors.New("sale campaign has no products or categories")
+	ErrSaleCampaignInvalidPeriod   = errors.New("invalid sale campaign period")
+	ErrSaleCampaignInvalidDiscount = errors.New("percent discount must be below 100")
+)
+
+// Sale campaign statuses.
+const (
+	SaleCampaignScheduled = "scheduled"
+	SaleCampaignActive    = "active"
+	SaleCampaignFinished  = "finished"
+	SaleCampaignCancelled = "cancelled"
+)
+
+// Price change reasons stored in price history.
+const (
+	PriceChangeManual        = "manual"
+	PriceChangeCampaignStart = "campaign_start"
+	PriceChangeCampaignEnd   = "campaign_end"
+)
+
+// IntList is a list of IDs stored as JSONB.
+type IntList []int
+
+// Scan implements sql.Scanner for JSONB.
+func (l *IntList) Scan(value interface{}) error {
+	if value == nil {
+		*l = IntList{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan IntList: expected []byte, got %T", value)
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer for JSONB.
+func (l IntList) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// SaleCampaign is a scheduled discount on a set of products and/or categories.
+type SaleCampaign struct {
+	ID            int        `gorm:"primaryKey" json:"id"`
+	Name          string     `gorm:"not null" json:"name"`
+	Description   *string    `json:"description,omitempty"`
+	DiscountType  string     `gorm:"not null" json:"discountType"`
+	DiscountValue float64    `gorm:"type:decimal(10,2);not null" json:"discountValue"`
+	ProductIDs    IntList    `gorm:"column:product_ids;type:jsonb" json:"productIds"`
+	CategoryIDs   IntList    `gorm:"column:category_ids;type:jsonb" json:"categoryIds"`
+	Status        string     `gorm:"default:scheduled" json:"status"`
+	StartsAt      time.Time  `gorm:"not null" json:"startsAt"`
+	EndsAt        *time.Time `json:"endsAt,omitempty"`
+	ActivatedAt   *time.Time `json:"activatedAt,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+
+	Items []SaleCampaignItem `gorm:"foreignKey:CampaignID" json:"items,omitempty"`
+}
+
+func (SaleCampaign) TableName() string {
+	return "sale_campaigns"
+}
+
+// SalePrice applies the campaign discount to a price.
+// Returns false if the discount would make the price non-positive.
+func (c *SaleCampaign) SalePrice(price float64) (float64, bool) {
+	var sale float64
+	switch c.DiscountType {
+	case "percent":
+		sale = price * (100 - c.DiscountValue) / 100
+	case "fixed":
+		sale = price - c.DiscountValue
+	default:
+		return 0, false
+	}
+	if sale <= 0 || sale >= price {
+		return 0, false
+	}
+	return sale, true
+}
+
+// SaleCampaignItem remembers a product's prices before the campaign was applied.
+type SaleCampaignItem struct {
+	ID               int        `gorm:"primaryKey" json:"id"`
+	CampaignID       int        `gorm:"not null" json:"campaignId"`
+	ProductID        int        `gorm:"not null" json:"productId"`
+	OriginalPrice    float64    `gorm:"type:decimal(10,2);not null" json:"originalPrice"`
+	OriginalOldPrice *float64   `gorm:"type:decimal(10,2)" json:"originalOldPrice,omitempty"`
+	SalePrice        float64    `gorm:"type:decimal(10,2);not null" json:"salePrice"`
+	RestoredAt       *time.Time `json:"restoredAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+func (SaleCampaignItem) TableName() string {
+	return "sale_campaign_items"
+}
+
+// PriceHistory is a single change of a product's price.
+type PriceHistory struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	ProductID   int       `gorm:"not null" json:"productId"`
+	OldPrice    *float64  `gorm:"type:decimal(10,2)" json:"oldPrice,omitempty"`
+	NewPrice    float64   `gorm:"type:decimal(10,2);not null" json:"newPrice"`
+	OldOldPrice *float64  `gorm:"type:decimal(10,2)" json:"oldOldPrice,omitempty"`
+	NewOldPrice *float64  `gorm:"type:decimal(10,2)" json:"newOldPrice,omitempty"`
+	Reason      string    `gorm:"not null" json:"reason"`
+	CampaignID  *int      `json:"campaignId,omitempty"`
+	ChangedBy   *int      `json:"changedBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (PriceHistory) TableName() string {
+	return "price_history"
+}
+
+// SaleCampaignRepository defines data access for sale campaigns.
+type SaleCampaignRepository interface {
+	Create(ctx context.Context, campaign *SaleCampaign) error
+	FindByID(ctx context.Context, id int) (*SaleCampaign, error)
+	List(ctx context.Context, status string) ([]SaleCampaign, error)
+	Update(ctx context.Context, campaign *SaleCampaign) error
+	Delete(ctx context.Context, id int) error
+	FindDueToStart(ctx context.Context, now time.Time) ([]SaleCampaign, error)
+	FindDueToEnd(ctx context.Context, now time.Time) ([]SaleCampaign, error)
+}
+
+// PriceHistoryRepository defines data access for product price history.
+type PriceHistoryRepository interface {
+	Create(ctx context.Context, entry *PriceHistory) error
+	ListByProductID(ctx context.Context, productID int, limit int) ([]PriceHistory, error)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrSlugRedirectNotFound = errors.New("slug redirect not found")
+
+// Entity types that keep slug history.
+const (
+	SlugEntityProduct  = "product"
+	SlugEntityCategory = "category"
+)
+
+// SlugRedirect maps a previous slug of a product or category to the entity,
+// so old links keep working after a rename.
+type SlugRedirect struct {
+	ID         int        `gorm:"primaryKey" json:"id"`
+	EntityType string     `gorm:"not null" json:"entityType"`
+	EntityID   int        `gorm:"not null" json:"entityId"`
+	OldSlug    string     `gorm:"not null" json:"oldSlug"`
+	Hits       int        `gorm:"default:0" json:"hits"`
+	LastHitAt  *time.Time `json:"lastHitAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	// CurrentSlug is the entity's slug now, filled for the admin list.
+	CurrentSlug string `gorm:"-" json:"currentSlug,omitempty"`
+}
+
+func (SlugRedirect) TableName() string {
+	return "slug_redirects"
+}
+
+// SlugMovedError is returned when a slug was renamed. NewSlug is the current one.
+type SlugMovedError struct {
+	NewSlug string
+}
+
+func (e *SlugMovedError) Error() string {
+	return fmt.Sprintf("slug moved permanently to %q", e.NewSlug)
+}
+
+type SlugRedirectFilter struct {
+	EntityType string
+	Search     string
+	Page       int
+	Limit      int
+}
+
+type SlugRedirectRepository interface {
+	// Save records oldSlug for the entity. An existing redirect with the same slug is re-pointed.
+	Save(ctx context.Context, entityType string, entityID int, oldSlug string) error
+	FindByOldSlug(ctx context.Context, entityType, slug string) (*SlugRedirect, error)
+	// DeleteBySlug removes a redirect whose slug is now taken by a live entity.
+	DeleteBySlug(ctx context.Context, entityType, slug string) error
+	RecordHit(ctx context.Context, id int) error
+	List(ctx context.Context, filter SlugRedirectFilter) ([]SlugRedirect, int64, error)
+	Delete(ctx context.Context, id int) error
+	// DeleteUnusedSince removes redirects not followed since the given time.
+	DeleteUnusedSince(ctx context.Context, since time.Time) (int64, error)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidStockMovement is returned for a zero movement or a stock type that cannot be entered by hand.
+var ErrInvalidStockMovement = errors.New("invalid stock movement")
+
+// Stock movement types. Quantity is positive for incoming and negative for
+// outgoing units.
+const (
+	// StockMovementInitial is the opening balance: stock of new products and
+	// of existing ones when the ledger was introduced.
+	StockMovementInitial      = "initial"
+	StockMovementReceipt      = "receipt"
+	StockMovementSale         = "sale"
+	StockMovementCancellation = "cancellation"
+	StockMovementReturn       = "return"
+	StockMovementAdjustment   = "adjustment"
+	// StockMovementProduction is printed units put on the shelf.
+	StockMovementProduction = "production"
+)
+
+// StockMovement is an entry of the stock ledger. Product.StockQuantity is the
+// sum of a product's movements; Balance is that sum right after the movement.
+// Reference is an external document, e.g. the supplier's delivery note.
+type StockMovement struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	ProductID int       `gorm:"not null" json:"productId"`
+	Type      string    `gorm:"not null" json:"type"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	Balance   int       `gorm:"not null" json:"balance"`
+	OrderID   *int      `json:"orderId,omitempty"`
+	ReturnID  *int      `json:"returnId,omitempty"`
+	Reference *string   `json:"reference,omitempty"`
+	Comment   *string   `json:"comment,omitempty"`
+	CreatedBy *int      `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// StockMovementFilter selects the movements of a product, newest first.
+type StockMovementFilter struct {
+	ProductID int
+	Type      string
+	Page      int
+	Limit     int
+}
+
+// StockMovementRepository defines read access to the stock ledger. Movements
+// are written together with the stock they change, see service.moveStock.
+type StockMovementRepository interface {
+	List(ctx context.Context, filter StockMovementFilter) ([]StockMovement, int64, error)
+}
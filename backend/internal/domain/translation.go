@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTranslationNotFound = errors.New("translation not found")
+	ErrUnsupportedLocale   = errors.New("unsupported locale")
+	ErrTranslationField    = errors.New("field cannot be translated")
+)
+
+// Translated entity types.
+const (
+	TranslationEntityProduct      = "product"
+	TranslationEntityCategory     = "category"
+	TranslationEntityContentBlock = "content_block"
+)
+
+// TranslatableFields lists the fields of each entity that can be translated,
+// by their JSON names. Content blocks are translated as a whole.
+var TranslatableFields = map[string][]string{
+	TranslationEntityProduct:  {"name", "shortDescription", "description"},
+	TranslationEntityCategory: {"name", "description", "metaTitle", "metaDescription", "h1"},
+}
+
+// TranslationFields maps a field name to its translated text (stored as JSONB).
+type TranslationFields map[string]string
+
+// Scan implements sql.Scanner for JSONB.
+func (f *TranslationFields) Scan(value interface{}) error {
+	if value == nil {
+		*f = TranslationFields{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to scan TranslationFields: expected []byte, got %T", value)
+	}
+	return json.Unmarshal(bytes, f)
+}
+
+// Value implements driver.Valuer for JSONB.
+func (f TranslationFields) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Translation holds the text of one entity in one locale.
+type Translation struct {
+	ID         int               `gorm:"primaryKey" json:"id"`
+	EntityType string            `gorm:"not null" json:"entityType"`
+	EntityID   int               `gorm:"not null" json:"entityId"`
+	Locale     string            `gorm:"not null" json:"locale"`
+	Fields     TranslationFields `gorm:"type:jsonb;not null;default:'{}'" json:"fields"`
+	Data       json.RawMessage   `gorm:"type:jsonb" json:"data,omitempty"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+func (Translation) TableName() string {
+	return "translations"
+}
+
+// TranslationRepository defines data access for translations.
+type TranslationRepository interface {
+	// Upsert creates or replaces the translation of an entity in a locale.
+	Upsert(ctx context.Context, t *Translation) error
+	// FindByEntity returns all translations of an entity, ordered by locale.
+	FindByEntity(ctx context.Context, entityType string, entityID int) ([]Translation, error)
+	Find(ctx context.Context, entityType string, entityID int, locale string) (*Translation, error)
+	// FindForEntities returns translations of the given entities in one locale.
+	FindForEntities(ctx context.Context, entityType string, entityIDs []int, locale string) ([]Translation, error)
+	// FindAllForLocale returns every translation of an entity type in one locale.
+	FindAllForLocale(ctx context.Context, entityType, locale string) ([]Translation, error)
+	Delete(ctx context.Context, entityType string, entityID int, locale string) error
+}
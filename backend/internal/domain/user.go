@@ -28,6 +28,9 @@ type User struct {
 	ReferralCode     *string   `gorm:"uniqueIndex" json:"referralCode,omitempty"`
 	ReferredByUserID *int      `json:"referredByUserID,omitempty"`
 	BonusBalance     float64   `gorm:"default:0" json:"bonusBalance"`
+	CartRemindersOptOut bool   `gorm:"default:false" json:"cartRemindersOptOut"`
+	// PriceListID assigns customer prices (wholesale, partner); it takes precedence over the company's.
+	PriceListID      *int      `json:"priceListId,omitempty"`
 	CreatedAt        time.Time `json:"createdAt"`
 	UpdatedAt        time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrWishlistItemNotFound      = errors.New("wishlist item not found")
+	ErrStockSubscriptionNotFound = errors.New("stock subscription not found")
+	ErrProductInStock            = errors.New("product is in stock")
+	ErrSubscriptionContact       = errors.New("no contact for the selected channel")
+)
+
+// Stock subscription channels.
+const (
+	StockChannelEmail    = "email"
+	StockChannelTelegram = "telegram"
+)
+
+type WishlistItem struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	UserID    int       `gorm:"not null" json:"-"`
+	ProductID int       `gorm:"not null" json:"productId"`
+	CreatedAt time.Time `json:"createdAt"`
+	Product   *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (WishlistItem) TableName() string {
+	return "wishlist_items"
+}
+
+// StockSubscription asks to notify a customer when an out-of-stock product is back.
+type StockSubscription struct {
+	ID         int       `gorm:"primaryKey" json:"id"`
+	ProductID  int       `gorm:"not null" json:"productId"`
+	UserID     *int      `json:"-"`
+	Channel    string    `gorm:"not null" json:"channel"`
+	Email      *string   `json:"email,omitempty"`
+	TelegramID *int64    `json:"-"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Product    *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (StockSubscription) TableName() string {
+	return "stock_subscriptions"
+}
+
+type WishlistRepository interface {
+	ListByUserID(ctx context.Context, userID int) ([]WishlistItem, error)
+	// Add is idempotent: adding the same product twice keeps one item.
+	Add(ctx context.Context, item *WishlistItem) error
+	Remove(ctx context.Context, userID, productID int) error
+}
+
+type StockSubscriptionRepository interface {
+	// Create is idempotent per product and contact: if the subscription
+	// already exists, sub is filled from it and created is false.
+	Create(ctx context.Context, sub *StockSubscription) (created bool, err error)
+	ListByUserID(ctx context.Context, userID int) ([]StockSubscription, error)
+	DeleteForUser(ctx context.Context, id, userID int) error
+	// ListByProductID returns up to limit subscriptions with ID greater than afterID.
+	ListByProductID(ctx context.Context, productID, afterID, limit int) ([]StockSubscription, error)
+	DeleteByIDs(ctx context.Context, ids []int) error
+}
+
+// BackInStockNotifier delivers back-in-stock notifications via Telegram.
+type BackInStockNotifier interface {
+	NotifyBackInStock(ctx context.Context, telegramID int64, product *Product) error
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// AddressHandler handles the customer's address book.
+type AddressHandler struct {
+	addressService *service.AddressService
+}
+
+// NewAddressHandler creates a new address book handler.
+func NewAddressHandler(addressService *service.AddressService) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+// RegisterProtectedRoutes registers address book routes that require authentication.
+func (h *AddressHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
+	addresses := rg.Group("/users/me/addresses")
+	addresses.GET("", h.List)
+	addresses.POST("", h.Create)
+	addresses.PUT("/:id", h.Update)
+	addresses.DELETE("/:id", h.Delete)
+}
+
+// List handles GET /api/v1/users/me/addresses
+func (h *AddressHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	addresses, err := h.addressService.List(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	if addresses == nil {
+		addresses = []domain.UserAddress{}
+	}
+	response.OK(c, addresses)
+}
+
+// Create handles POST /api/v1/users/me/addresses
+func (h *AddressHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	var input service.AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	address, err := h.addressService.Create(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, address)
+}
+
+// Update handles PUT /api/v1/users/me/addresses/:id
+func (h *AddressHandler) Update(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.AddressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	address, err := h.addressService.Update(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, address)
+}
+
+// Delete handles DELETE /api/v1/users/me/addresses/:id
+func (h *AddressHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.addressService.Delete(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *AddressHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAddressNotFound):
+		response.NotFound(c, "Адрес не найден")
+	case errors.Is(err, domain.ErrAddressIncomplete):
+		response.Error(c, http.StatusBadRequest, "ADDRESS_INCOMPLETE", "Для курьерской доставки укажите город и адрес, для пункта выдачи — пункт выдачи")
+	case errors.Is(err, domain.ErrPickupPointNotFound):
+		response.Error(c, http.StatusBadRequest, "PICKUP_POINT_NOT_FOUND", "Пункт выдачи не найден")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -41,6 +41,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	input.CartToken = c.GetHeader(cartTokenHeader)
+
 	tokens, err := h.authService.Login(c.Request.Context(), input)
 	if err != nil {
 		switch {
@@ -67,6 +69,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	input.CartToken = c.GetHeader(cartTokenHeader)
+
 	resp, err := h.authService.Register(c.Request.Context(), input)
 	if err != nil {
 		switch {
@@ -91,6 +95,8 @@ func (h *AuthHandler) TelegramWidgetLogin(c *gin.Context) {
 		return
 	}
 
+	input.CartToken = c.GetHeader(cartTokenHeader)
+
 	resp, err := h.authService.LoginTelegramWidget(c.Request.Context(), input)
 	if err != nil {
 		switch {
@@ -124,7 +130,7 @@ func (h *AuthHandler) TelegramLogin(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.authService.LoginTelegram(c.Request.Context(), input.InitData)
+	resp, err := h.authService.LoginTelegram(c.Request.Context(), input.InitData, c.GetHeader(cartTokenHeader))
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrInvalidInitData):
@@ -13,6 +13,10 @@ import (
 	"github.com/brown/3d-print-shop/pkg/response"
 )
 
+// cartTokenHeader carries the guest cart token. It is also read by the auth
+// endpoints to merge the guest cart into the account on login.
+const cartTokenHeader = "X-Cart-Token"
+
 type CartHandler struct {
 	cartService *service.CartService
 }
@@ -21,6 +25,8 @@ func NewCartHandler(cartService *service.CartService) *CartHandler {
 	return &CartHandler{cartService: cartService}
 }
 
+// RegisterRoutes registers cart routes. Expects OptionalAuth: logged-in users
+// get their stored cart, anonymous visitors a guest cart by X-Cart-Token.
 func (h *CartHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	cart := rg.Group("/cart")
 	cart.Use(authMiddleware)
@@ -31,26 +37,51 @@ func (h *CartHandler) RegisterRoutes(rg *gin.RouterGroup, authMiddleware gin.Han
 	cart.DELETE("", h.Clear)
 }
 
+// owner identifies whose cart the request works with. ok is false when the
+// visitor is anonymous and guest carts are disabled; the response is written then.
+func (h *CartHandler) owner(c *gin.Context) (userID int, token string, ok bool) {
+	if userID, ok := middleware.GetUserID(c); ok {
+		return userID, "", true
+	}
+	if !h.cartService.GuestCartsEnabled() {
+		response.Unauthorized(c, "Требуется авторизация")
+		return 0, "", false
+	}
+	return 0, c.GetHeader(cartTokenHeader), true
+}
+
+// respondCart writes the cart, repeating the guest token in the header.
+func (h *CartHandler) respondCart(c *gin.Context, cart *domain.Cart) {
+	if cart.Token != "" {
+		c.Header(cartTokenHeader, cart.Token)
+	}
+	response.OK(c, cart)
+}
+
 func (h *CartHandler) GetCart(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, token, ok := h.owner(c)
 	if !ok {
-		response.Unauthorized(c, "Требуется авторизация")
 		return
 	}
 
-	cart, err := h.cartService.GetCart(c.Request.Context(), userID)
+	var cart *domain.Cart
+	var err error
+	if userID != 0 {
+		cart, err = h.cartService.GetCart(c.Request.Context(), userID)
+	} else {
+		cart, err = h.cartService.GetGuestCart(c.Request.Context(), token)
+	}
 	if err != nil {
-		response.InternalError(c)
+		h.handleError(c, err)
 		return
 	}
 
-	response.OK(c, cart)
+	h.respondCart(c, cart)
 }
 
 func (h *CartHandler) AddItem(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, token, ok := h.owner(c)
 	if !ok {
-		response.Unauthorized(c, "Требуется авторизация")
 		return
 	}
 
@@ -62,19 +93,24 @@ func (h *CartHandler) AddItem(c *gin.Context) {
 		return
 	}
 
-	cart, err := h.cartService.AddItem(c.Request.Context(), userID, input)
+	var cart *domain.Cart
+	var err error
+	if userID != 0 {
+		cart, err = h.cartService.AddItem(c.Request.Context(), userID, input)
+	} else {
+		cart, err = h.cartService.AddGuestItem(c.Request.Context(), token, input)
+	}
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	response.OK(c, cart)
+	h.respondCart(c, cart)
 }
 
 func (h *CartHandler) UpdateItem(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, token, ok := h.owner(c)
 	if !ok {
-		response.Unauthorized(c, "Требуется авторизация")
 		return
 	}
 
@@ -92,19 +128,23 @@ func (h *CartHandler) UpdateItem(c *gin.Context) {
 		return
 	}
 
-	cart, err := h.cartService.UpdateItem(c.Request.Context(), userID, itemID, input)
+	var cart *domain.Cart
+	if userID != 0 {
+		cart, err = h.cartService.UpdateItem(c.Request.Context(), userID, itemID, input)
+	} else {
+		cart, err = h.cartService.UpdateGuestItem(c.Request.Context(), token, itemID, input)
+	}
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	response.OK(c, cart)
+	h.respondCart(c, cart)
 }
 
 func (h *CartHandler) RemoveItem(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, token, ok := h.owner(c)
 	if !ok {
-		response.Unauthorized(c, "Требуется авторизация")
 		return
 	}
 
@@ -114,24 +154,34 @@ func (h *CartHandler) RemoveItem(c *gin.Context) {
 		return
 	}
 
-	cart, err := h.cartService.RemoveItem(c.Request.Context(), userID, itemID)
+	var cart *domain.Cart
+	if userID != 0 {
+		cart, err = h.cartService.RemoveItem(c.Request.Context(), userID, itemID)
+	} else {
+		cart, err = h.cartService.RemoveGuestItem(c.Request.Context(), token, itemID)
+	}
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
-	response.OK(c, cart)
+	h.respondCart(c, cart)
 }
 
 func (h *CartHandler) Clear(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	userID, token, ok := h.owner(c)
 	if !ok {
-		response.Unauthorized(c, "Требуется авторизация")
 		return
 	}
 
-	if err := h.cartService.Clear(c.Request.Context(), userID); err != nil {
-		response.InternalError(c)
+	var err error
+	if userID != 0 {
+		err = h.cartService.Clear(c.Request.Context(), userID)
+	} else if token != "" {
+		err = h.cartService.ClearGuest(c.Request.Context(), token)
+	}
+	if err != nil {
+		h.handleError(c, err)
 		return
 	}
 
@@ -148,6 +198,8 @@ func (h *CartHandler) handleError(c *gin.Context, err error) {
 		response.Error(c, http.StatusBadRequest, "INSUFFICIENT_STOCK", "Недостаточно товара на складе")
 	case errors.Is(err, domain.ErrProductInactive):
 		response.Error(c, http.StatusBadRequest, "PRODUCT_INACTIVE", "Товар недоступен")
+	case errors.Is(err, domain.ErrInvalidCartToken):
+		response.Error(c, http.StatusBadRequest, "INVALID_CART_TOKEN", "Некорректный токен корзины")
 	default:
 		response.InternalError(c)
 	}
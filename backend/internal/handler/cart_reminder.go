@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// CartReminderHandler handles abandoned cart reminder links and admin campaigns.
+type CartReminderHandler struct {
+	reminderService *service.CartReminderService
+}
+
+// NewCartReminderHandler creates a new cart reminder handler.
+func NewCartReminderHandler(reminderService *service.CartReminderService) *CartReminderHandler {
+	return &CartReminderHandler{reminderService: reminderService}
+}
+
+// RegisterPublicRoutes registers the links sent in reminders.
+func (h *CartReminderHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.GET("/cart-reminders/:token", h.Click)
+	rg.GET("/cart-reminders/:token/unsubscribe", h.Unsubscribe)
+}
+
+// RegisterAdminRoutes registers admin campaign routes.
+func (h *CartReminderHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	reminders := rg.Group("/cart-reminders")
+	reminders.GET("/campaigns", h.ListCampaigns)
+	reminders.POST("/campaigns", h.CreateCampaign)
+	reminders.PUT("/campaigns/:id", h.UpdateCampaign)
+	reminders.DELETE("/campaigns/:id", h.DeleteCampaign)
+	reminders.GET("/stats", h.Stats)
+}
+
+// Click handles GET /api/v1/cart-reminders/:token
+// Records the click and redirects to the cart. Unknown links still lead to the cart.
+func (h *CartReminderHandler) Click(c *gin.Context) {
+	url, _ := h.reminderService.Click(c.Request.Context(), c.Param("token"))
+	c.Redirect(http.StatusFound, url)
+}
+
+// Unsubscribe handles GET /api/v1/cart-reminders/:token/unsubscribe
+func (h *CartReminderHandler) Unsubscribe(c *gin.Context) {
+	url, err := h.reminderService.Unsubscribe(c.Request.Context(), c.Param("token"))
+	if err != nil && !errors.Is(err, domain.ErrCartReminderNotFound) {
+		response.InternalError(c)
+		return
+	}
+	c.Redirect(http.StatusFound, url)
+}
+
+// ListCampaigns handles GET /api/v1/admin/cart-reminders/campaigns
+func (h *CartReminderHandler) ListCampaigns(c *gin.Context) {
+	campaigns, err := h.reminderService.ListCampaigns(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, campaigns)
+}
+
+// CreateCampaign handles POST /api/v1/admin/cart-reminders/campaigns
+func (h *CartReminderHandler) CreateCampaign(c *gin.Context) {
+	var input service.CartReminderCampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	campaign, err := h.reminderService.CreateCampaign(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, campaign)
+}
+
+// UpdateCampaign handles PUT /api/v1/admin/cart-reminders/campaigns/:id
+func (h *CartReminderHandler) UpdateCampaign(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.CartReminderCampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	campaign, err := h.reminderService.UpdateCampaign(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// DeleteCampaign handles DELETE /api/v1/admin/cart-reminders/campaigns/:id
+func (h *CartReminderHandler) DeleteCampaign(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.reminderService.DeleteCampaign(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Stats handles GET /api/v1/admin/cart-reminders/stats
+func (h *CartReminderHandler) Stats(c *gin.Context) {
+	stats, err := h.reminderService.Stats(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	if stats == nil {
+		stats = []domain.CartReminderStats{}
+	}
+	response.OK(c, stats)
+}
+
+func (h *CartReminderHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCartReminderCampaignNotFound):
+		response.NotFound(c, "Кампания напоминаний не найдена")
+	case errors.Is(err, domain.ErrCartReminderInvalidPromo):
+		response.Error(c, http.StatusBadRequest, "INVALID_REMINDER_PROMO", "Укажите тип и размер скидки промокода; скидка в процентах должна быть меньше 100")
+	default:
+		response.InternalError(c)
+	}
+}
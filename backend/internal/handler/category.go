@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
 	"github.com/brown/3d-print-shop/internal/service"
 	"github.com/brown/3d-print-shop/pkg/response"
 )
@@ -25,12 +26,16 @@ func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandl
 // RegisterPublicRoutes registers public category routes.
 func (h *CategoryHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
 	rg.GET("/categories", h.GetTree)
+	rg.GET("/categories/:slug", h.GetBySlug)
+	rg.GET("/categories/:slug/breadcrumbs", h.Breadcrumbs)
 }
 
 // RegisterAdminRoutes registers admin category routes.
 func (h *CategoryHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
 	cats := rg.Group("/categories")
 	cats.POST("", h.Create)
+	cats.POST("/reorder", h.Reorder)
+	cats.POST("/:id/move", h.Move)
 	cats.PUT("/:id", h.Update)
 	cats.DELETE("/:id", h.Delete)
 }
@@ -47,11 +52,14 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 
 	cat, err := h.categoryService.Create(c.Request.Context(), input)
 	if err != nil {
-		if errors.Is(err, domain.ErrCategorySlugExists) {
+		switch {
+		case errors.Is(err, domain.ErrCategorySlugExists):
 			response.Conflict(c, "Категория с таким slug уже существует")
-			return
+		case errors.Is(err, domain.ErrCategoryParentNotFound):
+			response.Error(c, http.StatusBadRequest, "PARENT_NOT_FOUND", "Родительская категория не найдена")
+		default:
+			response.InternalError(c)
 		}
-		response.InternalError(c)
 		return
 	}
 
@@ -60,7 +68,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 
 // GetTree handles GET /api/v1/categories
 func (h *CategoryHandler) GetTree(c *gin.Context) {
-	categories, err := h.categoryService.GetTree(c.Request.Context())
+	categories, err := h.categoryService.GetTree(c.Request.Context(), middleware.GetLocale(c))
 	if err != nil {
 		response.InternalError(c)
 		return
@@ -92,6 +100,10 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 			response.NotFound(c, "Категория не найдена")
 		case errors.Is(err, domain.ErrCategorySlugExists):
 			response.Conflict(c, "Категория с таким slug уже существует")
+		case errors.Is(err, domain.ErrCategoryParentNotFound):
+			response.Error(c, http.StatusBadRequest, "PARENT_NOT_FOUND", "Родительская категория не найдена")
+		case errors.Is(err, domain.ErrCategoryCycle):
+			response.Error(c, http.StatusBadRequest, "CATEGORY_CYCLE", "Нельзя переместить категорию внутрь её подкатегории")
 		default:
 			response.InternalError(c)
 		}
@@ -101,6 +113,110 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	response.OK(c, cat)
 }
 
+// GetBySlug handles GET /api/v1/categories/:slug
+// Returns the category with its SEO fields. An old slug returns 301 with the new slug.
+func (h *CategoryHandler) GetBySlug(c *gin.Context) {
+	cat, err := h.categoryService.GetBySlug(c.Request.Context(), c.Param("slug"), middleware.GetLocale(c))
+	if err != nil {
+		var moved *domain.SlugMovedError
+		if errors.As(err, &moved) {
+			response.MovedPermanently(c, "/api/v1/categories/"+moved.NewSlug, moved.NewSlug)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			response.NotFound(c, "Категория не найдена")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, cat)
+}
+
+// Breadcrumbs handles GET /api/v1/categories/:slug/breadcrumbs
+// Returns the path from the top-level category down to the requested one.
+func (h *CategoryHandler) Breadcrumbs(c *gin.Context) {
+	path, err := h.categoryService.Breadcrumbs(c.Request.Context(), c.Param("slug"), middleware.GetLocale(c))
+	if err != nil {
+		var moved *domain.SlugMovedError
+		if errors.As(err, &moved) {
+			response.MovedPermanently(c, "/api/v1/categories/"+moved.NewSlug+"/breadcrumbs", moved.NewSlug)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			response.NotFound(c, "Категория не найдена")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, path)
+}
+
+// Move handles POST /api/v1/admin/categories/:id/move
+// Moves the category with its whole subtree under another parent (or to the top level).
+func (h *CategoryHandler) Move(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.MoveCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: "Некорректные данные"},
+		})
+		return
+	}
+
+	cat, err := h.categoryService.Move(c.Request.Context(), id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCategoryNotFound):
+			response.NotFound(c, "Категория не найдена")
+		case errors.Is(err, domain.ErrCategoryParentNotFound):
+			response.Error(c, http.StatusBadRequest, "PARENT_NOT_FOUND", "Родительская категория не найдена")
+		case errors.Is(err, domain.ErrCategoryCycle):
+			response.Error(c, http.StatusBadRequest, "CATEGORY_CYCLE", "Нельзя переместить категорию внутрь её подкатегории")
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	response.OK(c, cat)
+}
+
+type reorderCategoriesRequest struct {
+	Items []domain.CategoryOrder `json:"items" binding:"required,min=1,dive"`
+}
+
+// Reorder handles POST /api/v1/admin/categories/reorder
+// Body: {"items": [{"id": 1, "displayOrder": 0}, ...]}
+func (h *CategoryHandler) Reorder(c *gin.Context) {
+	var req reorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: "Некорректные данные"},
+		})
+		return
+	}
+
+	if err := h.categoryService.Reorder(c.Request.Context(), req.Items); err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			response.NotFound(c, "Категория не найдена")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
 // Delete handles DELETE /api/v1/admin/categories/:id
 func (h *CategoryHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// CompanyHandler handles the company requisites of B2B customers.
+type CompanyHandler struct {
+	companyService *service.CompanyService
+}
+
+// NewCompanyHandler creates a new company handler.
+func NewCompanyHandler(companyService *service.CompanyService) *CompanyHandler {
+	return &CompanyHandler{companyService: companyService}
+}
+
+// RegisterProtectedRoutes registers company routes that require authentication.
+func (h *CompanyHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
+	company := rg.Group("/users/me/company")
+	company.GET("", h.Get)
+	company.PUT("", h.Save)
+	company.DELETE("", h.Delete)
+}
+
+// RegisterAdminRoutes registers admin company routes.
+func (h *CompanyHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/companies", h.AdminList)
+	rg.GET("/companies/:id", h.AdminGetByID)
+}
+
+// Get handles GET /api/v1/users/me/company
+func (h *CompanyHandler) Get(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	company, err := h.companyService.Get(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, company)
+}
+
+// Save handles PUT /api/v1/users/me/company
+func (h *CompanyHandler) Save(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	var input service.CompanyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	company, err := h.companyService.Save(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, company)
+}
+
+// Delete handles DELETE /api/v1/users/me/company
+func (h *CompanyHandler) Delete(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Не авторизован")
+		return
+	}
+
+	if err := h.companyService.Delete(c.Request.Context(), userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// AdminList handles GET /api/v1/admin/companies
+// Query: search (legal name or INN), page, limit.
+func (h *CompanyHandler) AdminList(c *gin.Context) {
+	filter := domain.CompanyFilter{
+		Search: c.Query("search"),
+		Page:   1,
+		Limit:  20,
+	}
+	if p, _ := strconv.Atoi(c.DefaultQuery("page", "1")); p > 0 {
+		filter.Page = p
+	}
+	if l, _ := strconv.Atoi(c.DefaultQuery("limit", "20")); l > 0 && l <= 100 {
+		filter.Limit = l
+	}
+
+	companies, total, err := h.companyService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	totalPages := int(total) / filter.Limit
+	if int(total)%filter.Limit > 0 {
+		totalPages++
+	}
+	response.Paginated(c, companies, response.PaginationMeta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// AdminGetByID handles GET /api/v1/admin/companies/:id
+func (h *CompanyHandler) AdminGetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	company, err := h.companyService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, company)
+}
+
+func (h *CompanyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCompanyNotFound):
+		response.NotFound(c, "Компания не найдена")
+	case errors.Is(err, domain.ErrInvalidINN):
+		response.Error(c, http.StatusBadRequest, "INVALID_INN", "Некорректный ИНН: 10 цифр для организации, 12 для ИП")
+	case errors.Is(err, domain.ErrInvalidKPP):
+		response.Error(c, http.StatusBadRequest, "INVALID_KPP", "Некорректный КПП: обязателен для организации (9 символов), у ИП не указывается")
+	case errors.Is(err, domain.ErrInvalidOGRN):
+		response.Error(c, http.StatusBadRequest, "INVALID_OGRN", "Некорректный ОГРН: 13 цифр для организации, 15 для ИП")
+	default:
+		response.InternalError(c)
+	}
+}
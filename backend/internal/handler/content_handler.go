@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/brown/3d-print-shop/internal/middleware"
 	"github.com/brown/3d-print-shop/internal/service"
 	"github.com/brown/3d-print-shop/pkg/response"
 )
@@ -32,7 +33,7 @@ func (h *ContentHandler) RegisterAdminRoutes(admin *gin.RouterGroup) {
 func (h *ContentHandler) GetBlock(c *gin.Context) {
 	slug := c.Param("slug")
 
-	data, err := h.contentService.GetBlock(c.Request.Context(), slug)
+	data, err := h.contentService.GetBlock(c.Request.Context(), slug, middleware.GetLocale(c))
 	if err != nil {
 		response.NotFound(c, "Контентный блок не найден")
 		return
@@ -15,16 +15,22 @@ import (
 
 type CustomOrderHandler struct {
 	customOrderService *service.CustomOrderService
+	idempotency        gin.HandlerFunc
 }
 
 func NewCustomOrderHandler(customOrderService *service.CustomOrderService) *CustomOrderHandler {
 	return &CustomOrderHandler{customOrderService: customOrderService}
 }
 
+// SetIdempotency включает поддержку Idempotency-Key для создания заявок и ссылок на оплату.
+func (h *CustomOrderHandler) SetIdempotency(mw gin.HandlerFunc) {
+	h.idempotency = mw
+}
+
 // RegisterPublicRoutes — маршруты для клиентского фронтенда.
 // Вызывается с группой, оснащённой OptionalAuth, чтобы получать userID когда пользователь авторизован.
 func (h *CustomOrderHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
-	rg.POST("/custom-orders", h.SubmitRequest)
+	rg.POST("/custom-orders", idempotent(h.idempotency, h.SubmitRequest)...)
 	rg.POST("/custom-orders/:id/files", h.UploadModelFile)
 }
 
@@ -39,7 +45,7 @@ func (h *CustomOrderHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
 	rg.GET("/custom-orders", h.ListCustomOrders)
 	rg.GET("/custom-orders/:id", h.GetByID)
 	rg.POST("/custom-orders/:id/confirm", h.ConfirmRequest)
-	rg.POST("/custom-orders/:id/send-payment", h.SendPaymentLink)
+	rg.POST("/custom-orders/:id/send-payment", idempotent(h.idempotency, h.SendPaymentLink)...)
 	rg.POST("/custom-orders/:id/mark-paid", h.MarkPaidManually)
 	rg.PUT("/custom-orders/:id", h.UpdateAdminDetails)
 	rg.POST("/custom-orders/:id/files", h.UploadModelFile)
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// DigitalHandler handles digital product files and download links.
+type DigitalHandler struct {
+	digitalService *service.DigitalService
+}
+
+// NewDigitalHandler creates a new digital product handler.
+func NewDigitalHandler(digitalService *service.DigitalService) *DigitalHandler {
+	return &DigitalHandler{digitalService: digitalService}
+}
+
+// RegisterPublicRoutes registers the download endpoint (access is granted by the token).
+func (h *DigitalHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.GET("/downloads/:token", h.Download)
+}
+
+// RegisterProtectedRoutes registers "My downloads" for authenticated users.
+func (h *DigitalHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
+	rg.GET("/users/me/downloads", h.MyDownloads)
+}
+
+// RegisterAdminRoutes registers admin file management routes.
+func (h *DigitalHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/products/:id/files", h.ListFiles)
+	rg.POST("/products/:id/files", h.UploadFile)
+	rg.DELETE("/products/files/:fileId", h.DeleteFile)
+}
+
+// Download handles GET /api/v1/downloads/:token
+// Counts the download and redirects to a short-lived S3 URL.
+func (h *DigitalHandler) Download(c *gin.Context) {
+	url, err := h.digitalService.ResolveDownload(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDownloadLinkNotFound), errors.Is(err, domain.ErrProductFileNotFound):
+			response.NotFound(c, "Ссылка не найдена")
+		case errors.Is(err, domain.ErrDownloadLinkExpired):
+			response.Error(c, http.StatusGone, "LINK_EXPIRED", "Срок действия ссылки истёк")
+		case errors.Is(err, domain.ErrDownloadLimitReached):
+			response.Error(c, http.StatusGone, "DOWNLOAD_LIMIT_REACHED", "Лимит скачиваний исчерпан")
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// MyDownloads handles GET /api/v1/users/me/downloads
+func (h *DigitalHandler) MyDownloads(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	links, err := h.digitalService.ListMyDownloads(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, links)
+}
+
+// ListFiles handles GET /api/v1/admin/products/:id/files
+func (h *DigitalHandler) ListFiles(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	files, err := h.digitalService.ListFiles(c.Request.Context(), id)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, files)
+}
+
+// UploadFile handles POST /api/v1/admin/products/:id/files
+// Multipart form, field "file".
+func (h *DigitalHandler) UploadFile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "NO_FILE", "Файл не загружен (поле 'file')")
+		return
+	}
+	defer file.Close()
+
+	pf, err := h.digitalService.UploadFile(c.Request.Context(), id, header.Filename, file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProductNotFound):
+			response.NotFound(c, "Товар не найден")
+		case errors.Is(err, service.ErrProductNotDigital):
+			response.Error(c, http.StatusBadRequest, "PRODUCT_NOT_DIGITAL", "Товар не отмечен как цифровой")
+		case errors.Is(err, service.ErrDigitalFileTooLarge):
+			response.Error(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", err.Error())
+		case errors.Is(err, service.ErrDigitalUnsupportedFile):
+			response.Error(c, http.StatusBadRequest, "UNSUPPORTED_FORMAT", err.Error())
+		case errors.Is(err, service.ErrDigitalStorageDisabled):
+			response.Error(c, http.StatusServiceUnavailable, "STORAGE_UNAVAILABLE", "Хранилище файлов не настроено")
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	response.Created(c, pf)
+}
+
+// DeleteFile handles DELETE /api/v1/admin/products/files/:fileId
+func (h *DigitalHandler) DeleteFile(c *gin.Context) {
+	fileID, err := strconv.Atoi(c.Param("fileId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.digitalService.DeleteFile(c.Request.Context(), fileID); err != nil {
+		if errors.Is(err, domain.ErrProductFileNotFound) {
+			response.NotFound(c, "Файл не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	response.NoContent(c)
+}
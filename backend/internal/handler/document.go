@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// DocumentHandler serves order documents as PDF downloads.
+type DocumentHandler struct {
+	documentService *service.DocumentService
+}
+
+// NewDocumentHandler creates a new document handler.
+func NewDocumentHandler(documentService *service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{documentService: documentService}
+}
+
+// RegisterPublicRoutes registers customer document routes.
+// Like the order page, documents are reached by the order number.
+func (h *DocumentHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.GET("/orders/:orderNumber/documents/:kind", h.CustomerDocument)
+}
+
+// RegisterAdminRoutes registers admin document routes.
+func (h *DocumentHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/orders/:id/documents/:kind", h.AdminDocument)
+}
+
+// CustomerDocument handles GET /api/v1/orders/:orderNumber/documents/:kind
+// kind: "invoice" | "bank-invoice".
+func (h *DocumentHandler) CustomerDocument(c *gin.Context) {
+	doc, err := h.documentService.CustomerDocument(c.Request.Context(), c.Param("orderNumber"), c.Param("kind"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.send(c, doc)
+}
+
+// AdminDocument handles GET /api/v1/admin/orders/:id/documents/:kind
+// kind: "packing-slip" | "invoice" | "bank-invoice".
+func (h *DocumentHandler) AdminDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	doc, err := h.documentService.OrderDocument(c.Request.Context(), id, c.Param("kind"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.send(c, doc)
+}
+
+func (h *DocumentHandler) send(c *gin.Context, doc *service.Document) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", doc.FileName))
+	c.Data(http.StatusOK, "application/pdf", doc.Content)
+}
+
+func (h *DocumentHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrOrderNotFound):
+		response.NotFound(c, "Заказ не найден")
+	case errors.Is(err, service.ErrDocumentNotFound):
+		response.NotFound(c, "Документ не найден")
+	case errors.Is(err, service.ErrBankDetailsMissing):
+		response.Error(c, http.StatusServiceUnavailable, "REQUISITES_NOT_CONFIGURED", "Реквизиты для выставления счёта не настроены")
+	default:
+		response.InternalError(c)
+	}
+}
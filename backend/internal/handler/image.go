@@ -25,8 +25,14 @@ func NewImageHandler(imageService *service.ImageService) *ImageHandler {
 // RegisterAdminRoutes registers admin image routes.
 func (h *ImageHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
 	rg.POST("/products/:id/images", h.Upload)
+	rg.PUT("/products/:id/images/order", h.Reorder)
+	rg.POST("/products/:id/images/import", h.ImportFromURLs)
+	rg.POST("/products/images/archive", h.UploadArchive)
 	rg.PUT("/products/:id/images/:imageId/main", h.SetMain)
 	rg.DELETE("/products/images/:imageId", h.Delete)
+	rg.POST("/products/images/:imageId/reprocess", h.Reprocess)
+	rg.GET("/images/status", h.Status)
+	rg.POST("/images/regenerate", h.Regenerate)
 }
 
 // Upload handles POST /api/v1/admin/products/:id/images
@@ -63,6 +69,103 @@ func (h *ImageHandler) Upload(c *gin.Context) {
 	response.Created(c, img)
 }
 
+type reorderImagesRequest struct {
+	ImageIDs []int `json:"imageIds" binding:"required,min=1"`
+}
+
+// Reorder handles PUT /api/v1/admin/products/:id/images/order
+// Body: {"imageIds": [3, 1, 2]} — every image of the product in the new order.
+func (h *ImageHandler) Reorder(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID товара")
+		return
+	}
+
+	var req reorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Field: "imageIds", Message: "Укажите ID изображений"},
+		})
+		return
+	}
+
+	images, err := h.imageService.Reorder(c.Request.Context(), productID, req.ImageIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrImageOrderMismatch):
+			response.Error(c, http.StatusBadRequest, "IMAGE_ORDER_MISMATCH", "Нужно перечислить все изображения товара по одному разу")
+		case errors.Is(err, domain.ErrImageNotFound):
+			response.NotFound(c, "Изображение не найдено")
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	response.OK(c, images)
+}
+
+type importImagesRequest struct {
+	URLs []string `json:"urls" binding:"required,min=1,max=20,dive,required,url"`
+}
+
+// ImportFromURLs handles POST /api/v1/admin/products/:id/images/import
+// Body: {"urls": ["https://..."]}. Each URL gets its own result.
+func (h *ImageHandler) ImportFromURLs(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID товара")
+		return
+	}
+
+	var req importImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Field: "urls", Message: "Укажите от 1 до 20 корректных ссылок"},
+		})
+		return
+	}
+
+	results, err := h.imageService.ImportFromURLs(c.Request.Context(), productID, req.URLs)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			response.NotFound(c, "Товар не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, results)
+}
+
+// UploadArchive handles POST /api/v1/admin/products/images/archive
+// Accepts a ZIP in the "file" field; images are matched to products by SKU in the file name.
+func (h *ImageHandler) UploadArchive(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "NO_FILE", "Файл не загружен")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.imageService.UploadArchive(c.Request.Context(), file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImageArchiveTooLarge):
+			response.Error(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", err.Error())
+		case errors.Is(err, service.ErrImageArchiveInvalid):
+			response.Error(c, http.StatusBadRequest, "INVALID_ARCHIVE", err.Error())
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	response.OK(c, result)
+}
+
 // SetMain handles PUT /api/v1/admin/products/:id/images/:imageId/main
 func (h *ImageHandler) SetMain(c *gin.Context) {
 	productID, err := strconv.Atoi(c.Param("id"))
@@ -110,3 +213,59 @@ func (h *ImageHandler) Delete(c *gin.Context) {
 
 	response.NoContent(c)
 }
+
+// Reprocess handles POST /api/v1/admin/products/images/:imageId/reprocess
+func (h *ImageHandler) Reprocess(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID изображения")
+		return
+	}
+
+	if err := h.imageService.Reprocess(c.Request.Context(), imageID); err != nil {
+		if errors.Is(err, domain.ErrImageNotFound) {
+			response.NotFound(c, "Изображение не найдено")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, gin.H{"message": "Изображение поставлено в очередь на обработку"})
+}
+
+// Status handles GET /api/v1/admin/images/status
+func (h *ImageHandler) Status(c *gin.Context) {
+	status, err := h.imageService.Status(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, status)
+}
+
+type regenerateImagesRequest struct {
+	Force bool `json:"force"`
+}
+
+// Regenerate handles POST /api/v1/admin/images/regenerate
+// Without force only images rendered with outdated size presets are queued.
+func (h *ImageHandler) Regenerate(c *gin.Context) {
+	var req regenerateImagesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.ValidationError(c, []response.ErrorDetail{
+				{Message: "Некорректные данные"},
+			})
+			return
+		}
+	}
+
+	queued, err := h.imageService.Regenerate(c.Request.Context(), req.Force)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, gin.H{"queued": queued})
+}
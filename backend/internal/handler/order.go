@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -15,26 +18,47 @@ import (
 
 type OrderHandler struct {
 	orderService *service.OrderService
+	idempotency  gin.HandlerFunc
 }
 
 func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 	return &OrderHandler{orderService: orderService}
 }
 
+// SetIdempotency enables Idempotency-Key handling on order creation.
+func (h *OrderHandler) SetIdempotency(mw gin.HandlerFunc) {
+	h.idempotency = mw
+}
+
+// idempotent puts the Idempotency-Key middleware, when configured, in front of a handler.
+func idempotent(mw, h gin.HandlerFunc) []gin.HandlerFunc {
+	if mw == nil {
+		return []gin.HandlerFunc{h}
+	}
+	return []gin.HandlerFunc{mw, h}
+}
+
 func (h *OrderHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.POST("/checkout/preview", h.Preview)
 	orders := rg.Group("/orders")
-	orders.POST("", h.Create)
+	orders.POST("", idempotent(h.idempotency, h.Create)...)
 	orders.GET("/:orderNumber", h.GetByOrderNumber)
 }
 
 func (h *OrderHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
 	rg.GET("/orders/my", h.MyOrders)
+	rg.POST("/orders/:orderNumber/reorder", h.Reorder)
 }
 
 func (h *OrderHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
 	orders := rg.Group("/orders")
 	orders.GET("", h.AdminList)
+	orders.GET("/export", h.AdminExport)
+	orders.GET("/settings", h.AdminGetSettings)
+	orders.PUT("/settings", h.AdminUpdateSettings)
 	orders.GET("/:id", h.AdminGetByID)
+	orders.PUT("/:id", h.AdminEdit)
+	orders.GET("/:id/changes", h.AdminListChanges)
 	orders.PUT("/:id/status", h.AdminUpdateStatus)
 	orders.PUT("/:id/tracking", h.AdminUpdateTracking)
 }
@@ -47,6 +71,9 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		})
 		return
 	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.UserID = &userID
+	}
 
 	order, err := h.orderService.CreateOrder(c.Request.Context(), input)
 	if err != nil {
@@ -57,6 +84,67 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	response.Created(c, order)
 }
 
+// checkoutIssue is a problem found by the checkout preview. Field points at
+// the input, e.g. "items[1]" or "promoCode".
+type checkoutIssue struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type checkoutPreviewResponse struct {
+	*service.CheckoutPreview
+	Valid  bool            `json:"valid"`
+	Errors []checkoutIssue `json:"errors"`
+}
+
+// Preview handles POST /api/v1/checkout/preview
+// Returns the totals order creation would charge for the same input, without creating it.
+func (h *OrderHandler) Preview(c *gin.Context) {
+	var input service.CheckoutPreviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.UserID = &userID
+	}
+
+	preview, err := h.orderService.PreviewCheckout(c.Request.Context(), input)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	issues := []checkoutIssue{}
+	addIssue := func(field string, err error) {
+		code, message, ok := checkoutError(err)
+		if !ok {
+			code, message = "INTERNAL_ERROR", "Внутренняя ошибка сервера"
+		}
+		issues = append(issues, checkoutIssue{Field: field, Code: code, Message: message})
+	}
+	for i, line := range preview.Lines {
+		if line.Err != nil {
+			addIssue(fmt.Sprintf("items[%d]", i), line.Err)
+		}
+	}
+	if preview.DeliveryErr != nil {
+		addIssue("deliveryMethod", preview.DeliveryErr)
+	}
+	if preview.PromoErr != nil {
+		addIssue("promoCode", preview.PromoErr)
+	}
+
+	response.OK(c, checkoutPreviewResponse{
+		CheckoutPreview: preview,
+		Valid:           len(issues) == 0,
+		Errors:          issues,
+	})
+}
+
 func (h *OrderHandler) GetByOrderNumber(c *gin.Context) {
 	orderNumber := c.Param("orderNumber")
 
@@ -89,16 +177,31 @@ func (h *OrderHandler) MyOrders(c *gin.Context) {
 	response.OK(c, orders)
 }
 
+// Reorder handles POST /api/v1/orders/:orderNumber/reorder
+// Adds the items of a past order to the cart and reports what could not be added.
+func (h *OrderHandler) Reorder(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	result, err := h.orderService.Reorder(c.Request.Context(), userID, c.Param("orderNumber"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.OK(c, result)
+}
+
 func (h *OrderHandler) AdminList(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	status := c.Query("status")
 
-	filter := domain.OrderFilter{
-		Status: status,
-		Page:   page,
-		Limit:  limit,
-	}
+	filter := adminOrderFilter(c)
+	filter.Page = page
+	filter.Limit = limit
 
 	orders, total, err := h.orderService.ListOrders(c.Request.Context(), filter)
 	if err != nil {
@@ -122,6 +225,34 @@ func (h *OrderHandler) AdminList(c *gin.Context) {
 	})
 }
 
+// AdminExport handles GET /api/v1/admin/orders/export
+// Downloads the orders matching the list filters as a CSV file.
+func (h *OrderHandler) AdminExport(c *gin.Context) {
+	var buf bytes.Buffer
+	if err := h.orderService.ExportOrdersCSV(c.Request.Context(), adminOrderFilter(c), &buf); err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	fileName := fmt.Sprintf("orders-%s.csv", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// adminOrderFilter reads the admin order list filters:
+// status, paymentMethod, customerType (company | individual) and companyId.
+func adminOrderFilter(c *gin.Context) domain.OrderFilter {
+	filter := domain.OrderFilter{
+		Status:        c.Query("status"),
+		PaymentMethod: c.Query("paymentMethod"),
+		CustomerType:  c.Query("customerType"),
+	}
+	if id, err := strconv.Atoi(c.Query("companyId")); err == nil {
+		filter.CompanyID = id
+	}
+	return filter
+}
+
 func (h *OrderHandler) AdminGetByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -142,6 +273,55 @@ func (h *OrderHandler) AdminGetByID(c *gin.Context) {
 	response.OK(c, order)
 }
 
+// AdminEdit handles PUT /api/v1/admin/orders/:id
+// Replaces the items and delivery of an order that has not been shipped yet.
+func (h *OrderHandler) AdminEdit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.EditOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.EditedBy = &userID
+	}
+
+	result, err := h.orderService.EditOrder(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.OK(c, result)
+}
+
+// AdminListChanges handles GET /api/v1/admin/orders/:id/changes
+func (h *OrderHandler) AdminListChanges(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	changes, err := h.orderService.ListChanges(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	if changes == nil {
+		changes = []domain.OrderChange{}
+	}
+
+	response.OK(c, changes)
+}
+
 func (h *OrderHandler) AdminUpdateStatus(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -206,25 +386,83 @@ func (h *OrderHandler) AdminUpdateTracking(c *gin.Context) {
 	response.OK(c, order)
 }
 
+// AdminGetSettings handles GET /api/v1/admin/orders/settings
+func (h *OrderHandler) AdminGetSettings(c *gin.Context) {
+	settings, err := h.orderService.GetSettings(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, settings)
+}
+
+// AdminUpdateSettings handles PUT /api/v1/admin/orders/settings
+func (h *OrderHandler) AdminUpdateSettings(c *gin.Context) {
+	var input service.UpdateOrderSettingsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: "Некорректные данные"},
+		})
+		return
+	}
+
+	settings, err := h.orderService.UpdateSettings(c.Request.Context(), input)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, settings)
+}
+
 func (h *OrderHandler) handleError(c *gin.Context, err error) {
-	switch {
-	case errors.Is(err, domain.ErrOrderNotFound):
+	if errors.Is(err, domain.ErrOrderNotFound) {
 		response.NotFound(c, "Заказ не найден")
-	case errors.Is(err, domain.ErrProductNotFound):
-		response.Error(c, http.StatusBadRequest, "PRODUCT_NOT_FOUND", "Товар не найден")
-	case errors.Is(err, domain.ErrProductInactive):
-		response.Error(c, http.StatusBadRequest, "PRODUCT_INACTIVE", "Товар недоступен")
-	case errors.Is(err, domain.ErrInsufficientStock):
-		response.Error(c, http.StatusBadRequest, "INSUFFICIENT_STOCK", "Недостаточно товара на складе")
-	case errors.Is(err, domain.ErrPromoNotFound):
-		response.Error(c, http.StatusBadRequest, "PROMO_NOT_FOUND", "Промокод не найден")
-	case errors.Is(err, domain.ErrPromoExpired):
-		response.Error(c, http.StatusBadRequest, "PROMO_EXPIRED", "Срок действия промокода истёк")
-	case errors.Is(err, domain.ErrPromoInactive):
-		response.Error(c, http.StatusBadRequest, "PROMO_INACTIVE", "Промокод неактивен")
-	case errors.Is(err, domain.ErrPromoMinAmount):
-		response.Error(c, http.StatusBadRequest, "PROMO_MIN_AMOUNT", "Минимальная сумма заказа для промокода не достигнута")
-	default:
-		response.InternalError(c)
+		return
+	}
+	if errors.Is(err, domain.ErrOrderNotEditable) {
+		response.Error(c, http.StatusConflict, "ORDER_NOT_EDITABLE", "Изменить можно только обычный заказ до отправки")
+		return
+	}
+	if errors.Is(err, domain.ErrOrderChanged) {
+		response.Error(c, http.StatusConflict, "ORDER_CHANGED", "Заказ изменился, пока вы его редактировали. Обновите страницу")
+		return
+	}
+	if code, message, ok := checkoutError(err); ok {
+		response.Error(c, http.StatusBadRequest, code, message)
+		return
+	}
+	response.InternalError(c)
+}
+
+// checkoutErrors maps order validation errors to API codes. They are returned
+// by order creation and listed per line by the checkout preview.
+var checkoutErrors = []struct {
+	err     error
+	code    string
+	message string
+}{
+	{domain.ErrProductNotFound, "PRODUCT_NOT_FOUND", "Товар не найден"},
+	{domain.ErrProductInactive, "PRODUCT_INACTIVE", "Товар недоступен"},
+	{domain.ErrInsufficientStock, "INSUFFICIENT_STOCK", "Недостаточно товара на складе"},
+	{domain.ErrDeliveryMethodRequired, "DELIVERY_METHOD_REQUIRED", "Выберите способ доставки"},
+	{domain.ErrCustomerContactRequired, "CONTACT_REQUIRED", "Укажите имя и телефон получателя"},
+	{domain.ErrAddressNotFound, "ADDRESS_NOT_FOUND", "Адрес не найден"},
+	{domain.ErrCompanyRequired, "COMPANY_REQUIRED", "Для оплаты по счёту заполните реквизиты компании в профиле"},
+	{domain.ErrPromoNotFound, "PROMO_NOT_FOUND", "Промокод не найден"},
+	{domain.ErrPromoExpired, "PROMO_EXPIRED", "Срок действия промокода истёк"},
+	{domain.ErrPromoInactive, "PROMO_INACTIVE", "Промокод неактивен"},
+	{domain.ErrPromoNotStarted, "PROMO_NOT_STARTED", "Промокод ещё не активен"},
+	{domain.ErrPromoUsedUp, "PROMO_USED_UP", "Лимит использований промокода исчерпан"},
+	{domain.ErrPromoMinAmount, "PROMO_MIN_AMOUNT", "Минимальная сумма заказа для промокода не достигнута"},
+}
+
+func checkoutError(err error) (code, message string, ok bool) {
+	for _, e := range checkoutErrors {
+		if errors.Is(err, e.err) {
+			return e.code, e.message, true
+		}
 	}
+	return "", "", false
 }
@@ -15,12 +15,18 @@ import (
 
 type PaymentHandler struct {
 	paymentService *service.PaymentService
+	idempotency    gin.HandlerFunc
 }
 
 func NewPaymentHandler(paymentService *service.PaymentService) *PaymentHandler {
 	return &PaymentHandler{paymentService: paymentService}
 }
 
+// SetIdempotency enables Idempotency-Key handling on payment link regeneration.
+func (h *PaymentHandler) SetIdempotency(mw gin.HandlerFunc) {
+	h.idempotency = mw
+}
+
 // RegisterWebhookRoute registers the public payment gateway webhook.
 // Called on the root router (outside /api/v1) to match Nginx proxy config.
 func (h *PaymentHandler) RegisterWebhookRoute(router *gin.Engine) {
@@ -35,7 +41,8 @@ func (h *PaymentHandler) RegisterMockRoutes(v1 *gin.RouterGroup) {
 
 // RegisterAdminRoutes registers admin-only payment actions.
 func (h *PaymentHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
-	rg.POST("/orders/:id/regenerate-payment", h.RegeneratePayment)
+	rg.POST("/orders/:id/regenerate-payment", idempotent(h.idempotency, h.RegeneratePayment)...)
+	rg.POST("/invoices/:number/payments", h.RecordInvoicePayment)
 }
 
 // HandleWebhook processes an incoming payment status notification from the gateway.
@@ -97,3 +104,34 @@ func (h *PaymentHandler) RegeneratePayment(c *gin.Context) {
 
 	response.OK(c, gin.H{"paymentLink": paymentURL})
 }
+
+// RecordInvoicePayment registers a bank transfer received against an invoice.
+// POST /admin/invoices/:number/payments
+func (h *PaymentHandler) RecordInvoicePayment(c *gin.Context) {
+	var input service.InvoicePaymentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	order, err := h.paymentService.RecordInvoicePayment(c.Request.Context(), c.Param("number"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound):
+			response.NotFound(c, "Счёт не найден")
+		case errors.Is(err, domain.ErrOrderAlreadyPaid):
+			response.Error(c, http.StatusConflict, "ALREADY_PAID", "Счёт уже оплачен")
+		case errors.Is(err, domain.ErrOrderStatusInvalid):
+			response.Error(c, http.StatusConflict, "ORDER_CANCELLED", "Заказ отменён")
+		case errors.Is(err, domain.ErrPaymentAmountInvalid):
+			response.Error(c, http.StatusBadRequest, "INVALID_AMOUNT", "Сумма оплаты больше остатка по счёту")
+		default:
+			response.Error(c, http.StatusBadRequest, "PAYMENT_ERROR", err.Error())
+		}
+		return
+	}
+
+	response.OK(c, order)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// PriceListHandler handles admin endpoints for quantity price breaks and customer price lists.
+type PriceListHandler struct {
+	priceListService *service.PriceListService
+}
+
+// NewPriceListHandler creates a new price list handler.
+func NewPriceListHandler(priceListService *service.PriceListService) *PriceListHandler {
+	return &PriceListHandler{priceListService: priceListService}
+}
+
+// RegisterAdminRoutes registers admin price list routes.
+func (h *PriceListHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	lists := rg.Group("/price-lists")
+	lists.GET("", h.List)
+	lists.POST("", h.Create)
+	lists.GET("/:id", h.GetByID)
+	lists.PUT("/:id", h.Update)
+	lists.DELETE("/:id", h.Delete)
+	lists.PUT("/:id/items", h.SetItems)
+
+	rg.GET("/products/:id/price-tiers", h.ProductTiers)
+	rg.PUT("/products/:id/price-tiers", h.SetProductTiers)
+	rg.PUT("/users/:id/price-list", h.AssignToUser)
+	rg.PUT("/companies/:id/price-list", h.AssignToCompany)
+}
+
+// List handles GET /api/v1/admin/price-lists
+func (h *PriceListHandler) List(c *gin.Context) {
+	lists, err := h.priceListService.List(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, lists)
+}
+
+// GetByID handles GET /api/v1/admin/price-lists/:id
+func (h *PriceListHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	list, err := h.priceListService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, list)
+}
+
+// Create handles POST /api/v1/admin/price-lists
+func (h *PriceListHandler) Create(c *gin.Context) {
+	var input service.PriceListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	list, err := h.priceListService.Create(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, list)
+}
+
+// Update handles PUT /api/v1/admin/price-lists/:id
+func (h *PriceListHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.PriceListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	list, err := h.priceListService.Update(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, list)
+}
+
+// Delete handles DELETE /api/v1/admin/price-lists/:id
+func (h *PriceListHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.priceListService.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// SetItems handles PUT /api/v1/admin/price-lists/:id/items
+// Replaces all product prices of the list.
+func (h *PriceListHandler) SetItems(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.SetPriceListItemsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	list, err := h.priceListService.SetItems(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, list)
+}
+
+// ProductTiers handles GET /api/v1/admin/products/:id/price-tiers
+func (h *PriceListHandler) ProductTiers(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	tiers, err := h.priceListService.ProductTiers(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, tiers)
+}
+
+// SetProductTiers handles PUT /api/v1/admin/products/:id/price-tiers
+// Replaces all quantity price breaks of the product; an empty list removes them.
+func (h *PriceListHandler) SetProductTiers(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.SetPriceTiersInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	tiers, err := h.priceListService.SetProductTiers(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, tiers)
+}
+
+// AssignToUser handles PUT /api/v1/admin/users/:id/price-list
+func (h *PriceListHandler) AssignToUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.AssignPriceListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	if err := h.priceListService.AssignToUser(c.Request.Context(), id, input.PriceListID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// AssignToCompany handles PUT /api/v1/admin/companies/:id/price-list
+func (h *PriceListHandler) AssignToCompany(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.AssignPriceListInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	if err := h.priceListService.AssignToCompany(c.Request.Context(), id, input.PriceListID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (h *PriceListHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrPriceListNotFound):
+		response.NotFound(c, "Прайс-лист не найден")
+	case errors.Is(err, domain.ErrPriceListNameExists):
+		response.Conflict(c, "Прайс-лист с таким названием уже существует")
+	case errors.Is(err, domain.ErrInvalidPriceTiers):
+		response.Error(c, http.StatusBadRequest, "INVALID_PRICE_TIERS", "Пороги количества не должны повторяться")
+	case errors.Is(err, domain.ErrProductNotFound):
+		response.NotFound(c, "Товар не найден")
+	case errors.Is(err, domain.ErrUserNotFound):
+		response.NotFound(c, "Пользователь не найден")
+	case errors.Is(err, domain.ErrCompanyNotFound):
+		response.NotFound(c, "Компания не найдена")
+	default:
+		response.InternalError(c)
+	}
+}
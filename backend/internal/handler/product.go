@@ -9,23 +9,29 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
 	"github.com/brown/3d-print-shop/internal/service"
 	"github.com/brown/3d-print-shop/pkg/response"
 )
 
 // ProductHandler handles product HTTP endpoints.
 type ProductHandler struct {
-	productService *service.ProductService
+	productService   *service.ProductService
+	statsService     *service.ProductStatsService
+	priceListService *service.PriceListService
 }
 
 // NewProductHandler creates a new product handler.
-func NewProductHandler(productService *service.ProductService) *ProductHandler {
-	return &ProductHandler{productService: productService}
+func NewProductHandler(productService *service.ProductService, statsService *service.ProductStatsService, priceListService *service.PriceListService) *ProductHandler {
+	return &ProductHandler{productService: productService, statsService: statsService, priceListService: priceListService}
 }
 
 // RegisterPublicRoutes registers public product routes.
+// Expects a group with OptionalAuth so views are attributed to logged-in users
+// and prices follow their price list.
 func (h *ProductHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
 	rg.GET("/products", h.List)
+	rg.GET("/products/recently-viewed", h.RecentlyViewed)
 	rg.GET("/products/:slug", h.GetBySlug)
 	rg.GET("/search/suggestions", h.SearchSuggestions)
 }
@@ -34,10 +40,15 @@ func (h *ProductHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
 func (h *ProductHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
 	products := rg.Group("/products")
 	products.GET("", h.AdminList)
+	products.GET("/trash", h.Trash)
 	products.GET("/:id", h.AdminGetByID)
 	products.POST("", h.Create)
 	products.PUT("/:id", h.Update)
 	products.DELETE("/:id", h.Delete)
+	products.POST("/:id/restore", h.RestoreFromTrash)
+	products.GET("/:id/revisions", h.ListRevisions)
+	products.GET("/:id/revisions/diff", h.DiffRevisions)
+	products.POST("/:id/revisions/:revision/restore", h.RestoreRevision)
 }
 
 // parseProductFilter extracts product filter from query parameters.
@@ -71,14 +82,17 @@ func (h *ProductHandler) parseProductFilter(c *gin.Context) domain.ProductFilter
 }
 
 // List handles GET /api/v1/products (public, active only)
+// Prices are the signed-in customer's, with quantity price breaks.
 func (h *ProductHandler) List(c *gin.Context) {
 	filter := h.parseProductFilter(c)
+	filter.Locale = middleware.GetLocale(c)
 
 	result, err := h.productService.List(c.Request.Context(), filter)
 	if err != nil {
 		response.InternalError(c)
 		return
 	}
+	h.priceListService.ApplyToProducts(c.Request.Context(), viewerInfo(c).UserID, result.Products)
 
 	response.Paginated(c, result.Products, response.PaginationMeta{
 		Page:       result.Page,
@@ -138,6 +152,10 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.CreatedBy = &userID
+	}
+
 	product, err := h.productService.Create(c.Request.Context(), input)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductSlugExists) {
@@ -152,11 +170,17 @@ func (h *ProductHandler) Create(c *gin.Context) {
 }
 
 // GetBySlug handles GET /api/v1/products/:slug
+// An old slug of a renamed product returns 301 with the new slug.
 func (h *ProductHandler) GetBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
-	product, err := h.productService.GetBySlug(c.Request.Context(), slug)
+	product, err := h.productService.GetBySlug(c.Request.Context(), slug, middleware.GetLocale(c))
 	if err != nil {
+		var moved *domain.SlugMovedError
+		if errors.As(err, &moved) {
+			response.MovedPermanently(c, "/api/v1/products/"+moved.NewSlug, moved.NewSlug)
+			return
+		}
 		if errors.Is(err, domain.ErrProductNotFound) {
 			response.NotFound(c, "Товар не найден")
 			return
@@ -165,7 +189,40 @@ func (h *ProductHandler) GetBySlug(c *gin.Context) {
 		return
 	}
 
-	response.OK(c, product)
+	viewer := viewerInfo(c)
+	h.statsService.RecordView(c.Request.Context(), product.ID, viewer)
+	products := []domain.Product{*product}
+	h.priceListService.ApplyToProducts(c.Request.Context(), viewer.UserID, products)
+	response.OK(c, products[0])
+}
+
+// RecentlyViewed handles GET /api/v1/products/recently-viewed?limit=
+// Visitors are identified by JWT or the X-Session-ID header.
+func (h *ProductHandler) RecentlyViewed(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	viewer := viewerInfo(c)
+	products, err := h.statsService.RecentlyViewed(c.Request.Context(), viewer, limit)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	h.priceListService.ApplyToProducts(c.Request.Context(), viewer.UserID, products)
+
+	response.OK(c, products)
+}
+
+// viewerInfo identifies the visitor for view counting.
+func viewerInfo(c *gin.Context) service.ViewerInfo {
+	viewer := service.ViewerInfo{
+		SessionID: c.GetHeader("X-Session-ID"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		viewer.UserID = &userID
+	}
+	return viewer
 }
 
 // Update handles PUT /api/v1/admin/products/:id
@@ -184,6 +241,10 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.ChangedBy = &userID
+	}
+
 	product, err := h.productService.Update(c.Request.Context(), id, input)
 	if err != nil {
 		switch {
@@ -191,6 +252,8 @@ func (h *ProductHandler) Update(c *gin.Context) {
 			response.NotFound(c, "Товар не найден")
 		case errors.Is(err, domain.ErrProductSlugExists):
 			response.Conflict(c, "Товар с таким slug уже существует")
+		case errors.Is(err, domain.ErrInsufficientStock):
+			response.Error(c, http.StatusBadRequest, "INVALID_STOCK", "Остаток не может быть отрицательным")
 		default:
 			response.InternalError(c)
 		}
@@ -208,7 +271,12 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.productService.Delete(c.Request.Context(), id)
+	var deletedBy *int
+	if userID, ok := middleware.GetUserID(c); ok {
+		deletedBy = &userID
+	}
+
+	err = h.productService.Delete(c.Request.Context(), id, deletedBy)
 	if err != nil {
 		if errors.Is(err, domain.ErrProductNotFound) {
 			response.NotFound(c, "Товар не найден")
@@ -221,6 +289,170 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 	response.NoContent(c)
 }
 
+// Trash handles GET /api/v1/admin/products/trash
+func (h *ProductHandler) Trash(c *gin.Context) {
+	page, limit := 1, 20
+	if p, _ := strconv.Atoi(c.DefaultQuery("page", "1")); p > 0 {
+		page = p
+	}
+	if l, _ := strconv.Atoi(c.DefaultQuery("limit", "20")); l > 0 && l <= 100 {
+		limit = l
+	}
+
+	result, err := h.productService.ListTrash(c.Request.Context(), c.Query("search"), page, limit)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	response.Paginated(c, result.Products, response.PaginationMeta{
+		Page:       result.Page,
+		Limit:      result.Limit,
+		Total:      result.Total,
+		TotalPages: result.TotalPages,
+	})
+}
+
+// RestoreFromTrash handles POST /api/v1/admin/products/:id/restore
+func (h *ProductHandler) RestoreFromTrash(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var restoredBy *int
+	if userID, ok := middleware.GetUserID(c); ok {
+		restoredBy = &userID
+	}
+
+	product, err := h.productService.RestoreFromTrash(c.Request.Context(), id, restoredBy)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			response.NotFound(c, "Товар не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	response.OK(c, product)
+}
+
+// ListRevisions handles GET /api/v1/admin/products/:id/revisions
+func (h *ProductHandler) ListRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+	page, limit := 1, 20
+	if p, _ := strconv.Atoi(c.DefaultQuery("page", "1")); p > 0 {
+		page = p
+	}
+	if l, _ := strconv.Atoi(c.DefaultQuery("limit", "20")); l > 0 && l <= 100 {
+		limit = l
+	}
+
+	revisions, total, err := h.productService.ListRevisions(c.Request.Context(), id, page, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			response.NotFound(c, "Товар не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+	response.Paginated(c, revisions, response.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// DiffRevisions handles GET /api/v1/admin/products/:id/revisions/diff?from=3&to=5
+// Without "to" the revision is compared with the current product.
+func (h *ProductHandler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from < 1 {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Field: "from", Message: "Укажите номер ревизии"},
+		})
+		return
+	}
+	to := 0
+	if v := c.Query("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil || to < 1 {
+			response.ValidationError(c, []response.ErrorDetail{
+				{Field: "to", Message: "Некорректный номер ревизии"},
+			})
+			return
+		}
+	}
+
+	diff, err := h.productService.DiffRevisions(c.Request.Context(), id, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProductRevisionNotFound):
+			response.NotFound(c, "Ревизия не найдена")
+		case errors.Is(err, domain.ErrProductNotFound):
+			response.NotFound(c, "Товар не найден")
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	response.OK(c, diff)
+}
+
+// RestoreRevision handles POST /api/v1/admin/products/:id/revisions/:revision/restore
+func (h *ProductHandler) RestoreRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный номер ревизии")
+		return
+	}
+
+	var restoredBy *int
+	if userID, ok := middleware.GetUserID(c); ok {
+		restoredBy = &userID
+	}
+
+	product, err := h.productService.RestoreRevision(c.Request.Context(), id, revision, restoredBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProductRevisionNotFound):
+			response.NotFound(c, "Ревизия не найдена")
+		case errors.Is(err, domain.ErrProductNotFound):
+			response.NotFound(c, "Товар не найден")
+		case errors.Is(err, domain.ErrProductSlugExists):
+			response.Conflict(c, "Slug этой ревизии уже занят другим товаром")
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+
+	response.OK(c, product)
+}
+
 // SearchSuggestions handles GET /api/v1/search/suggestions?q=...
 func (h *ProductHandler) SearchSuggestions(c *gin.Context) {
 	q := c.Query("q")
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// ReturnHandler handles return requests of customers and their processing in the admin panel.
+type ReturnHandler struct {
+	returnService *service.ReturnService
+}
+
+// NewReturnHandler creates a new return handler.
+func NewReturnHandler(returnService *service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+// RegisterProtectedRoutes registers customer routes that require authentication.
+func (h *ReturnHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
+	returns := rg.Group("/returns")
+	returns.POST("", h.Create)
+	returns.GET("/my", h.ListMine)
+	returns.GET("/:id", h.GetMine)
+	returns.POST("/:id/photos", h.UploadPhoto)
+	returns.PUT("/:id/shipment", h.Ship)
+}
+
+// RegisterAdminRoutes registers admin return routes.
+func (h *ReturnHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	returns := rg.Group("/returns")
+	returns.GET("", h.AdminList)
+	returns.GET("/:id", h.AdminGetByID)
+	returns.POST("/:id/approve", h.AdminApprove)
+	returns.POST("/:id/reject", h.AdminReject)
+	returns.POST("/:id/receive", h.AdminReceive)
+	returns.POST("/:id/refund", h.AdminRefund)
+}
+
+// Create handles POST /api/v1/returns
+func (h *ReturnHandler) Create(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	var input service.CreateReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	req, err := h.returnService.Create(c.Request.Context(), userID, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, req)
+}
+
+// ListMine handles GET /api/v1/returns/my
+func (h *ReturnHandler) ListMine(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	reqs, err := h.returnService.ListMine(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	if reqs == nil {
+		reqs = []domain.ReturnRequest{}
+	}
+	response.OK(c, reqs)
+}
+
+// GetMine handles GET /api/v1/returns/:id
+func (h *ReturnHandler) GetMine(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	req, err := h.returnService.GetMine(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, req)
+}
+
+// UploadPhoto handles POST /api/v1/returns/:id/photos
+// Multipart form, field "file". Up to 5 JPG/PNG/WebP photos before the request is reviewed.
+func (h *ReturnHandler) UploadPhoto(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "NO_FILE", "Файл не загружен (поле 'file')")
+		return
+	}
+	defer file.Close()
+
+	url, err := h.returnService.UploadPhoto(c.Request.Context(), userID, id, file, header.Size)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrTooManyFiles):
+			response.Error(c, http.StatusUnprocessableEntity, "TOO_MANY_FILES", err.Error())
+		case errors.Is(err, service.ErrPhotoTooLarge):
+			response.Error(c, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", err.Error())
+		case errors.Is(err, service.ErrUnsupportedPhoto):
+			response.Error(c, http.StatusBadRequest, "UNSUPPORTED_FORMAT", err.Error())
+		default:
+			h.handleError(c, err)
+		}
+		return
+	}
+	response.Created(c, gin.H{"url": url})
+}
+
+// Ship handles PUT /api/v1/returns/:id/shipment
+func (h *ReturnHandler) Ship(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.ShipReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	req, err := h.returnService.Ship(c.Request.Context(), userID, id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, req)
+}
+
+// AdminList handles GET /api/v1/admin/returns
+func (h *ReturnHandler) AdminList(c *gin.Context) {
+	filter := domain.ReturnFilter{
+		Status: c.Query("status"),
+		Page:   1,
+		Limit:  20,
+	}
+	if p, _ := strconv.Atoi(c.DefaultQuery("page", "1")); p > 0 {
+		filter.Page = p
+	}
+	if l, _ := strconv.Atoi(c.DefaultQuery("limit", "20")); l > 0 && l <= 100 {
+		filter.Limit = l
+	}
+
+	reqs, total, err := h.returnService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	totalPages := int(total) / filter.Limit
+	if int(total)%filter.Limit > 0 {
+		totalPages++
+	}
+	response.Paginated(c, reqs, response.PaginationMeta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// AdminGetByID handles GET /api/v1/admin/returns/:id
+func (h *ReturnHandler) AdminGetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	req, err := h.returnService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, req)
+}
+
+type reviewReturnInput struct {
+	Comment *string `json:"comment"`
+}
+
+// AdminApprove handles POST /api/v1/admin/returns/:id/approve
+// The comment tells the customer where and how to send the items.
+func (h *ReturnHandler) AdminApprove(c *gin.Context) {
+	h.review(c, true)
+}
+
+// AdminReject handles POST /api/v1/admin/returns/:id/reject
+func (h *ReturnHandler) AdminReject(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *ReturnHandler) review(c *gin.Context, approve bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input reviewReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	req, err := h.returnService.Review(c.Request.Context(), id, approve, input.Comment)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, req)
+}
+
+// AdminReceive handles POST /api/v1/admin/returns/:id/receive
+// Records the inspection result of each item; resellable items go back to stock.
+func (h *ReturnHandler) AdminReceive(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.InspectReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.ReceivedBy = &userID
+	}
+
+	req, err := h.returnService.Receive(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, req)
+}
+
+// AdminRefund handles POST /api/v1/admin/returns/:id/refund
+func (h *ReturnHandler) AdminRefund(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.RefundReturnInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	req, err := h.returnService.Refund(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, req)
+}
+
+func (h *ReturnHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrReturnNotFound):
+		response.NotFound(c, "Заявка на возврат не найдена")
+	case errors.Is(err, domain.ErrOrderNotFound):
+		response.NotFound(c, "Заказ не найден")
+	case errors.Is(err, domain.ErrReturnNotAllowed):
+		response.Error(c, http.StatusConflict, "RETURN_NOT_ALLOWED", "Вернуть можно только товары из доставленного заказа")
+	case errors.Is(err, domain.ErrReturnInvalidItems):
+		response.Error(c, http.StatusBadRequest, "INVALID_RETURN_ITEMS", "Проверьте позиции и количество для возврата")
+	case errors.Is(err, domain.ErrReturnInvalidStatus):
+		response.Error(c, http.StatusConflict, "INVALID_RETURN_STATUS", "Действие недоступно в текущем статусе заявки")
+	case errors.Is(err, domain.ErrReturnRefundAmount):
+		response.Error(c, http.StatusBadRequest, "INVALID_REFUND_AMOUNT", "Сумма возврата превышает стоимость возвращаемых товаров или оплаченную сумму")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// SaleCampaignHandler handles sale campaign and price history admin endpoints.
+type SaleCampaignHandler struct {
+	campaignService *service.SaleCampaignService
+}
+
+// NewSaleCampaignHandler creates a new sale campaign handler.
+func NewSaleCampaignHandler(campaignService *service.SaleCampaignService) *SaleCampaignHandler {
+	return &SaleCampaignHandler{campaignService: campaignService}
+}
+
+// RegisterAdminRoutes registers admin sale campaign routes.
+func (h *SaleCampaignHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	campaigns := rg.Group("/sale-campaigns")
+	campaigns.GET("", h.List)
+	campaigns.GET("/:id", h.GetByID)
+	campaigns.POST("", h.Create)
+	campaigns.PUT("/:id", h.Update)
+	campaigns.DELETE("/:id", h.Delete)
+	campaigns.POST("/:id/start", h.Start)
+	campaigns.POST("/:id/stop", h.Stop)
+
+	rg.GET("/products/:id/price-history", h.PriceHistory)
+}
+
+// List handles GET /api/v1/admin/sale-campaigns?status=
+func (h *SaleCampaignHandler) List(c *gin.Context) {
+	campaigns, err := h.campaignService.List(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, campaigns)
+}
+
+// GetByID handles GET /api/v1/admin/sale-campaigns/:id
+func (h *SaleCampaignHandler) GetByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	campaign, err := h.campaignService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// Create handles POST /api/v1/admin/sale-campaigns
+func (h *SaleCampaignHandler) Create(c *gin.Context) {
+	var input service.CreateSaleCampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	campaign, err := h.campaignService.Create(c.Request.Context(), input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, campaign)
+}
+
+// Update handles PUT /api/v1/admin/sale-campaigns/:id
+func (h *SaleCampaignHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.UpdateSaleCampaignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	campaign, err := h.campaignService.Update(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// Delete handles DELETE /api/v1/admin/sale-campaigns/:id
+func (h *SaleCampaignHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.campaignService.Delete(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Start handles POST /api/v1/admin/sale-campaigns/:id/start
+func (h *SaleCampaignHandler) Start(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	campaign, err := h.campaignService.StartNow(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// Stop handles POST /api/v1/admin/sale-campaigns/:id/stop
+func (h *SaleCampaignHandler) Stop(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	campaign, err := h.campaignService.Stop(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.OK(c, campaign)
+}
+
+// PriceHistory handles GET /api/v1/admin/products/:id/price-history?limit=
+func (h *SaleCampaignHandler) PriceHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	history, err := h.campaignService.PriceHistory(c.Request.Context(), id, limit)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, history)
+}
+
+func (h *SaleCampaignHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSaleCampaignNotFound):
+		response.NotFound(c, "Акция не найдена")
+	case errors.Is(err, domain.ErrSaleCampaignNotEditable):
+		response.Error(c, http.StatusConflict, "CAMPAIGN_NOT_EDITABLE", "Акцию можно изменить только до её начала")
+	case errors.Is(err, domain.ErrSaleCampaignActive):
+		response.Error(c, http.StatusConflict, "CAMPAIGN_ACTIVE", "Сначала остановите акцию, чтобы вернуть цены")
+	case errors.Is(err, domain.ErrSaleCampaignEmpty):
+		response.Error(c, http.StatusBadRequest, "CAMPAIGN_EMPTY", "Укажите товары или категории акции")
+	case errors.Is(err, domain.ErrSaleCampaignInvalidPeriod):
+		response.Error(c, http.StatusBadRequest, "CAMPAIGN_INVALID_PERIOD", "Некорректный период акции")
+	case errors.Is(err, domain.ErrSaleCampaignInvalidDiscount):
+		response.Error(c, http.StatusBadRequest, "CAMPAIGN_INVALID_DISCOUNT", "Скидка в процентах должна быть меньше 100")
+	default:
+		response.InternalError(c)
+	}
+}
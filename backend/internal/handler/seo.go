@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// SEOHandler serves sitemap.xml, robots.txt and product structured data.
+type SEOHandler struct {
+	seoService *service.SEOService
+}
+
+// NewSEOHandler creates a new SEO handler.
+func NewSEOHandler(seoService *service.SEOService) *SEOHandler {
+	return &SEOHandler{seoService: seoService}
+}
+
+// RegisterRootRoutes registers crawler files outside /api/v1.
+// The storefront proxies these paths to the backend.
+func (h *SEOHandler) RegisterRootRoutes(r gin.IRouter) {
+	r.GET("/sitemap.xml", h.Sitemap)
+	r.GET("/sitemaps/:file", h.SitemapPage)
+	r.GET("/robots.txt", h.Robots)
+}
+
+// RegisterPublicRoutes registers public SEO routes.
+func (h *SEOHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.GET("/products/:slug/structured-data", h.ProductStructuredData)
+}
+
+// Sitemap handles GET /sitemap.xml
+func (h *SEOHandler) Sitemap(c *gin.Context) {
+	body, err := h.seoService.Sitemap(c.Request.Context())
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// SitemapPage handles GET /sitemaps/:file (e.g. /sitemaps/2.xml)
+func (h *SEOHandler) SitemapPage(c *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".xml"))
+	if err != nil || page < 1 {
+		response.NotFound(c, "Страница не найдена")
+		return
+	}
+
+	body, err := h.seoService.SitemapPage(c.Request.Context(), page)
+	if err != nil {
+		if errors.Is(err, service.ErrSitemapPageNotFound) {
+			response.NotFound(c, "Страница не найдена")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// Robots handles GET /robots.txt
+func (h *SEOHandler) Robots(c *gin.Context) {
+	c.String(http.StatusOK, h.seoService.Robots())
+}
+
+// ProductStructuredData handles GET /api/v1/products/:slug/structured-data
+// Returns schema.org Product JSON-LD to embed into the product page.
+func (h *SEOHandler) ProductStructuredData(c *gin.Context) {
+	ld, err := h.seoService.ProductStructuredData(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			response.NotFound(c, "Товар не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, ld)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// SlugRedirectHandler handles admin management of old slug redirects.
+type SlugRedirectHandler struct {
+	slugRedirectService *service.SlugRedirectService
+}
+
+// NewSlugRedirectHandler creates a new slug redirect handler.
+func NewSlugRedirectHandler(slugRedirectService *service.SlugRedirectService) *SlugRedirectHandler {
+	return &SlugRedirectHandler{slugRedirectService: slugRedirectService}
+}
+
+// RegisterAdminRoutes registers admin slug redirect routes.
+func (h *SlugRedirectHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	redirects := rg.Group("/slug-redirects")
+	redirects.GET("", h.List)
+	redirects.POST("/cleanup", h.Cleanup)
+	redirects.DELETE("/:id", h.Delete)
+}
+
+// List handles GET /api/v1/admin/slug-redirects?type=product&search=&page=&limit=
+func (h *SlugRedirectHandler) List(c *gin.Context) {
+	filter := domain.SlugRedirectFilter{
+		EntityType: c.Query("type"),
+		Search:     c.Query("search"),
+		Page:       1,
+		Limit:      20,
+	}
+	if p, _ := strconv.Atoi(c.DefaultQuery("page", "1")); p > 0 {
+		filter.Page = p
+	}
+	if l, _ := strconv.Atoi(c.DefaultQuery("limit", "20")); l > 0 && l <= 100 {
+		filter.Limit = l
+	}
+
+	redirects, total, err := h.slugRedirectService.List(c.Request.Context(), filter)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+
+	totalPages := int(total) / filter.Limit
+	if int(total)%filter.Limit > 0 {
+		totalPages++
+	}
+	response.Paginated(c, redirects, response.PaginationMeta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// Delete handles DELETE /api/v1/admin/slug-redirects/:id
+func (h *SlugRedirectHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.slugRedirectService.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrSlugRedirectNotFound) {
+			response.NotFound(c, "Редирект не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	response.NoContent(c)
+}
+
+type cleanupRedirectsRequest struct {
+	UnusedDays int `json:"unusedDays" binding:"required,min=1"`
+}
+
+// Cleanup handles POST /api/v1/admin/slug-redirects/cleanup
+// Removes redirects that nobody followed for unusedDays.
+func (h *SlugRedirectHandler) Cleanup(c *gin.Context) {
+	var req cleanupRedirectsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Field: "unusedDays", Message: "Укажите количество дней (не меньше 1)"},
+		})
+		return
+	}
+
+	deleted, err := h.slugRedirectService.Cleanup(c.Request.Context(), req.UnusedDays)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, gin.H{"deleted": deleted})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// StockHandler handles admin endpoints of the stock ledger.
+type StockHandler struct {
+	stockService *service.StockService
+}
+
+// NewStockHandler creates a new stock handler.
+func NewStockHandler(stockService *service.StockService) *StockHandler {
+	return &StockHandler{stockService: stockService}
+}
+
+// RegisterAdminRoutes registers admin stock routes.
+func (h *StockHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/products/:id/stock-movements", h.History)
+	rg.POST("/products/:id/stock-receipts", h.Receive)
+	rg.POST("/products/:id/stock-adjustments", h.Adjust)
+}
+
+// History handles GET /api/v1/admin/products/:id/stock-movements
+// Query: type, page, limit.
+func (h *StockHandler) History(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	filter := domain.StockMovementFilter{
+		ProductID: id,
+		Type:      c.Query("type"),
+		Page:      1,
+		Limit:     50,
+	}
+	if p, _ := strconv.Atoi(c.DefaultQuery("page", "1")); p > 0 {
+		filter.Page = p
+	}
+	if l, _ := strconv.Atoi(c.DefaultQuery("limit", "50")); l > 0 && l <= 100 {
+		filter.Limit = l
+	}
+
+	movements, total, err := h.stockService.History(c.Request.Context(), filter)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	totalPages := int(total) / filter.Limit
+	if int(total)%filter.Limit > 0 {
+		totalPages++
+	}
+	response.Paginated(c, movements, response.PaginationMeta{
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// Receive handles POST /api/v1/admin/products/:id/stock-receipts
+// type: "receipt" (default, from a supplier) | "production" (printed to stock).
+func (h *StockHandler) Receive(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.StockReceiptInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.CreatedBy = &userID
+	}
+
+	movement, err := h.stockService.Receive(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, movement)
+}
+
+// Adjust handles POST /api/v1/admin/products/:id/stock-adjustments
+// Body: quantity (change) or actual (counted stock), and a comment.
+func (h *StockHandler) Adjust(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	var input service.StockAdjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+	if userID, ok := middleware.GetUserID(c); ok {
+		input.CreatedBy = &userID
+	}
+
+	movement, err := h.stockService.Adjust(c.Request.Context(), id, input)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Created(c, movement)
+}
+
+func (h *StockHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		response.NotFound(c, "Товар не найден")
+	case errors.Is(err, domain.ErrInvalidStockMovement):
+		response.Error(c, http.StatusBadRequest, "INVALID_STOCK_MOVEMENT", "Укажите ненулевое изменение quantity или фактический остаток actual, отличный от текущего")
+	case errors.Is(err, domain.ErrInsufficientStock):
+		response.Error(c, http.StatusConflict, "INSUFFICIENT_STOCK", "Остаток не может стать отрицательным")
+	default:
+		response.InternalError(c)
+	}
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// TranslationHandler handles admin endpoints for catalog translations.
+type TranslationHandler struct {
+	translationService *service.TranslationService
+}
+
+// NewTranslationHandler creates a new translation handler.
+func NewTranslationHandler(translationService *service.TranslationService) *TranslationHandler {
+	return &TranslationHandler{translationService: translationService}
+}
+
+// RegisterAdminRoutes registers admin translation routes.
+func (h *TranslationHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/translations/locales", h.Locales)
+
+	for _, r := range []struct {
+		path       string
+		entityType string
+	}{
+		{"/products/:id/translations", domain.TranslationEntityProduct},
+		{"/categories/:id/translations", domain.TranslationEntityCategory},
+		{"/content/:slug/translations", domain.TranslationEntityContentBlock},
+	} {
+		rg.GET(r.path, h.List(r.entityType))
+		rg.PUT(r.path+"/:locale", h.Save(r.entityType))
+		rg.DELETE(r.path+"/:locale", h.Delete(r.entityType))
+	}
+}
+
+// Locales handles GET /api/v1/admin/translations/locales
+func (h *TranslationHandler) Locales(c *gin.Context) {
+	locales := h.translationService.Locales()
+	response.OK(c, gin.H{
+		"defaultLocale": locales[0],
+		"locales":       locales,
+		"fields":        domain.TranslatableFields,
+	})
+}
+
+// entityID resolves the translated entity from the path: a numeric ID for
+// products and categories, a slug for content blocks. It writes the error
+// response itself and returns false on failure.
+func (h *TranslationHandler) entityID(c *gin.Context, entityType string) (int, bool) {
+	if entityType == domain.TranslationEntityContentBlock {
+		id, err := h.translationService.ContentBlockID(c.Request.Context(), c.Param("slug"))
+		if err != nil {
+			response.NotFound(c, "Контентный блок не найден")
+			return 0, false
+		}
+		return id, true
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// List handles GET /api/v1/admin/{products/:id,categories/:id,content/:slug}/translations
+func (h *TranslationHandler) List(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := h.entityID(c, entityType)
+		if !ok {
+			return
+		}
+
+		translations, err := h.translationService.List(c.Request.Context(), entityType, id)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		response.OK(c, translations)
+	}
+}
+
+type saveTranslationInput struct {
+	Fields domain.TranslationFields `json:"fields"`
+	Data   json.RawMessage          `json:"data"`
+}
+
+// Save handles PUT /api/v1/admin/{products/:id,categories/:id,content/:slug}/translations/:locale
+// Products and categories take {"fields": {...}}, content blocks take {"data": {...}}.
+func (h *TranslationHandler) Save(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := h.entityID(c, entityType)
+		if !ok {
+			return
+		}
+
+		var input saveTranslationInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			response.ValidationError(c, []response.ErrorDetail{
+				{Message: "Некорректные данные"},
+			})
+			return
+		}
+
+		t, err := h.translationService.Save(c.Request.Context(), service.SaveTranslationInput{
+			EntityType: entityType,
+			EntityID:   id,
+			Locale:     c.Param("locale"),
+			Fields:     input.Fields,
+			Data:       input.Data,
+		})
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		response.OK(c, t)
+	}
+}
+
+// Delete handles DELETE /api/v1/admin/{products/:id,categories/:id,content/:slug}/translations/:locale
+func (h *TranslationHandler) Delete(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := h.entityID(c, entityType)
+		if !ok {
+			return
+		}
+
+		if err := h.translationService.Delete(c.Request.Context(), entityType, id, c.Param("locale")); err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		response.NoContent(c)
+	}
+}
+
+func (h *TranslationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		response.NotFound(c, "Товар не найден")
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		response.NotFound(c, "Категория не найдена")
+	case errors.Is(err, domain.ErrTranslationNotFound):
+		response.NotFound(c, "Перевод не найден")
+	case errors.Is(err, domain.ErrUnsupportedLocale):
+		response.Error(c, http.StatusBadRequest, "UNSUPPORTED_LOCALE", "Язык не поддерживается")
+	case errors.Is(err, domain.ErrTranslationField):
+		response.ValidationError(c, []response.ErrorDetail{
+			{Field: "fields", Message: "Поле нельзя перевести или перевод пуст"},
+		})
+	default:
+		response.InternalError(c)
+	}
+}
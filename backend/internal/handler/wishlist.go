@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/middleware"
+	"github.com/brown/3d-print-shop/internal/service"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+// WishlistHandler handles wishlists and back-in-stock subscriptions.
+type WishlistHandler struct {
+	wishlistService   *service.WishlistService
+	stockAlertService *service.StockAlertService
+}
+
+// NewWishlistHandler creates a new wishlist handler.
+func NewWishlistHandler(wishlistService *service.WishlistService, stockAlertService *service.StockAlertService) *WishlistHandler {
+	return &WishlistHandler{wishlistService: wishlistService, stockAlertService: stockAlertService}
+}
+
+// RegisterPublicRoutes registers the subscription endpoint, open to guests.
+// Expects a group with optional auth so logged-in users get their own contacts.
+func (h *WishlistHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.POST("/stock-subscriptions", h.Subscribe)
+}
+
+// RegisterProtectedRoutes registers wishlist and subscription routes of the current user.
+func (h *WishlistHandler) RegisterProtectedRoutes(rg *gin.RouterGroup) {
+	me := rg.Group("/users/me")
+	me.GET("/wishlist", h.List)
+	me.POST("/wishlist", h.Add)
+	me.DELETE("/wishlist/:productId", h.Remove)
+	me.GET("/stock-subscriptions", h.ListSubscriptions)
+	me.DELETE("/stock-subscriptions/:id", h.Unsubscribe)
+}
+
+// List handles GET /api/v1/users/me/wishlist
+func (h *WishlistHandler) List(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	items, err := h.wishlistService.List(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, items)
+}
+
+type addWishlistRequest struct {
+	ProductID int `json:"productId" binding:"required,min=1"`
+}
+
+// Add handles POST /api/v1/users/me/wishlist
+func (h *WishlistHandler) Add(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	var req addWishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	if err := h.wishlistService.Add(c.Request.Context(), userID, req.ProductID); err != nil {
+		if errors.Is(err, domain.ErrProductNotFound) {
+			response.NotFound(c, "Товар не найден")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Remove handles DELETE /api/v1/users/me/wishlist/:productId
+func (h *WishlistHandler) Remove(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.wishlistService.Remove(c.Request.Context(), userID, productID); err != nil {
+		if errors.Is(err, domain.ErrWishlistItemNotFound) {
+			response.NotFound(c, "Товара нет в избранном")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	response.NoContent(c)
+}
+
+// Subscribe handles POST /api/v1/stock-subscriptions
+func (h *WishlistHandler) Subscribe(c *gin.Context) {
+	var input service.SubscribeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		response.ValidationError(c, []response.ErrorDetail{
+			{Message: err.Error()},
+		})
+		return
+	}
+
+	var userID *int
+	if id, ok := middleware.GetUserID(c); ok {
+		userID = &id
+	}
+
+	sub, err := h.stockAlertService.Subscribe(c.Request.Context(), userID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrProductNotFound):
+			response.NotFound(c, "Товар не найден")
+		case errors.Is(err, domain.ErrProductInStock):
+			response.Error(c, http.StatusConflict, "PRODUCT_IN_STOCK", "Товар уже доступен для заказа")
+		case errors.Is(err, domain.ErrSubscriptionContact):
+			if input.Channel == domain.StockChannelTelegram {
+				response.Error(c, http.StatusBadRequest, "TELEGRAM_NOT_LINKED", "Войдите через Telegram, чтобы получать уведомления в боте")
+			} else {
+				response.Error(c, http.StatusBadRequest, "EMAIL_REQUIRED", "Укажите email для уведомления")
+			}
+		default:
+			response.InternalError(c)
+		}
+		return
+	}
+	response.Created(c, sub)
+}
+
+// ListSubscriptions handles GET /api/v1/users/me/stock-subscriptions
+func (h *WishlistHandler) ListSubscriptions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	subs, err := h.stockAlertService.ListMine(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c)
+		return
+	}
+	response.OK(c, subs)
+}
+
+// Unsubscribe handles DELETE /api/v1/users/me/stock-subscriptions/:id
+func (h *WishlistHandler) Unsubscribe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		response.Unauthorized(c, "Требуется авторизация")
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "Некорректный ID")
+		return
+	}
+
+	if err := h.stockAlertService.Unsubscribe(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, domain.ErrStockSubscriptionNotFound) {
+			response.NotFound(c, "Подписка не найдена")
+			return
+		}
+		response.InternalError(c)
+		return
+	}
+	response.NoContent(c)
+}
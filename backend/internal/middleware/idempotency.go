@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/pkg/response"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyPrefix      = "idempotency:"
+	idempotencyMaxKeyLength   = 255
+	idempotencyInFlightExpiry = 2 * time.Minute
+)
+
+// idempotencyRecord is what is kept in Redis under an Idempotency-Key.
+// Done is false while the first request is still being handled.
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// idempotencyWriter keeps a copy of the response body.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes a route safe to retry when the client sends an
+// Idempotency-Key header. The first response is stored for ttl and replayed
+// for repeats of the same request; the same key with a different payload is
+// rejected. Requests without the header pass through unchanged.
+// Server errors are not stored, so the client may retry them with the same key.
+func Idempotency(store *cache.Store, ttl time.Duration, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeyLength {
+			response.Error(c, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Слишком длинный ключ идемпотентности")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "INVALID_REQUEST", "Не удалось прочитать запрос")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped by user so that one customer cannot replay another's response.
+		scope := "guest"
+		if userID, ok := GetUserID(c); ok {
+			scope = strconv.Itoa(userID)
+		}
+		sum := sha256.Sum256([]byte(key))
+		redisKey := idempotencyKeyPrefix + scope + ":" + hex.EncodeToString(sum[:])
+		fingerprint := requestFingerprint(c, body)
+
+		ctx := c.Request.Context()
+		acquired, err := store.SetNX(ctx, redisKey, idempotencyRecord{Fingerprint: fingerprint}, idempotencyInFlightExpiry)
+		if err != nil {
+			// Without Redis the request is handled as if no key was sent.
+			log.Warn("idempotency store unavailable", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			var rec idempotencyRecord
+			found, err := store.Get(ctx, redisKey, &rec)
+			if err != nil || !found {
+				// The in-flight record expired between the two calls.
+				response.Error(c, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "Запрос с этим ключом ещё обрабатывается")
+				c.Abort()
+				return
+			}
+			switch {
+			case rec.Fingerprint != fingerprint:
+				response.Error(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Ключ идемпотентности уже использован с другими данными")
+			case !rec.Done:
+				response.Error(c, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "Запрос с этим ключом ещё обрабатывается")
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(rec.Status, rec.ContentType, rec.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		w := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Delete(ctx, redisKey); err != nil {
+				log.Warn("failed to release idempotency key", zap.Error(err))
+			}
+			return
+		}
+		rec := idempotencyRecord{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        w.body.Bytes(),
+		}
+		if err := store.Set(ctx, redisKey, rec, ttl); err != nil {
+			log.Warn("failed to store idempotent response", zap.Error(err))
+		}
+	}
+}
+
+// requestFingerprint identifies the request a key was first used with.
+func requestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Request.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
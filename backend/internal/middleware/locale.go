@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContextKeyLocale = "locale"
+
+// Locale picks the response language from the ?lang= parameter or the
+// Accept-Language header, falling back to defaultLocale. Only the primary
+// language subtag is matched: "kk-KZ" selects "kk".
+func Locale(supported []string, defaultLocale string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(supported))
+	for _, l := range supported {
+		allowed[l] = true
+	}
+
+	return func(c *gin.Context) {
+		locale := defaultLocale
+		if lang := primaryTag(c.Query("lang")); allowed[lang] {
+			locale = lang
+		} else if lang, ok := negotiate(c.GetHeader("Accept-Language"), allowed); ok {
+			locale = lang
+		}
+
+		c.Set(ContextKeyLocale, locale)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// GetLocale returns the locale selected by the Locale middleware.
+func GetLocale(c *gin.Context) string {
+	return c.GetString(ContextKeyLocale)
+}
+
+// negotiate returns the supported language with the highest q-value.
+func negotiate(header string, allowed map[string]bool) (string, bool) {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if lang := primaryTag(tag); q > 0 && allowed[lang] {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang, true
+}
+
+func primaryTag(tag string) string {
+	tag, _, _ = strings.Cut(strings.TrimSpace(tag), "-")
+	tag, _, _ = strings.Cut(tag, "_")
+	return strings.ToLower(tag)
+}
@@ -49,6 +49,10 @@ func (p *Provider) CancelPayment(_ context.Context, _ string) error {
 	return nil
 }
 
+func (p *Provider) RefundPayment(_ context.Context, _ string, _ float64) error {
+	return nil
+}
+
 // ValidateWebhook is a no-op for the mock provider: real webhooks never arrive.
 func (p *Provider) ValidateWebhook(_ []byte, _ map[string]string) (*payment.WebhookEvent, error) {
 	return nil, fmt.Errorf("mock provider does not receive real payment webhooks")
@@ -53,6 +53,9 @@ type Provider interface {
 	// CancelPayment cancels a pending payment.
 	CancelPayment(ctx context.Context, providerPaymentID string) error
 
+	// RefundPayment returns part or all of a succeeded payment to the customer.
+	RefundPayment(ctx context.Context, providerPaymentID string, amount float64) error
+
 	// ValidateWebhook parses and validates an incoming webhook from the provider.
 	// Returns a WebhookEvent on success, or an error if the payload is invalid.
 	ValidateWebhook(body []byte, headers map[string]string) (*WebhookEvent, error)
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// AddressRepo implements domain.AddressRepository using GORM.
+type AddressRepo struct {
+	db *gorm.DB
+}
+
+// NewAddressRepo creates a new address book repository.
+func NewAddressRepo(db *gorm.DB) *AddressRepo {
+	return &AddressRepo{db: db}
+}
+
+func (r *AddressRepo) ListByUserID(ctx context.Context, userID int) ([]domain.UserAddress, error) {
+	var addresses []domain.UserAddress
+	err := r.db.WithContext(ctx).
+		Preload("PickupPoint").
+		Where("user_id = ?", userID).
+		Order("is_default DESC, updated_at DESC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *AddressRepo) FindByID(ctx context.Context, id, userID int) (*domain.UserAddress, error) {
+	var address domain.UserAddress
+	err := r.db.WithContext(ctx).
+		Preload("PickupPoint").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrAddressNotFound
+	}
+	return &address, err
+}
+
+func (r *AddressRepo) Save(ctx context.Context, address *domain.UserAddress) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := tx.Model(&domain.UserAddress{}).
+				Where("user_id = ? AND type = ? AND is_default AND id <> ?", address.UserID, address.Type, address.ID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("PickupPoint").Save(address).Error
+	})
+}
+
+func (r *AddressRepo) Delete(ctx context.Context, id, userID int) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&domain.UserAddress{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAddressNotFound
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// CartReminderRepo implements domain.CartReminderRepository using GORM.
+type CartReminderRepo struct {
+	db *gorm.DB
+}
+
+// NewCartReminderRepo creates a new cart reminder repository.
+func NewCartReminderRepo(db *gorm.DB) *CartReminderRepo {
+	return &CartReminderRepo{db: db}
+}
+
+func (r *CartReminderRepo) CreateCampaign(ctx context.Context, campaign *domain.CartReminderCampaign) error {
+	return r.db.WithContext(ctx).Create(campaign).Error
+}
+
+func (r *CartReminderRepo) FindCampaignByID(ctx context.Context, id int) (*domain.CartReminderCampaign, error) {
+	var campaign domain.CartReminderCampaign
+	err := r.db.WithContext(ctx).First(&campaign, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrCartReminderCampaignNotFound
+	}
+	return &campaign, err
+}
+
+func (r *CartReminderRepo) ListCampaigns(ctx context.Context, onlyActive bool) ([]domain.CartReminderCampaign, error) {
+	var campaigns []domain.CartReminderCampaign
+	q := r.db.WithContext(ctx).Order("delay_hours ASC, id ASC")
+	if onlyActive {
+		q = q.Where("is_active = true")
+	}
+	err := q.Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *CartReminderRepo) UpdateCampaign(ctx context.Context, campaign *domain.CartReminderCampaign) error {
+	return r.db.WithContext(ctx).Save(campaign).Error
+}
+
+func (r *CartReminderRepo) DeleteCampaign(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&domain.CartReminderCampaign{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCartReminderCampaignNotFound
+	}
+	return nil
+}
+
+func (r *CartReminderRepo) FindAbandoned(ctx context.Context, campaignID int, minTotal float64, idleBefore, cooldownAfter time.Time, limit int) ([]domain.AbandonedCart, error) {
+	var carts []domain.AbandonedCart
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.user_id, c.updated_at, c.total
+		FROM (
+			SELECT ci.user_id,
+				MAX(ci.updated_at) AS updated_at,
+				COALESCE(SUM(ci.quantity * p.price) FILTER (WHERE p.is_active), 0) AS total
+			FROM cart_items ci
+			JOIN products p ON p.id = ci.product_id
+			GROUP BY ci.user_id
+		) c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.updated_at < ?
+		  AND c.total > 0 AND c.total >= ?
+		  AND u.is_active = true
+		  AND u.cart_reminders_opt_out = false
+		  AND (u.telegram_id IS NOT NULL OR u.email IS NOT NULL)
+		  AND NOT EXISTS (
+			SELECT 1 FROM orders o WHERE o.user_id = c.user_id AND o.created_at >= c.updated_at
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM cart_reminders cr
+			WHERE cr.user_id = c.user_id
+			  AND (cr.sent_at > ? OR (cr.campaign_id = ? AND cr.sent_at >= c.updated_at))
+		  )
+		ORDER BY c.updated_at ASC
+		LIMIT ?`,
+		idleBefore, minTotal, cooldownAfter, campaignID, limit,
+	).Scan(&carts).Error
+	return carts, err
+}
+
+func (r *CartReminderRepo) Create(ctx context.Context, reminder *domain.CartReminder) error {
+	return r.db.WithContext(ctx).Create(reminder).Error
+}
+
+func (r *CartReminderRepo) Delete(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Delete(&domain.CartReminder{}, id).Error
+}
+
+func (r *CartReminderRepo) FindByToken(ctx context.Context, token string) (*domain.CartReminder, error) {
+	var reminder domain.CartReminder
+	err := r.db.WithContext(ctx).Where("token = ?", token).First(&reminder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrCartReminderNotFound
+	}
+	return &reminder, err
+}
+
+func (r *CartReminderRepo) MarkClicked(ctx context.Context, id int, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.CartReminder{}).
+		Where("id = ? AND clicked_at IS NULL", id).
+		Update("clicked_at", at).Error
+}
+
+func (r *CartReminderRepo) MarkConverted(ctx context.Context, userID, orderID int, since, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE cart_reminders SET converted_at = ?, order_id = ?
+		WHERE id = (
+			SELECT id FROM cart_reminders
+			WHERE user_id = ? AND converted_at IS NULL AND sent_at >= ?
+			ORDER BY sent_at DESC
+			LIMIT 1
+		)`,
+		at, orderID, userID, since,
+	)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *CartReminderRepo) Stats(ctx context.Context) ([]domain.CartReminderStats, error) {
+	var stats []domain.CartReminderStats
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id AS campaign_id, c.name,
+			COUNT(cr.id) AS sent,
+			COUNT(cr.clicked_at) AS clicked,
+			COUNT(cr.converted_at) AS converted,
+			COALESCE(SUM(o.total_price), 0) AS revenue
+		FROM cart_reminder_campaigns c
+		LEFT JOIN cart_reminders cr ON cr.campaign_id = c.id
+		LEFT JOIN orders o ON o.id = cr.order_id
+		GROUP BY c.id, c.name
+		ORDER BY c.id`,
+	).Scan(&stats).Error
+	return stats, err
+}
@@ -3,12 +3,23 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/brown/3d-print-shop/internal/domain"
 )
 
+const (
+	// maxCategoryDepth bounds the recursive tree queries, so a corrupted tree
+	// with a cycle cannot make them recurse forever.
+	maxCategoryDepth = 32
+	// categoryTreeLockKey is the advisory lock taken while re-parenting categories.
+	categoryTreeLockKey = 31_001
+)
+
 // CategoryRepo implements domain.CategoryRepository using GORM.
 type CategoryRepo struct {
 	db *gorm.DB
@@ -43,47 +54,176 @@ func (r *CategoryRepo) FindBySlug(ctx context.Context, slug string) (*domain.Cat
 
 // FindAll returns top-level categories with nested children (tree).
 func (r *CategoryRepo) FindAll(ctx context.Context) ([]domain.Category, error) {
-	var categories []domain.Category
+	var all []domain.Category
+	err := r.db.WithContext(ctx).
+		Order("display_order ASC, name ASC").
+		Find(&all).Error
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(all, nil), nil
+}
+
+func (r *CategoryRepo) FindRootIDs(ctx context.Context) ([]int, error) {
+	var ids []int
 	err := r.db.WithContext(ctx).
+		Model(&domain.Category{}).
 		Where("parent_id IS NULL").
 		Order("display_order ASC, name ASC").
-		Find(&categories).Error
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *CategoryRepo) FindTree(ctx context.Context, rootID int) (*domain.Category, error) {
+	var nodes []domain.Category
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT c.*, 0 AS depth FROM categories c WHERE c.id = ?
+			UNION ALL
+			SELECT c.*, t.depth + 1 FROM categories c JOIN tree t ON c.parent_id = t.id
+			WHERE t.depth < ?
+		)
+		SELECT * FROM tree ORDER BY display_order ASC, name ASC`, rootID, maxCategoryDepth).
+		Scan(&nodes).Error
 	if err != nil {
 		return nil, err
 	}
 
-	// Load children recursively
-	for i := range categories {
-		if err := r.loadChildren(ctx, &categories[i]); err != nil {
-			return nil, err
+	for i := range nodes {
+		if nodes[i].ID == rootID {
+			root := nodes[i]
+			root.Children = buildCategoryTree(nodes, &rootID)
+			return &root, nil
 		}
 	}
+	return nil, domain.ErrCategoryNotFound
+}
 
-	return categories, nil
+func (r *CategoryRepo) FindSubtreeIDs(ctx context.Context, id int) ([]int, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id, t.depth + 1 FROM categories c JOIN tree t ON c.parent_id = t.id
+			WHERE t.depth < ?
+		)
+		SELECT id FROM tree ORDER BY depth, id`, id, maxCategoryDepth).
+		Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return ids, nil
 }
 
-func (r *CategoryRepo) loadChildren(ctx context.Context, parent *domain.Category) error {
-	var children []domain.Category
-	err := r.db.WithContext(ctx).
-		Where("parent_id = ?", parent.ID).
-		Order("display_order ASC, name ASC").
-		Find(&children).Error
+func (r *CategoryRepo) FindAncestors(ctx context.Context, id int) ([]domain.Category, error) {
+	var path []domain.Category
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE path AS (
+			SELECT c.*, 0 AS depth FROM categories c WHERE c.id = ?
+			UNION ALL
+			SELECT c.*, p.depth + 1 FROM categories c JOIN path p ON c.id = p.parent_id
+			WHERE p.depth < ?
+		)
+		SELECT * FROM path ORDER BY depth DESC`, id, maxCategoryDepth).
+		Scan(&path).Error
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, domain.ErrCategoryNotFound
 	}
+	return path, nil
+}
+
+func (r *CategoryRepo) UpdateDisplayOrders(ctx context.Context, orders []domain.CategoryOrder) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, o := range orders {
+			res := tx.Model(&domain.Category{}).
+				Where("id = ?", o.ID).
+				Updates(map[string]interface{}{
+					"display_order": o.DisplayOrder,
+					"updated_at":    time.Now(),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return domain.ErrCategoryNotFound
+			}
+		}
+		return nil
+	})
+}
 
-	for i := range children {
-		if err := r.loadChildren(ctx, &children[i]); err != nil {
-			return err
+// buildCategoryTree nests a flat, already ordered list under parentID.
+func buildCategoryTree(all []domain.Category, parentID *int) []domain.Category {
+	byParent := make(map[int][]domain.Category)
+	var roots []domain.Category
+	for _, c := range all {
+		switch {
+		case parentID == nil && c.ParentID == nil:
+			roots = append(roots, c)
+		case c.ParentID != nil:
+			byParent[*c.ParentID] = append(byParent[*c.ParentID], c)
 		}
 	}
+	if parentID != nil {
+		roots = byParent[*parentID]
+	}
+
+	var attach func(nodes []domain.Category, depth int)
+	attach = func(nodes []domain.Category, depth int) {
+		if depth > 32 {
+			return
+		}
+		for i := range nodes {
+			nodes[i].Children = byParent[nodes[i].ID]
+			attach(nodes[i].Children, depth+1)
+		}
+	}
+	attach(roots, 0)
 
-	parent.Children = children
-	return nil
+	if roots == nil {
+		roots = []domain.Category{}
+	}
+	return roots
 }
 
+// Update saves the category. Tree changes are serialized by an advisory lock
+// and the new parent is checked inside it, so two concurrent moves cannot
+// put categories under each other.
 func (r *CategoryRepo) Update(ctx context.Context, category *domain.Category) error {
-	return r.db.WithContext(ctx).Save(category).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`SELECT pg_advisory_xact_lock(?)`, categoryTreeLockKey).Error; err != nil {
+			return fmt.Errorf("lock category tree: %w", err)
+		}
+		if category.ParentID != nil {
+			var path []int
+			err := tx.Raw(`
+				WITH RECURSIVE path AS (
+					SELECT id, parent_id, 0 AS depth FROM categories WHERE id = ?
+					UNION ALL
+					SELECT c.id, c.parent_id, p.depth + 1 FROM categories c JOIN path p ON c.id = p.parent_id
+					WHERE p.depth < ?
+				)
+				SELECT id FROM path`, *category.ParentID, maxCategoryDepth).
+				Scan(&path).Error
+			if err != nil {
+				return fmt.Errorf("check category parent: %w", err)
+			}
+			if len(path) == 0 {
+				return domain.ErrCategoryParentNotFound
+			}
+			if slices.Contains(path, category.ID) {
+				return domain.ErrCategoryCycle
+			}
+		}
+		return tx.Save(category).Error
+	})
 }
 
 func (r *CategoryRepo) Delete(ctx context.Context, id int) error {
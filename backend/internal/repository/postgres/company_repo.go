@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// CompanyRepo implements domain.CompanyRepository using GORM.
+type CompanyRepo struct {
+	db *gorm.DB
+}
+
+// NewCompanyRepo creates a new company repository.
+func NewCompanyRepo(db *gorm.DB) *CompanyRepo {
+	return &CompanyRepo{db: db}
+}
+
+func (r *CompanyRepo) FindByID(ctx context.Context, id int) (*domain.Company, error) {
+	var company domain.Company
+	err := r.db.WithContext(ctx).First(&company, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrCompanyNotFound
+	}
+	return &company, err
+}
+
+func (r *CompanyRepo) FindByUserID(ctx context.Context, userID int) (*domain.Company, error) {
+	var company domain.Company
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&company).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrCompanyNotFound
+	}
+	return &company, err
+}
+
+func (r *CompanyRepo) Save(ctx context.Context, company *domain.Company) error {
+	return r.db.WithContext(ctx).Save(company).Error
+}
+
+func (r *CompanyRepo) DeleteByUserID(ctx context.Context, userID int) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&domain.Company{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCompanyNotFound
+	}
+	return nil
+}
+
+func (r *CompanyRepo) List(ctx context.Context, filter domain.CompanyFilter) ([]domain.Company, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Company{})
+	if filter.Search != "" {
+		query = query.Where("legal_name ILIKE ? OR inn LIKE ?", "%"+filter.Search+"%", filter.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	var companies []domain.Company
+	err := query.
+		Order("legal_name").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&companies).Error
+	return companies, total, err
+}
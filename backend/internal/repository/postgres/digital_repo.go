@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+type DigitalRepo struct {
+	db *gorm.DB
+}
+
+func NewDigitalRepo(db *gorm.DB) *DigitalRepo {
+	return &DigitalRepo{db: db}
+}
+
+func (r *DigitalRepo) CreateFile(ctx context.Context, file *domain.ProductFile) error {
+	return r.db.WithContext(ctx).Create(file).Error
+}
+
+func (r *DigitalRepo) FindFileByID(ctx context.Context, id int) (*domain.ProductFile, error) {
+	var file domain.ProductFile
+	err := r.db.WithContext(ctx).First(&file, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrProductFileNotFound
+	}
+	return &file, err
+}
+
+func (r *DigitalRepo) ListFilesByProductIDs(ctx context.Context, productIDs []int) ([]domain.ProductFile, error) {
+	var files []domain.ProductFile
+	if len(productIDs) == 0 {
+		return files, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("product_id IN ?", productIDs).
+		Order("product_id ASC, id ASC").
+		Find(&files).Error
+	return files, err
+}
+
+func (r *DigitalRepo) DeleteFile(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&domain.ProductFile{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrProductFileNotFound
+	}
+	return nil
+}
+
+// CreateLinks inserts links, skipping files already issued for the same order.
+func (r *DigitalRepo) CreateLinks(ctx context.Context, links []domain.DownloadLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Omit("Product", "File", "Order").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "order_id"}, {Name: "product_file_id"}},
+			DoNothing: true,
+		}).
+		Create(&links).Error
+}
+
+func (r *DigitalRepo) FindLinkByToken(ctx context.Context, token string) (*domain.DownloadLink, error) {
+	var link domain.DownloadLink
+	err := r.db.WithContext(ctx).
+		Preload("File").
+		Where("token = ?", token).
+		First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrDownloadLinkNotFound
+	}
+	return &link, err
+}
+
+func (r *DigitalRepo) ListLinksByOrderID(ctx context.Context, orderID int) ([]domain.DownloadLink, error) {
+	var links []domain.DownloadLink
+	err := r.db.WithContext(ctx).
+		Preload("Product").
+		Preload("File").
+		Where("order_id = ?", orderID).
+		Order("id ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *DigitalRepo) ListLinksByUserID(ctx context.Context, userID int) ([]domain.DownloadLink, error) {
+	var links []domain.DownloadLink
+	err := r.db.WithContext(ctx).
+		Preload("Product").
+		Preload("Product.Images", "is_main = true").
+		Preload("File").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id ASC").
+		Find(&links).Error
+	return links, err
+}
+
+func (r *DigitalRepo) ConsumeDownload(ctx context.Context, id int, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.DownloadLink{}).
+		Where("id = ? AND download_count < max_downloads AND expires_at > ?", id, now).
+		Updates(map[string]interface{}{
+			"download_count":     gorm.Expr("download_count + 1"),
+			"last_downloaded_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+type OrderChangeRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderChangeRepo(db *gorm.DB) *OrderChangeRepo {
+	return &OrderChangeRepo{db: db}
+}
+
+func (r *OrderChangeRepo) ListByOrderID(ctx context.Context, orderID int) ([]domain.OrderChange, error) {
+	var changes []domain.OrderChange
+	err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at DESC, id DESC").
+		Find(&changes).Error
+	return changes, err
+}
@@ -3,7 +3,6 @@ package postgres
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -30,6 +29,9 @@ func (r *OrderRepo) FindByID(ctx context.Context, id int) (*domain.Order, error)
 		Preload("Items.Product").
 		Preload("Items.Product.Images", "is_main = true").
 		Preload("CustomDetails").
+		Preload("Company").
+		Preload("Returns").
+		Preload("Returns.Items").
 		First(&order, id).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, domain.ErrOrderNotFound
@@ -44,6 +46,9 @@ func (r *OrderRepo) FindByOrderNumber(ctx context.Context, orderNumber string) (
 		Preload("Items.Product").
 		Preload("Items.Product.Images", "is_main = true").
 		Preload("CustomDetails").
+		Preload("Company").
+		Preload("Returns").
+		Preload("Returns.Items").
 		Where("order_number = ?", orderNumber).
 		First(&order).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -53,14 +58,7 @@ func (r *OrderRepo) FindByOrderNumber(ctx context.Context, orderNumber string) (
 }
 
 func (r *OrderRepo) List(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, int64, error) {
-	query := r.db.WithContext(ctx).Model(&domain.Order{})
-
-	if filter.Status != "" {
-		query = query.Where("status = ?", filter.Status)
-	}
-	if filter.OrderType != "" {
-		query = query.Where("order_type = ?", filter.OrderType)
-	}
+	query := r.db.WithContext(ctx).Model(&domain.Order{}).Scopes(orderFilterScope(filter))
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -79,14 +77,9 @@ func (r *OrderRepo) List(ctx context.Context, filter domain.OrderFilter) ([]doma
 		Preload("Items").
 		Preload("Items.Product").
 		Preload("Items.Product.Images", "is_main = true").
-		Preload("CustomDetails")
-
-	if filter.Status != "" {
-		listQuery = listQuery.Where("status = ?", filter.Status)
-	}
-	if filter.OrderType != "" {
-		listQuery = listQuery.Where("order_type = ?", filter.OrderType)
-	}
+		Preload("CustomDetails").
+		Preload("Company").
+		Scopes(orderFilterScope(filter))
 
 	var orders []domain.Order
 	err := listQuery.
@@ -97,12 +90,40 @@ func (r *OrderRepo) List(ctx context.Context, filter domain.OrderFilter) ([]doma
 	return orders, total, err
 }
 
+// orderFilterScope applies the conditions of an admin order filter.
+func orderFilterScope(filter domain.OrderFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Status != "" {
+			db = db.Where("status = ?", filter.Status)
+		}
+		if filter.OrderType != "" {
+			db = db.Where("order_type = ?", filter.OrderType)
+		}
+		if filter.PaymentMethod != "" {
+			db = db.Where("payment_method = ?", filter.PaymentMethod)
+		}
+		switch filter.CustomerType {
+		case domain.CustomerTypeCompany:
+			db = db.Where("company_id IS NOT NULL")
+		case domain.CustomerTypeIndividual:
+			db = db.Where("company_id IS NULL")
+		}
+		if filter.CompanyID > 0 {
+			db = db.Where("company_id = ?", filter.CompanyID)
+		}
+		return db
+	}
+}
+
 func (r *OrderRepo) ListByUserID(ctx context.Context, userID int) ([]domain.Order, error) {
 	var orders []domain.Order
 	err := r.db.WithContext(ctx).
 		Preload("Items").
 		Preload("Items.Product").
 		Preload("CustomDetails").
+		Preload("Company").
+		Preload("Returns").
+		Preload("Returns.Items").
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Find(&orders).Error
@@ -143,17 +164,28 @@ func (r *OrderRepo) UpdateTracking(ctx context.Context, id int, trackingNumber s
 	return nil
 }
 
-func (r *OrderRepo) NextOrderNumber(ctx context.Context) (string, error) {
-	today := time.Now().Format("20060102")
+func (r *OrderRepo) NextOrderSequence(ctx context.Context, series string, day time.Time) (int, error) {
+	// The upsert takes a row lock, so concurrent checkouts get distinct values.
+	var next int
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO order_number_counters (series, day, last_value)
+		VALUES (?, ?, 1)
+		ON CONFLICT (series, day)
+		DO UPDATE SET last_value = order_number_counters.last_value + 1
+		RETURNING last_value`,
+		series, day.Format("2006-01-02"),
+	).Scan(&next).Error
+	return next, err
+}
 
-	var count int64
+func (r *OrderRepo) FindExpiredUnpaid(ctx context.Context, before time.Time, limit int) ([]domain.Order, error) {
+	var orders []domain.Order
 	err := r.db.WithContext(ctx).
-		Model(&domain.Order{}).
-		Where("order_number LIKE ?", fmt.Sprintf("ORD-%s-%%", today)).
-		Count(&count).Error
-	if err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("ORD-%s-%04d", today, count+1), nil
+		Preload("Items").
+		Where("payment_method = 'card' AND is_paid = false AND paid_amount = 0 AND status = 'new'").
+		Where("payment_expires_at IS NOT NULL AND payment_expires_at < ?", before).
+		Order("payment_expires_at").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
 }
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+type OrderSettingsRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderSettingsRepo(db *gorm.DB) *OrderSettingsRepo {
+	return &OrderSettingsRepo{db: db}
+}
+
+func (r *OrderSettingsRepo) Get(ctx context.Context) (*domain.OrderSettings, error) {
+	var settings domain.OrderSettings
+	err := r.db.WithContext(ctx).First(&settings).Error
+	return &settings, err
+}
+
+func (r *OrderSettingsRepo) Update(ctx context.Context, settings *domain.OrderSettings) error {
+	return r.db.WithContext(ctx).Save(settings).Error
+}
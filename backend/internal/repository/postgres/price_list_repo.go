@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// PriceListRepo implements domain.PriceListRepository using GORM.
+type PriceListRepo struct {
+	db *gorm.DB
+}
+
+// NewPriceListRepo creates a new price list repository.
+func NewPriceListRepo(db *gorm.DB) *PriceListRepo {
+	return &PriceListRepo{db: db}
+}
+
+func (r *PriceListRepo) Create(ctx context.Context, list *domain.PriceList) error {
+	return r.db.WithContext(ctx).Omit("Items").Create(list).Error
+}
+
+func (r *PriceListRepo) FindByID(ctx context.Context, id int) (*domain.PriceList, error) {
+	var list domain.PriceList
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("product_id, min_quantity")
+		}).
+		First(&list, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrPriceListNotFound
+	}
+	return &list, err
+}
+
+func (r *PriceListRepo) FindByName(ctx context.Context, name string) (*domain.PriceList, error) {
+	var list domain.PriceList
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrPriceListNotFound
+	}
+	return &list, err
+}
+
+func (r *PriceListRepo) List(ctx context.Context) ([]domain.PriceList, error) {
+	var lists []domain.PriceList
+	err := r.db.WithContext(ctx).Order("name").Find(&lists).Error
+	return lists, err
+}
+
+func (r *PriceListRepo) Update(ctx context.Context, list *domain.PriceList) error {
+	return r.db.WithContext(ctx).Omit("Items").Save(list).Error
+}
+
+func (r *PriceListRepo) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&domain.PriceList{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPriceListNotFound
+	}
+	return nil
+}
+
+func (r *PriceListRepo) ReplaceItems(ctx context.Context, listID int, items []domain.PriceListItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("price_list_id = ?", listID).Delete(&domain.PriceListItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			items[i].PriceListID = listID
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+func (r *PriceListRepo) ItemsForProducts(ctx context.Context, listID int, productIDs []int) ([]domain.PriceListItem, error) {
+	var items []domain.PriceListItem
+	if len(productIDs) == 0 {
+		return items, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("price_list_id = ? AND product_id IN ?", listID, productIDs).
+		Find(&items).Error
+	return items, err
+}
+
+func (r *PriceListRepo) FindForUser(ctx context.Context, userID int) (*domain.PriceList, error) {
+	var list domain.PriceList
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users u ON u.id = ?", userID).
+		Joins("LEFT JOIN companies c ON c.user_id = u.id").
+		Where("price_lists.is_active AND price_lists.id = COALESCE(u.price_list_id, c.price_list_id)").
+		First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrPriceListNotFound
+	}
+	return &list, err
+}
+
+func (r *PriceListRepo) AssignToUser(ctx context.Context, userID int, listID *int) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("price_list_id", listID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PriceListRepo) AssignToCompany(ctx context.Context, companyID int, listID *int) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Company{}).
+		Where("id = ?", companyID).
+		Update("price_list_id", listID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCompanyNotFound
+	}
+	return nil
+}
+
+func (r *PriceListRepo) TiersForProducts(ctx context.Context, productIDs []int) ([]domain.PriceTier, error) {
+	var tiers []domain.PriceTier
+	if len(productIDs) == 0 {
+		return tiers, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("product_id IN ?", productIDs).
+		Order("product_id, min_quantity").
+		Find(&tiers).Error
+	return tiers, err
+}
+
+func (r *PriceListRepo) ReplaceTiers(ctx context.Context, productID int, tiers []domain.PriceTier) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&domain.PriceTier{}).Error; err != nil {
+			return err
+		}
+		if len(tiers) == 0 {
+			return nil
+		}
+		for i := range tiers {
+			tiers[i].ProductID = productID
+		}
+		return tx.Create(&tiers).Error
+	})
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -68,3 +69,129 @@ func (r *ProductImageRepo) CountByProductID(ctx context.Context, productID int)
 		Count(&count).Error
 	return count, err
 }
+
+func (r *ProductImageRepo) UpdateDisplayOrders(ctx context.Context, productID int, imageIDs []int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range imageIDs {
+			res := tx.Model(&domain.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("display_order", i)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return domain.ErrImageNotFound
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ProductImageRepo) ClaimPending(ctx context.Context) (*domain.ProductImage, error) {
+	var images []domain.ProductImage
+	// SKIP LOCKED lets several workers (and API instances) share the queue.
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE product_images SET
+			status = ?,
+			attempts = attempts + 1,
+			processing_started_at = NOW()
+		WHERE id = (
+			SELECT id FROM product_images
+			WHERE status = ?
+			ORDER BY id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`, domain.ImageStatusProcessing, domain.ImageStatusPending).
+		Scan(&images).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, nil
+	}
+	return &images[0], nil
+}
+
+func (r *ProductImageRepo) SaveProcessing(ctx context.Context, image *domain.ProductImage) error {
+	return r.db.WithContext(ctx).Model(&domain.ProductImage{}).
+		Where("id = ?", image.ID).
+		Updates(map[string]interface{}{
+			"url":              image.URL,
+			"url_webp":         image.URLWebP,
+			"url_large":        image.URLLarge,
+			"url_medium":       image.URLMedium,
+			"url_thumbnail":    image.URLThumbnail,
+			"variants":         image.Variants,
+			"source_key":       image.SourceKey,
+			"status":           image.Status,
+			"error":            image.Error,
+			"preset_signature": image.PresetSignature,
+			"processed_at":     image.ProcessedAt,
+		}).Error
+}
+
+func (r *ProductImageRepo) Requeue(ctx context.Context, id int) error {
+	res := r.db.WithContext(ctx).Model(&domain.ProductImage{}).
+		Where("id = ? AND status <> ?", id, domain.ImageStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":   domain.ImageStatusPending,
+			"error":    nil,
+			"attempts": 0,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// Either missing or already being processed; the latter is not an error.
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ProductImageRepo) RequeueStale(ctx context.Context, signature string) (int64, error) {
+	q := r.db.WithContext(ctx).Model(&domain.ProductImage{}).
+		Where("status IN ?", []string{domain.ImageStatusReady, domain.ImageStatusFailed})
+	if signature != "" {
+		q = q.Where("preset_signature IS DISTINCT FROM ?", signature)
+	}
+	res := q.Updates(map[string]interface{}{
+		"status":   domain.ImageStatusPending,
+		"error":    nil,
+		"attempts": 0,
+	})
+	return res.RowsAffected, res.Error
+}
+
+func (r *ProductImageRepo) RequeueStuck(ctx context.Context, startedBefore time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&domain.ProductImage{}).
+		Where("status = ? AND processing_started_at < ?", domain.ImageStatusProcessing, startedBefore).
+		Update("status", domain.ImageStatusPending)
+	return res.RowsAffected, res.Error
+}
+
+func (r *ProductImageRepo) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&domain.ProductImage{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{
+		domain.ImageStatusPending:    0,
+		domain.ImageStatusProcessing: 0,
+		domain.ImageStatusReady:      0,
+		domain.ImageStatusFailed:     0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
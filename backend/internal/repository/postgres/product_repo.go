@@ -54,20 +54,24 @@ func (r *ProductRepo) FindBySlug(ctx context.Context, slug string) (*domain.Prod
 
 func (r *ProductRepo) List(ctx context.Context, filter domain.ProductFilter) (*domain.ProductListResult, error) {
 	query := r.db.WithContext(ctx).Model(&domain.Product{})
-	if !filter.IncludeInactive {
+	switch {
+	case filter.OnlyInactive:
+		query = query.Where("is_active = false")
+	case !filter.IncludeInactive:
 		query = query.Where("is_active = true")
 	}
 
 	// Filter by category (including subcategories)
 	if filter.CategorySlug != "" {
-		var categoryIDs []int
-		// Find category by slug, then collect all descendant IDs
-		var cat domain.Category
-		if err := r.db.Where("slug = ?", filter.CategorySlug).First(&cat).Error; err == nil {
-			categoryIDs = append(categoryIDs, cat.ID)
-			categoryIDs = append(categoryIDs, r.collectChildIDs(cat.ID)...)
-			query = query.Where("category_id IN ?", categoryIDs)
-		}
+		query = query.Where(`category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id, 0 AS depth FROM categories WHERE slug = ?
+				UNION ALL
+				SELECT c.id, t.depth + 1 FROM categories c JOIN tree t ON c.parent_id = t.id
+				WHERE t.depth < ?
+			)
+			SELECT id FROM tree
+		)`, filter.CategorySlug, maxCategoryDepth)
 	}
 
 	// Filter by price range
@@ -112,6 +116,8 @@ func (r *ProductRepo) List(ctx context.Context, filter domain.ProductFilter) (*d
 		query = query.Order("created_at DESC")
 	case "popular":
 		query = query.Order("sales_count DESC")
+	case "updated":
+		query = query.Order("updated_at DESC")
 	default:
 		if filter.Search != "" {
 			words := strings.Fields(filter.Search)
@@ -162,27 +168,25 @@ func (r *ProductRepo) List(ctx context.Context, filter domain.ProductFilter) (*d
 	}, nil
 }
 
-// collectChildIDs recursively collects all child category IDs.
-func (r *ProductRepo) collectChildIDs(parentID int) []int {
-	var children []domain.Category
-	r.db.Where("parent_id = ?", parentID).Find(&children)
-
-	var ids []int
-	for _, child := range children {
-		ids = append(ids, child.ID)
-		ids = append(ids, r.collectChildIDs(child.ID)...)
-	}
-	return ids
-}
-
 func (r *ProductRepo) FindByIDs(ctx context.Context, ids []int) ([]domain.Product, error) {
 	var products []domain.Product
 	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&products).Error
 	return products, err
 }
 
+func (r *ProductRepo) FindBySKUs(ctx context.Context, skus []string) ([]domain.Product, error) {
+	lower := make([]string, len(skus))
+	for i, sku := range skus {
+		lower[i] = strings.ToLower(sku)
+	}
+	var products []domain.Product
+	err := r.db.WithContext(ctx).Where("LOWER(sku) IN ?", lower).Find(&products).Error
+	return products, err
+}
+
 func (r *ProductRepo) Update(ctx context.Context, product *domain.Product) error {
-	return r.db.WithContext(ctx).Omit("Category", "Images").Save(product).Error
+	// Stock only changes through the stock ledger.
+	return r.db.WithContext(ctx).Omit("Category", "Images", "StockQuantity").Save(product).Error
 }
 
 func (r *ProductRepo) SoftDelete(ctx context.Context, id int) error {
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// ProductRevisionRepo implements domain.ProductRevisionRepository using GORM.
+type ProductRevisionRepo struct {
+	db *gorm.DB
+}
+
+// NewProductRevisionRepo creates a new product revision repository.
+func NewProductRevisionRepo(db *gorm.DB) *ProductRevisionRepo {
+	return &ProductRevisionRepo{db: db}
+}
+
+func (r *ProductRevisionRepo) Create(ctx context.Context, revision *domain.ProductRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the product row serialises numbering of concurrent edits.
+		var locked int
+		if err := tx.Model(&domain.Product{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", revision.ProductID).
+			Pluck("id", &locked).Error; err != nil {
+			return err
+		}
+
+		var last int
+		if err := tx.Model(&domain.ProductRevision{}).
+			Where("product_id = ?", revision.ProductID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&last).Error; err != nil {
+			return err
+		}
+		revision.Revision = last + 1
+		return tx.Create(revision).Error
+	})
+}
+
+func (r *ProductRevisionRepo) ListByProductID(ctx context.Context, productID, page, limit int) ([]domain.ProductRevision, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.ProductRevision{}).Where("product_id = ?", productID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []domain.ProductRevision
+	err := query.
+		Order("revision DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&revisions).Error
+	return revisions, total, err
+}
+
+func (r *ProductRevisionRepo) FindByRevision(ctx context.Context, productID, revision int) (*domain.ProductRevision, error) {
+	var rev domain.ProductRevision
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND revision = ?", productID, revision).
+		First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrProductRevisionNotFound
+	}
+	return &rev, err
+}
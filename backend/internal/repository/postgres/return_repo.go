@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// ReturnRepo implements domain.ReturnRepository using GORM.
+type ReturnRepo struct {
+	db *gorm.DB
+}
+
+// NewReturnRepo creates a new return request repository.
+func NewReturnRepo(db *gorm.DB) *ReturnRepo {
+	return &ReturnRepo{db: db}
+}
+
+func (r *ReturnRepo) Create(ctx context.Context, req *domain.ReturnRequest) error {
+	return r.db.WithContext(ctx).Omit("Order").Create(req).Error
+}
+
+func (r *ReturnRepo) FindByID(ctx context.Context, id int) (*domain.ReturnRequest, error) {
+	var req domain.ReturnRequest
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.OrderItem").
+		Preload("Items.OrderItem.Product").
+		Preload("Order").
+		First(&req, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrReturnNotFound
+	}
+	return &req, err
+}
+
+func (r *ReturnRepo) ListByUserID(ctx context.Context, userID int) ([]domain.ReturnRequest, error) {
+	var reqs []domain.ReturnRequest
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.OrderItem").
+		Preload("Items.OrderItem.Product").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&reqs).Error
+	return reqs, err
+}
+
+func (r *ReturnRepo) List(ctx context.Context, filter domain.ReturnFilter) ([]domain.ReturnRequest, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.ReturnRequest{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	var reqs []domain.ReturnRequest
+	err := query.
+		Preload("Items").
+		Preload("Items.OrderItem").
+		Preload("Items.OrderItem.Product").
+		Preload("Order").
+		Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&reqs).Error
+	return reqs, total, err
+}
+
+func (r *ReturnRepo) Update(ctx context.Context, req *domain.ReturnRequest) error {
+	return r.db.WithContext(ctx).Omit("Items", "Order").Save(req).Error
+}
+
+func (r *ReturnRepo) ReturnedQuantities(ctx context.Context, orderID int) (map[int]int, error) {
+	var rows []struct {
+		OrderItemID int
+		Quantity    int
+	}
+	err := r.db.WithContext(ctx).
+		Table("return_items ri").
+		Select("ri.order_item_id, SUM(ri.quantity) AS quantity").
+		Joins("JOIN return_requests rr ON rr.id = ri.return_id").
+		Where("rr.order_id = ? AND rr.status <> ?", orderID, domain.ReturnStatusRejected).
+		Group("ri.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[int]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
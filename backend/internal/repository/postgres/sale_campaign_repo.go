@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+type SaleCampaignRepo struct {
+	db *gorm.DB
+}
+
+func NewSaleCampaignRepo(db *gorm.DB) *SaleCampaignRepo {
+	return &SaleCampaignRepo{db: db}
+}
+
+func (r *SaleCampaignRepo) Create(ctx context.Context, campaign *domain.SaleCampaign) error {
+	return r.db.WithContext(ctx).Omit("Items").Create(campaign).Error
+}
+
+func (r *SaleCampaignRepo) FindByID(ctx context.Context, id int) (*domain.SaleCampaign, error) {
+	var campaign domain.SaleCampaign
+	err := r.db.WithContext(ctx).
+		Preload("Items").
+		First(&campaign, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrSaleCampaignNotFound
+	}
+	return &campaign, err
+}
+
+func (r *SaleCampaignRepo) List(ctx context.Context, status string) ([]domain.SaleCampaign, error) {
+	var campaigns []domain.SaleCampaign
+	q := r.db.WithContext(ctx).Order("starts_at DESC")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *SaleCampaignRepo) Update(ctx context.Context, campaign *domain.SaleCampaign) error {
+	return r.db.WithContext(ctx).Omit("Items").Save(campaign).Error
+}
+
+func (r *SaleCampaignRepo) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&domain.SaleCampaign{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSaleCampaignNotFound
+	}
+	return nil
+}
+
+func (r *SaleCampaignRepo) FindDueToStart(ctx context.Context, now time.Time) ([]domain.SaleCampaign, error) {
+	var campaigns []domain.SaleCampaign
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND starts_at <= ?", domain.SaleCampaignScheduled, now).
+		Order("starts_at ASC").
+		Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *SaleCampaignRepo) FindDueToEnd(ctx context.Context, now time.Time) ([]domain.SaleCampaign, error) {
+	var campaigns []domain.SaleCampaign
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND ends_at IS NOT NULL AND ends_at <= ?", domain.SaleCampaignActive, now).
+		Order("ends_at ASC").
+		Find(&campaigns).Error
+	return campaigns, err
+}
+
+type PriceHistoryRepo struct {
+	db *gorm.DB
+}
+
+func NewPriceHistoryRepo(db *gorm.DB) *PriceHistoryRepo {
+	return &PriceHistoryRepo{db: db}
+}
+
+func (r *PriceHistoryRepo) Create(ctx context.Context, entry *domain.PriceHistory) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *PriceHistoryRepo) ListByProductID(ctx context.Context, productID int, limit int) ([]domain.PriceHistory, error) {
+	var entries []domain.PriceHistory
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+type SlugRedirectRepo struct {
+	db *gorm.DB
+}
+
+func NewSlugRedirectRepo(db *gorm.DB) *SlugRedirectRepo {
+	return &SlugRedirectRepo{db: db}
+}
+
+func (r *SlugRedirectRepo) Save(ctx context.Context, entityType string, entityID int, oldSlug string) error {
+	redirect := &domain.SlugRedirect{
+		EntityType: entityType,
+		EntityID:   entityID,
+		OldSlug:    oldSlug,
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "entity_type"}, {Name: "old_slug"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"entity_id":  entityID,
+				"created_at": time.Now(),
+			}),
+		}).
+		Create(redirect).Error
+}
+
+func (r *SlugRedirectRepo) FindByOldSlug(ctx context.Context, entityType, slug string) (*domain.SlugRedirect, error) {
+	var redirect domain.SlugRedirect
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND old_slug = ?", entityType, slug).
+		First(&redirect).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrSlugRedirectNotFound
+	}
+	return &redirect, err
+}
+
+func (r *SlugRedirectRepo) DeleteBySlug(ctx context.Context, entityType, slug string) error {
+	return r.db.WithContext(ctx).
+		Where("entity_type = ? AND old_slug = ?", entityType, slug).
+		Delete(&domain.SlugRedirect{}).Error
+}
+
+func (r *SlugRedirectRepo) RecordHit(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Model(&domain.SlugRedirect{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"hits":        gorm.Expr("hits + 1"),
+			"last_hit_at": time.Now(),
+		}).Error
+}
+
+func (r *SlugRedirectRepo) List(ctx context.Context, filter domain.SlugRedirectFilter) ([]domain.SlugRedirect, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.SlugRedirect{})
+
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.Search != "" {
+		query = query.Where("old_slug ILIKE ?", "%"+filter.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+
+	var redirects []domain.SlugRedirect
+	err := query.
+		Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&redirects).Error
+
+	return redirects, total, err
+}
+
+func (r *SlugRedirectRepo) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Delete(&domain.SlugRedirect{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrSlugRedirectNotFound
+	}
+	return nil
+}
+
+func (r *SlugRedirectRepo) DeleteUnusedSince(ctx context.Context, since time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("COALESCE(last_hit_at, created_at) < ?", since).
+		Delete(&domain.SlugRedirect{})
+	return result.RowsAffected, result.Error
+}
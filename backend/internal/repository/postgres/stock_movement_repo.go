@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// StockMovementRepo implements domain.StockMovementRepository using GORM.
+type StockMovementRepo struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepo creates a new stock movement repository.
+func NewStockMovementRepo(db *gorm.DB) *StockMovementRepo {
+	return &StockMovementRepo{db: db}
+}
+
+func (r *StockMovementRepo) List(ctx context.Context, filter domain.StockMovementFilter) ([]domain.StockMovement, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&domain.StockMovement{}).
+		Where("product_id = ?", filter.ProductID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+
+	var movements []domain.StockMovement
+	err := query.
+		Order("id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&movements).Error
+	return movements, total, err
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// TranslationRepo implements domain.TranslationRepository using GORM.
+type TranslationRepo struct {
+	db *gorm.DB
+}
+
+// NewTranslationRepo creates a new translation repository.
+func NewTranslationRepo(db *gorm.DB) *TranslationRepo {
+	return &TranslationRepo{db: db}
+}
+
+func (r *TranslationRepo) Upsert(ctx context.Context, t *domain.Translation) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "locale"}},
+			DoUpdates: clause.AssignmentColumns([]string{"fields", "data", "updated_at"}),
+		}).
+		Create(t).Error
+}
+
+func (r *TranslationRepo) FindByEntity(ctx context.Context, entityType string, entityID int) ([]domain.Translation, error) {
+	var translations []domain.Translation
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("locale").
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r *TranslationRepo) Find(ctx context.Context, entityType string, entityID int, locale string) (*domain.Translation, error) {
+	var t domain.Translation
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).
+		First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrTranslationNotFound
+	}
+	return &t, err
+}
+
+func (r *TranslationRepo) FindForEntities(ctx context.Context, entityType string, entityIDs []int, locale string) ([]domain.Translation, error) {
+	if len(entityIDs) == 0 {
+		return nil, nil
+	}
+	var translations []domain.Translation
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id IN ? AND locale = ?", entityType, entityIDs, locale).
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r *TranslationRepo) FindAllForLocale(ctx context.Context, entityType, locale string) ([]domain.Translation, error) {
+	var translations []domain.Translation
+	err := r.db.WithContext(ctx).
+		Where("entity_type = ? AND locale = ?", entityType, locale).
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r *TranslationRepo) Delete(ctx context.Context, entityType string, entityID int, locale string) error {
+	res := r.db.WithContext(ctx).
+		Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, locale).
+		Delete(&domain.Translation{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrTranslationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+type WishlistRepo struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepo(db *gorm.DB) *WishlistRepo {
+	return &WishlistRepo{db: db}
+}
+
+func (r *WishlistRepo) ListByUserID(ctx context.Context, userID int) ([]domain.WishlistItem, error) {
+	var items []domain.WishlistItem
+	err := r.db.WithContext(ctx).
+		Preload("Product").
+		Preload("Product.Images", "is_main = true").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *WishlistRepo) Add(ctx context.Context, item *domain.WishlistItem) error {
+	return r.db.WithContext(ctx).
+		Omit("Product").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "product_id"}},
+			DoNothing: true,
+		}).
+		Create(item).Error
+}
+
+func (r *WishlistRepo) Remove(ctx context.Context, userID, productID int) error {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&domain.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWishlistItemNotFound
+	}
+	return nil
+}
+
+type StockSubscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewStockSubscriptionRepo(db *gorm.DB) *StockSubscriptionRepo {
+	return &StockSubscriptionRepo{db: db}
+}
+
+func (r *StockSubscriptionRepo) Create(ctx context.Context, sub *domain.StockSubscription) (bool, error) {
+	// Conflicts are caught by the partial unique indexes (product + email / telegram_id).
+	res := r.db.WithContext(ctx).
+		Omit("Product").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(sub)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+
+	query := r.db.WithContext(ctx).Where("product_id = ? AND channel = ?", sub.ProductID, sub.Channel)
+	switch {
+	case sub.Email != nil:
+		query = query.Where("LOWER(email) = LOWER(?)", *sub.Email)
+	case sub.TelegramID != nil:
+		query = query.Where("telegram_id = ?", *sub.TelegramID)
+	}
+	if err := query.First(sub).Error; err != nil {
+		return false, fmt.Errorf("load existing subscription: %w", err)
+	}
+	return false, nil
+}
+
+func (r *StockSubscriptionRepo) ListByUserID(ctx context.Context, userID int) ([]domain.StockSubscription, error) {
+	var subs []domain.StockSubscription
+	err := r.db.WithContext(ctx).
+		Preload("Product").
+		Preload("Product.Images", "is_main = true").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&subs).Error
+	return subs, err
+}
+
+func (r *StockSubscriptionRepo) DeleteForUser(ctx context.Context, id, userID int) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&domain.StockSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrStockSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *StockSubscriptionRepo) ListByProductID(ctx context.Context, productID, afterID, limit int) ([]domain.StockSubscription, error) {
+	var subs []domain.StockSubscription
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND id > ?", productID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&subs).Error
+	return subs, err
+}
+
+func (r *StockSubscriptionRepo) DeleteByIDs(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&domain.StockSubscription{}).Error
+}
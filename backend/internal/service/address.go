@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// AddressInput represents an address book entry sent by the customer.
+// Courier addresses need a city and an address; pickup point entries need
+// pickupPointId.
+type AddressInput struct {
+	Type          string  `json:"type" binding:"required,oneof=courier pickup_point"`
+	Label         *string `json:"label" binding:"omitempty,max=100"`
+	RecipientName *string `json:"recipientName" binding:"omitempty,max=255"`
+	Phone         *string `json:"phone" binding:"omitempty,max=50"`
+	City          *string `json:"city" binding:"omitempty,max=255"`
+	Address       *string `json:"address"`
+	PickupPointID *int    `json:"pickupPointId"`
+	IsDefault     bool    `json:"isDefault"`
+}
+
+// AddressService manages customers' address books.
+type AddressService struct {
+	repo            domain.AddressRepository
+	pickupPointRepo domain.PickupPointRepository
+	log             *zap.Logger
+}
+
+// NewAddressService creates a new address book service.
+func NewAddressService(repo domain.AddressRepository, pickupPointRepo domain.PickupPointRepository, log *zap.Logger) *AddressService {
+	return &AddressService{repo: repo, pickupPointRepo: pickupPointRepo, log: log}
+}
+
+// List returns the user's addresses, defaults first.
+func (s *AddressService) List(ctx context.Context, userID int) ([]domain.UserAddress, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// Get returns one of the user's addresses.
+func (s *AddressService) Get(ctx context.Context, userID, id int) (*domain.UserAddress, error) {
+	return s.repo.FindByID(ctx, id, userID)
+}
+
+// Create adds an address to the user's address book.
+func (s *AddressService) Create(ctx context.Context, userID int, input AddressInput) (*domain.UserAddress, error) {
+	address := &domain.UserAddress{UserID: userID}
+	if err := s.apply(ctx, address, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, address); err != nil {
+		return nil, fmt.Errorf("create address: %w", err)
+	}
+
+	s.log.Info("address created", zap.Int("userId", userID), zap.Int("id", address.ID))
+	return s.repo.FindByID(ctx, address.ID, userID)
+}
+
+// Update replaces one of the user's addresses.
+func (s *AddressService) Update(ctx context.Context, userID, id int, input AddressInput) (*domain.UserAddress, error) {
+	address, err := s.repo.FindByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, address, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, address); err != nil {
+		return nil, fmt.Errorf("update address: %w", err)
+	}
+	return s.repo.FindByID(ctx, id, userID)
+}
+
+// Delete removes one of the user's addresses.
+func (s *AddressService) Delete(ctx context.Context, userID, id int) error {
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *AddressService) apply(ctx context.Context, a *domain.UserAddress, input AddressInput) error {
+	a.Type = input.Type
+	a.Label = trimmedOrNil(input.Label)
+	a.RecipientName = trimmedOrNil(input.RecipientName)
+	a.Phone = trimmedOrNil(input.Phone)
+	a.City = trimmedOrNil(input.City)
+	a.Address = nil
+	a.PickupPointID = nil
+	a.PickupPoint = nil
+	a.IsDefault = input.IsDefault
+
+	switch input.Type {
+	case domain.AddressTypeCourier:
+		a.Address = trimmedOrNil(input.Address)
+		if a.City == nil || a.Address == nil {
+			return domain.ErrAddressIncomplete
+		}
+	case domain.AddressTypePickupPoint:
+		if input.PickupPointID == nil {
+			return domain.ErrAddressIncomplete
+		}
+		point, err := s.pickupPointRepo.FindByID(ctx, *input.PickupPointID)
+		if err != nil {
+			return err
+		}
+		if !point.IsActive {
+			return domain.ErrPickupPointNotFound
+		}
+		a.PickupPointID = &point.ID
+		a.City = &point.City
+	}
+	return nil
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -27,6 +27,7 @@ type AuthService struct {
 	tokens         *AuthTokenService
 	botToken       string
 	loyaltyService *LoyaltyService
+	cartService    *CartService
 	log            *zap.Logger
 }
 
@@ -35,6 +36,22 @@ func (s *AuthService) SetLoyaltyService(ls *LoyaltyService) {
 	s.loyaltyService = ls
 }
 
+// SetCartService enables merging the guest cart into the account on login.
+func (s *AuthService) SetCartService(cs *CartService) {
+	s.cartService = cs
+}
+
+// mergeGuestCart moves the visitor's guest cart into the account. Failures
+// do not block the login.
+func (s *AuthService) mergeGuestCart(ctx context.Context, userID int, cartToken string) {
+	if s.cartService == nil || cartToken == "" {
+		return
+	}
+	if err := s.cartService.MergeGuestCart(ctx, userID, cartToken); err != nil {
+		s.log.Warn("failed to merge guest cart", zap.Int("userID", userID), zap.Error(err))
+	}
+}
+
 // NewAuthService creates a new authentication service.
 func NewAuthService(userRepo domain.UserRepository, tokens *AuthTokenService, botToken string, log *zap.Logger) *AuthService {
 	return &AuthService{
@@ -49,6 +66,8 @@ func NewAuthService(userRepo domain.UserRepository, tokens *AuthTokenService, bo
 type LoginInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	// CartToken is the guest cart to merge, taken from the X-Cart-Token header.
+	CartToken string `json:"-"`
 }
 
 // TokenResponse is the response with access and refresh tokens.
@@ -80,6 +99,7 @@ func (s *AuthService) Login(ctx context.Context, input LoginInput) (*TokenRespon
 		return nil, fmt.Errorf("generate tokens: %w", err)
 	}
 
+	s.mergeGuestCart(ctx, user.ID, input.CartToken)
 	s.log.Info("user logged in", zap.Int("userID", user.ID), zap.String("role", user.Role))
 
 	return &TokenResponse{
@@ -129,8 +149,9 @@ var (
 	ErrInitDataExpired = errors.New("initData expired")
 )
 
-// LoginTelegram validates Telegram initData and returns tokens.
-func (s *AuthService) LoginTelegram(ctx context.Context, initData string) (*TelegramLoginResponse, error) {
+// LoginTelegram validates Telegram initData and returns tokens. A non-empty
+// cartToken merges that guest cart into the account.
+func (s *AuthService) LoginTelegram(ctx context.Context, initData, cartToken string) (*TelegramLoginResponse, error) {
 	if s.botToken == "" {
 		return nil, fmt.Errorf("telegram bot token not configured")
 	}
@@ -208,6 +229,7 @@ func (s *AuthService) LoginTelegram(ctx context.Context, initData string) (*Tele
 		return nil, fmt.Errorf("generate tokens: %w", err)
 	}
 
+	s.mergeGuestCart(ctx, user.ID, cartToken)
 	s.log.Info("telegram user logged in", zap.Int("userID", user.ID), zap.Int64("telegramID", tgUser.ID))
 
 	resp := &TelegramLoginResponse{
@@ -230,6 +252,7 @@ type RegisterInput struct {
 	Email        string `json:"email" binding:"required,email"`
 	Password     string `json:"password" binding:"required,min=8"`
 	ReferralCode string `json:"referralCode"`
+	CartToken    string `json:"-"`
 }
 
 // RegisterResponse is returned after successful registration.
@@ -300,6 +323,8 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput) (*Regis
 		return nil, fmt.Errorf("generate tokens: %w", err)
 	}
 
+	s.mergeGuestCart(ctx, user.ID, input.CartToken)
+
 	resp := &RegisterResponse{
 		AccessToken:  pair.AccessToken,
 		RefreshToken: pair.RefreshToken,
@@ -321,6 +346,7 @@ type TelegramWidgetInput struct {
 	AuthDate  int64  `json:"authDate" binding:"required"`
 	Hash      string `json:"hash" binding:"required"`
 	Email     string `json:"email"`
+	CartToken string `json:"-"`
 }
 
 // LoginTelegramWidget validates Telegram Login Widget data and returns tokens.
@@ -384,6 +410,7 @@ func (s *AuthService) LoginTelegramWidget(ctx context.Context, input TelegramWid
 		return nil, fmt.Errorf("generate tokens: %w", err)
 	}
 
+	s.mergeGuestCart(ctx, user.ID, input.CartToken)
 	s.log.Info("telegram widget user logged in", zap.Int("userID", user.ID), zap.Int64("telegramID", input.ID))
 
 	resp := &TelegramLoginResponse{
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 
 	"go.uber.org/zap"
 
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/config"
 	"github.com/brown/3d-print-shop/internal/domain"
 )
 
@@ -22,6 +25,9 @@ type UpdateCartItemInput struct {
 type CartService struct {
 	cartRepo    domain.CartRepository
 	productRepo domain.ProductRepository
+	guestStore  *cache.Store
+	guestCfg    config.CartConfig
+	prices      *PriceListService
 	log         *zap.Logger
 }
 
@@ -33,12 +39,17 @@ func NewCartService(cartRepo domain.CartRepository, productRepo domain.ProductRe
 	}
 }
 
+// SetPriceListService sets the service resolving quantity price breaks and customer price lists.
+func (s *CartService) SetPriceListService(ps *PriceListService) {
+	s.prices = ps
+}
+
 func (s *CartService) GetCart(ctx context.Context, userID int) (*domain.Cart, error) {
 	items, err := s.cartRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("get cart items: %w", err)
 	}
-	return s.buildCart(items), nil
+	return s.buildCart(ctx, &userID, items)
 }
 
 func (s *CartService) AddItem(ctx context.Context, userID int, input AddToCartInput) (*domain.Cart, error) {
@@ -60,7 +71,7 @@ func (s *CartService) AddItem(ctx context.Context, userID int, input AddToCartIn
 	if existing != nil {
 		// Update quantity
 		newQty := existing.Quantity + input.Quantity
-		if product.StockQuantity < newQty {
+		if _, _, ok := product.StockSplit(newQty); !ok {
 			return nil, domain.ErrInsufficientStock
 		}
 		if err := s.cartRepo.UpdateQuantity(ctx, existing.ID, userID, newQty); err != nil {
@@ -68,7 +79,7 @@ func (s *CartService) AddItem(ctx context.Context, userID int, input AddToCartIn
 		}
 	} else {
 		// Add new item
-		if product.StockQuantity < input.Quantity {
+		if _, _, ok := product.StockSplit(input.Quantity); !ok {
 			return nil, domain.ErrInsufficientStock
 		}
 		item := &domain.CartItem{
@@ -95,7 +106,7 @@ func (s *CartService) UpdateItem(ctx context.Context, userID int, itemID int, in
 		return nil, err
 	}
 
-	if product.StockQuantity < input.Quantity {
+	if _, _, ok := product.StockSplit(input.Quantity); !ok {
 		return nil, domain.ErrInsufficientStock
 	}
 
@@ -120,15 +131,29 @@ func (s *CartService) Clear(ctx context.Context, userID int) error {
 	return nil
 }
 
-func (s *CartService) buildCart(items []domain.CartItem) *domain.Cart {
+// buildCart prices the items the way checkout will: at the unit price the
+// customer (nil for guests) pays for the quantity.
+func (s *CartService) buildCart(ctx context.Context, userID *int, items []domain.CartItem) (*domain.Cart, error) {
+	productIDs := make([]int, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	prices, err := s.prices.Prepare(ctx, userID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	cart := &domain.Cart{
 		Items: items,
 	}
-	for _, item := range items {
+	for i := range items {
+		item := &items[i]
+		item.UnitPrice = prices.UnitPrice(&item.Product, item.Quantity)
+		item.TotalPrice = math.Round(item.UnitPrice*float64(item.Quantity)*100) / 100
 		cart.TotalItems += item.Quantity
-		cart.TotalPrice += item.Product.Price * float64(item.Quantity)
+		cart.TotalPrice += item.TotalPrice
 	}
 	// Round to 2 decimal places
-	cart.TotalPrice = float64(int(cart.TotalPrice*100)) / 100
-	return cart
+	cart.TotalPrice = math.Round(cart.TotalPrice*100) / 100
+	return cart, nil
 }
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// cartReminderBatchSize limits how many carts one campaign handles per check.
+const cartReminderBatchSize = 100
+
+// CartReminderCampaignInput represents the input for creating or replacing a campaign.
+// Leave the promo discount empty for reminders without a promo code.
+type CartReminderCampaignInput struct {
+	Name               string   `json:"name" binding:"required,min=1,max=255"`
+	IsActive           *bool    `json:"isActive"`
+	DelayHours         int      `json:"delayHours" binding:"required,min=1,max=720"`
+	MinCartTotal       float64  `json:"minCartTotal" binding:"min=0"`
+	PromoDiscountType  *string  `json:"promoDiscountType" binding:"omitempty,oneof=percent fixed"`
+	PromoDiscountValue *float64 `json:"promoDiscountValue" binding:"omitempty,gt=0"`
+	PromoValidHours    int      `json:"promoValidHours" binding:"omitempty,min=1,max=720"`
+}
+
+// CartReminderService finds abandoned carts and reminds their owners via
+// Telegram or email, optionally with a single-use promo code.
+type CartReminderService struct {
+	repo         domain.CartReminderRepository
+	cartRepo     domain.CartRepository
+	userRepo     domain.UserRepository
+	promoRepo    domain.PromoRepository
+	emailService *EmailService
+	notifier     domain.CartReminderNotifier
+	cfg          config.CartReminderConfig
+	appURL       string // storefront, where clicks are redirected
+	apiURL       string // public API base, for click and unsubscribe links
+	log          *zap.Logger
+}
+
+// NewCartReminderService creates a new cart reminder service.
+func NewCartReminderService(
+	repo domain.CartReminderRepository,
+	cartRepo domain.CartRepository,
+	userRepo domain.UserRepository,
+	promoRepo domain.PromoRepository,
+	cfg config.CartReminderConfig,
+	appURL string,
+	apiURL string,
+	log *zap.Logger,
+) *CartReminderService {
+	return &CartReminderService{
+		repo:      repo,
+		cartRepo:  cartRepo,
+		userRepo:  userRepo,
+		promoRepo: promoRepo,
+		cfg:       cfg,
+		appURL:    strings.TrimRight(appURL, "/"),
+		apiURL:    strings.TrimRight(apiURL, "/"),
+		log:       log,
+	}
+}
+
+// SetEmailService enables email reminders.
+func (s *CartReminderService) SetEmailService(es *EmailService) {
+	s.emailService = es
+}
+
+// SetNotifier enables Telegram reminders.
+func (s *CartReminderService) SetNotifier(n domain.CartReminderNotifier) {
+	s.notifier = n
+}
+
+// ListCampaigns returns all campaigns, shortest delay first.
+func (s *CartReminderService) ListCampaigns(ctx context.Context) ([]domain.CartReminderCampaign, error) {
+	return s.repo.ListCampaigns(ctx, false)
+}
+
+// CreateCampaign creates a reminder campaign.
+func (s *CartReminderService) CreateCampaign(ctx context.Context, input CartReminderCampaignInput) (*domain.CartReminderCampaign, error) {
+	campaign := &domain.CartReminderCampaign{IsActive: true}
+	if err := applyCartReminderCampaignInput(campaign, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateCampaign(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("create cart reminder campaign: %w", err)
+	}
+
+	s.log.Info("cart reminder campaign created", zap.Int("id", campaign.ID), zap.Int("delayHours", campaign.DelayHours))
+	return campaign, nil
+}
+
+// UpdateCampaign replaces the settings of a campaign.
+func (s *CartReminderService) UpdateCampaign(ctx context.Context, id int, input CartReminderCampaignInput) (*domain.CartReminderCampaign, error) {
+	campaign, err := s.repo.FindCampaignByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCartReminderCampaignInput(campaign, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCampaign(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("update cart reminder campaign: %w", err)
+	}
+
+	s.log.Info("cart reminder campaign updated", zap.Int("id", campaign.ID))
+	return campaign, nil
+}
+
+// DeleteCampaign deletes a campaign. Sent reminders are kept without it.
+func (s *CartReminderService) DeleteCampaign(ctx context.Context, id int) error {
+	if err := s.repo.DeleteCampaign(ctx, id); err != nil {
+		return err
+	}
+	s.log.Info("cart reminder campaign deleted", zap.Int("id", id))
+	return nil
+}
+
+// Stats returns sent, clicked and converted counts per campaign.
+func (s *CartReminderService) Stats(ctx context.Context) ([]domain.CartReminderStats, error) {
+	return s.repo.Stats(ctx)
+}
+
+func applyCartReminderCampaignInput(c *domain.CartReminderCampaign, input CartReminderCampaignInput) error {
+	if (input.PromoDiscountType == nil) != (input.PromoDiscountValue == nil) {
+		return domain.ErrCartReminderInvalidPromo
+	}
+	if input.PromoDiscountType != nil && *input.PromoDiscountType == "percent" && *input.PromoDiscountValue >= 100 {
+		return domain.ErrCartReminderInvalidPromo
+	}
+
+	c.Name = input.Name
+	if input.IsActive != nil {
+		c.IsActive = *input.IsActive
+	}
+	c.DelayHours = input.DelayHours
+	c.MinCartTotal = input.MinCartTotal
+	c.PromoDiscountType = input.PromoDiscountType
+	c.PromoDiscountValue = input.PromoDiscountValue
+	c.PromoValidHours = input.PromoValidHours
+	if c.PromoValidHours == 0 {
+		c.PromoValidHours = 72
+	}
+	return nil
+}
+
+// StartScheduler checks for abandoned carts periodically.
+// Blocks until ctx is cancelled.
+func (s *CartReminderService) StartScheduler(ctx context.Context) {
+	s.log.Info("starting cart reminder scheduler", zap.Duration("interval", s.cfg.Interval))
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("stopping cart reminder scheduler")
+			return
+		case <-ticker.C:
+			if err := s.ProcessDue(ctx); err != nil {
+				s.log.Error("cart reminder check failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ProcessDue sends the reminders of all active campaigns that are due.
+func (s *CartReminderService) ProcessDue(ctx context.Context) error {
+	campaigns, err := s.repo.ListCampaigns(ctx, true)
+	if err != nil {
+		return fmt.Errorf("list cart reminder campaigns: %w", err)
+	}
+
+	for i := range campaigns {
+		campaign := &campaigns[i]
+		now := time.Now()
+		carts, err := s.repo.FindAbandoned(ctx, campaign.ID, campaign.MinCartTotal,
+			now.Add(-time.Duration(campaign.DelayHours)*time.Hour),
+			now.Add(-s.cfg.Cooldown),
+			cartReminderBatchSize,
+		)
+		if err != nil {
+			return fmt.Errorf("find abandoned carts: %w", err)
+		}
+
+		sent := 0
+		for _, cart := range carts {
+			err := s.remind(ctx, campaign, cart)
+			if errors.Is(err, errCartNothingToRemind) {
+				continue
+			}
+			if err != nil {
+				s.log.Warn("failed to send cart reminder",
+					zap.Int("campaignId", campaign.ID), zap.Int("userId", cart.UserID), zap.Error(err))
+				continue
+			}
+			sent++
+		}
+		if sent > 0 {
+			s.log.Info("cart reminders sent", zap.Int("campaignId", campaign.ID), zap.Int("count", sent))
+		}
+	}
+	return nil
+}
+
+var (
+	errNoReminderChannel = errors.New("no channel to reach the user")
+	// errCartNothingToRemind: the cart has no active products left, nothing is sent.
+	errCartNothingToRemind = errors.New("cart has no active products")
+)
+
+func (s *CartReminderService) remind(ctx context.Context, campaign *domain.CartReminderCampaign, cart domain.AbandonedCart) error {
+	user, err := s.userRepo.FindByID(ctx, cart.UserID)
+	if err != nil {
+		return err
+	}
+
+	var channel string
+	switch {
+	case user.TelegramID != nil && s.notifier != nil:
+		channel = domain.CartReminderChannelTelegram
+	case user.Email != nil && *user.Email != "" && s.emailService != nil:
+		channel = domain.CartReminderChannelEmail
+	default:
+		return errNoReminderChannel
+	}
+
+	items, err := s.cartRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("load cart: %w", err)
+	}
+	active := items[:0]
+	for _, item := range items {
+		if item.Product.IsActive {
+			active = append(active, item)
+		}
+	}
+	if len(active) == 0 {
+		return errCartNothingToRemind
+	}
+
+	token, err := newReminderToken()
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/cart-reminders/%s", s.apiURL, token)
+	msg := &domain.CartReminderMessage{
+		Items:          active,
+		Total:          cart.Total,
+		CartURL:        link,
+		UnsubscribeURL: link + "/unsubscribe",
+	}
+
+	if campaign.HasPromo() {
+		promo, err := s.createPromo(ctx, campaign)
+		if err != nil {
+			return err
+		}
+		msg.Promo = promo
+	}
+
+	// The reminder is saved before sending so the frequency cap counts it
+	// even if the process dies right after the message went out.
+	reminder := &domain.CartReminder{
+		CampaignID: &campaign.ID,
+		UserID:     user.ID,
+		Channel:    channel,
+		Token:      token,
+		CartTotal:  cart.Total,
+		SentAt:     time.Now(),
+	}
+	if msg.Promo != nil {
+		reminder.PromoCodeID = &msg.Promo.ID
+	}
+	if err := s.repo.Create(ctx, reminder); err != nil {
+		if msg.Promo != nil {
+			_ = s.promoRepo.Delete(ctx, msg.Promo.ID)
+		}
+		return fmt.Errorf("save cart reminder: %w", err)
+	}
+
+	switch channel {
+	case domain.CartReminderChannelTelegram:
+		err = s.notifier.NotifyAbandonedCart(ctx, *user.TelegramID, msg)
+	case domain.CartReminderChannelEmail:
+		err = s.emailService.SendCartReminder(*user.Email, userFirstName(user), msg)
+	}
+	if err != nil {
+		if delErr := s.repo.Delete(ctx, reminder.ID); delErr != nil {
+			s.log.Warn("failed to delete unsent cart reminder", zap.Int("id", reminder.ID), zap.Error(delErr))
+		}
+		if msg.Promo != nil {
+			_ = s.promoRepo.Delete(ctx, msg.Promo.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// createPromo creates a single-use promo code for one reminder.
+func (s *CartReminderService) createPromo(ctx context.Context, campaign *domain.CartReminderCampaign) (*domain.PromoCode, error) {
+	maxUses := 1
+	expiresAt := time.Now().Add(time.Duration(campaign.PromoValidHours) * time.Hour)
+	desc := fmt.Sprintf("Напоминание о корзине: %s", campaign.Name)
+	promo := &domain.PromoCode{
+		Code:          generateCartPromoCode(),
+		DiscountType:  *campaign.PromoDiscountType,
+		DiscountValue: *campaign.PromoDiscountValue,
+		MaxUses:       &maxUses,
+		IsActive:      true,
+		ExpiresAt:     &expiresAt,
+		Description:   &desc,
+	}
+	if err := s.promoRepo.Create(ctx, promo); err != nil {
+		return nil, fmt.Errorf("create reminder promo: %w", err)
+	}
+	return promo, nil
+}
+
+// Click records a click on a reminder link and returns the cart URL to
+// redirect to, with the reminder's promo code if there is one.
+func (s *CartReminderService) Click(ctx context.Context, token string) (string, error) {
+	cartURL := s.appURL + "/cart"
+
+	reminder, err := s.repo.FindByToken(ctx, token)
+	if err != nil {
+		return cartURL, err
+	}
+	if err := s.repo.MarkClicked(ctx, reminder.ID, time.Now()); err != nil {
+		s.log.Warn("failed to record reminder click", zap.Int("reminderId", reminder.ID), zap.Error(err))
+	}
+
+	if reminder.PromoCodeID != nil {
+		if promo, err := s.promoRepo.FindByID(ctx, *reminder.PromoCodeID); err == nil {
+			cartURL += "?promo=" + promo.Code
+		}
+	}
+	return cartURL, nil
+}
+
+// Unsubscribe turns reminders off for the user a reminder was sent to and
+// returns the page to redirect to.
+func (s *CartReminderService) Unsubscribe(ctx context.Context, token string) (string, error) {
+	doneURL := s.appURL + "/?cartReminders=off"
+
+	reminder, err := s.repo.FindByToken(ctx, token)
+	if err != nil {
+		return s.appURL, err
+	}
+	user, err := s.userRepo.FindByID(ctx, reminder.UserID)
+	if err != nil {
+		return s.appURL, err
+	}
+	if user.CartRemindersOptOut {
+		return doneURL, nil
+	}
+
+	user.CartRemindersOptOut = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return s.appURL, fmt.Errorf("update user: %w", err)
+	}
+	s.log.Info("user unsubscribed from cart reminders", zap.Int("userId", user.ID))
+	return doneURL, nil
+}
+
+// TrackConversion attributes a new order to the user's latest reminder.
+func (s *CartReminderService) TrackConversion(ctx context.Context, order *domain.Order) {
+	if order.UserID == nil {
+		return
+	}
+	now := time.Now()
+	converted, err := s.repo.MarkConverted(ctx, *order.UserID, order.ID, now.Add(-s.cfg.Attribution), now)
+	if err != nil {
+		s.log.Warn("failed to track cart reminder conversion", zap.Int("orderId", order.ID), zap.Error(err))
+		return
+	}
+	if converted {
+		s.log.Info("cart reminder converted", zap.Int("userId", *order.UserID), zap.String("orderNumber", order.OrderNumber))
+	}
+}
+
+func newReminderToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate reminder token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// generateCartPromoCode creates a random code like "CART-A1B2C3".
+func generateCartPromoCode() string {
+	const chars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, 6)
+	for i := range b {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		b[i] = chars[n.Int64()]
+	}
+	return "CART-" + string(b)
+}
+
+func userFirstName(u *domain.User) string {
+	if u.FirstName != nil {
+		return *u.FirstName
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,14 +15,18 @@ import (
 	"github.com/brown/3d-print-shop/internal/domain"
 )
 
-const categoryCacheKey = "categories:tree"
+// The tree is cached per root subtree so that a change only evicts the branch it touches.
+const categoryRootsCacheKey = "categories:roots"
+const categoryTreeCachePrefix = "categories:tree:"
 const categoryCacheTTL = 30 * time.Minute
 
 // CategoryService handles category business logic.
 type CategoryService struct {
-	repo  domain.CategoryRepository
-	cache *cache.Store
-	log   *zap.Logger
+	repo         domain.CategoryRepository
+	slugHistory  *SlugRedirectService
+	translations *TranslationService
+	cache        *cache.Store
+	log          *zap.Logger
 }
 
 // NewCategoryService creates a new category service.
@@ -29,25 +34,47 @@ func NewCategoryService(repo domain.CategoryRepository, cache *cache.Store, log
 	return &CategoryService{repo: repo, cache: cache, log: log}
 }
 
+// SetSlugRedirectService enables slug history: old category links redirect to the new slug.
+func (s *CategoryService) SetSlugRedirectService(sr *SlugRedirectService) {
+	s.slugHistory = sr
+}
+
+// SetTranslationService enables translated category text on the storefront.
+func (s *CategoryService) SetTranslationService(ts *TranslationService) {
+	s.translations = ts
+}
+
 // CreateCategoryInput represents the input for creating a category.
 type CreateCategoryInput struct {
-	Name         string  `json:"name" binding:"required,min=1,max=255"`
-	Slug         *string `json:"slug"`
-	Description  *string `json:"description"`
-	ParentID     *int    `json:"parentId"`
-	DisplayOrder *int    `json:"displayOrder"`
-	ImageURL     *string `json:"imageUrl"`
+	Name            string  `json:"name" binding:"required,min=1,max=255"`
+	Slug            *string `json:"slug"`
+	Description     *string `json:"description"`
+	ParentID        *int    `json:"parentId"`
+	DisplayOrder    *int    `json:"displayOrder"`
+	ImageURL        *string `json:"imageUrl"`
+	MetaTitle       *string `json:"metaTitle" binding:"omitempty,max=255"`
+	MetaDescription *string `json:"metaDescription"`
+	H1              *string `json:"h1" binding:"omitempty,max=255"`
 }
 
 // UpdateCategoryInput represents the input for updating a category.
 type UpdateCategoryInput struct {
-	Name         *string `json:"name" binding:"omitempty,min=1,max=255"`
-	Slug         *string `json:"slug"`
-	Description  *string `json:"description"`
-	ParentID     *int    `json:"parentId"`
-	DisplayOrder *int    `json:"displayOrder"`
-	ImageURL     *string `json:"imageUrl"`
-	IsActive     *bool   `json:"isActive"`
+	Name            *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Slug            *string `json:"slug"`
+	Description     *string `json:"description"`
+	ParentID        *int    `json:"parentId"`
+	DisplayOrder    *int    `json:"displayOrder"`
+	ImageURL        *string `json:"imageUrl"`
+	IsActive        *bool   `json:"isActive"`
+	MetaTitle       *string `json:"metaTitle" binding:"omitempty,max=255"`
+	MetaDescription *string `json:"metaDescription"`
+	H1              *string `json:"h1" binding:"omitempty,max=255"`
+}
+
+// MoveCategoryInput moves a category together with its subtree.
+type MoveCategoryInput struct {
+	ParentID     *int `json:"parentId"` // nil or 0 — move to the top level
+	DisplayOrder *int `json:"displayOrder"`
 }
 
 // Create creates a new category, auto-generating slug from name if not provided.
@@ -70,7 +97,7 @@ func (s *CategoryService) Create(ctx context.Context, input CreateCategoryInput)
 	if input.ParentID != nil {
 		if _, err := s.repo.FindByID(ctx, *input.ParentID); err != nil {
 			if errors.Is(err, domain.ErrCategoryNotFound) {
-				return nil, fmt.Errorf("parent category not found")
+				return nil, domain.ErrCategoryParentNotFound
 			}
 			return nil, err
 		}
@@ -82,50 +109,244 @@ func (s *CategoryService) Create(ctx context.Context, input CreateCategoryInput)
 	}
 
 	cat := &domain.Category{
-		Name:         input.Name,
-		Slug:         slug,
-		Description:  input.Description,
-		ParentID:     input.ParentID,
-		DisplayOrder: displayOrder,
-		ImageURL:     input.ImageURL,
-		IsActive:     true,
+		Name:            input.Name,
+		Slug:            slug,
+		Description:     input.Description,
+		ParentID:        input.ParentID,
+		DisplayOrder:    displayOrder,
+		ImageURL:        input.ImageURL,
+		IsActive:        true,
+		MetaTitle:       input.MetaTitle,
+		MetaDescription: input.MetaDescription,
+		H1:              input.H1,
 	}
 
 	if err := s.repo.Create(ctx, cat); err != nil {
 		return nil, fmt.Errorf("create category: %w", err)
 	}
 
-	s.invalidateCategoryCache(ctx)
+	s.invalidateSubtreeOf(ctx, cat.ID)
 	s.log.Info("category created", zap.Int("id", cat.ID), zap.String("slug", cat.Slug))
 	return cat, nil
 }
 
-// GetTree returns the full category tree (cached for 30 min).
-func (s *CategoryService) GetTree(ctx context.Context) ([]domain.Category, error) {
-	var categories []domain.Category
-	if found, err := s.cache.Get(ctx, categoryCacheKey, &categories); err == nil && found {
-		s.log.Debug("categories served from cache")
-		return categories, nil
+// GetTree returns the full category tree in the given locale. Each root subtree
+// is cached for 30 min; translations are cached separately per locale.
+func (s *CategoryService) GetTree(ctx context.Context, locale string) ([]domain.Category, error) {
+	var rootIDs []int
+	if found, err := s.cache.Get(ctx, categoryRootsCacheKey, &rootIDs); err != nil || !found {
+		rootIDs, err = s.repo.FindRootIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.cache.Set(ctx, categoryRootsCacheKey, rootIDs, categoryCacheTTL); err != nil {
+			s.log.Warn("failed to cache category roots", zap.Error(err))
+		}
 	}
 
-	categories, err := s.repo.FindAll(ctx)
+	categories := make([]domain.Category, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		root, err := s.getSubtree(ctx, id)
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			// Stale roots list: the root was deleted or moved concurrently.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *root)
+	}
+
+	if s.translations != nil {
+		s.translations.TranslateCategories(ctx, categories, locale)
+	}
+	return categories, nil
+}
+
+func (s *CategoryService) getSubtree(ctx context.Context, rootID int) (*domain.Category, error) {
+	key := categoryTreeCachePrefix + strconv.Itoa(rootID)
+
+	var root domain.Category
+	if found, err := s.cache.Get(ctx, key, &root); err == nil && found {
+		return &root, nil
+	}
+
+	tree, err := s.repo.FindTree(ctx, rootID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.cache.Set(ctx, categoryCacheKey, categories, categoryCacheTTL); err != nil {
-		s.log.Warn("failed to cache categories", zap.Error(err))
+	if err := s.cache.Set(ctx, key, tree, categoryCacheTTL); err != nil {
+		s.log.Warn("failed to cache category subtree", zap.Int("rootId", rootID), zap.Error(err))
+	}
+	return tree, nil
+}
+
+// invalidateSubtreeOf evicts the cached root subtree containing the category.
+// The roots list is evicted too when the category itself is a root.
+func (s *CategoryService) invalidateSubtreeOf(ctx context.Context, id int) {
+	path, err := s.repo.FindAncestors(ctx, id)
+	if err != nil {
+		s.log.Warn("failed to resolve category root, dropping whole tree cache", zap.Int("id", id), zap.Error(err))
+		s.invalidateCategoryCache(ctx)
+		return
 	}
+	s.invalidateRoot(ctx, path[0].ID, len(path) == 1)
+}
 
-	return categories, nil
+func (s *CategoryService) invalidateRoot(ctx context.Context, rootID int, withRoots bool) {
+	invalidateSEOCache(ctx, s.cache, s.log)
+	if err := s.cache.Delete(ctx, categoryTreeCachePrefix+strconv.Itoa(rootID)); err != nil {
+		s.log.Warn("failed to invalidate category subtree cache", zap.Int("rootId", rootID), zap.Error(err))
+	}
+	if withRoots {
+		if err := s.cache.Delete(ctx, categoryRootsCacheKey); err != nil {
+			s.log.Warn("failed to invalidate category roots cache", zap.Error(err))
+		}
+	}
 }
 
 func (s *CategoryService) invalidateCategoryCache(ctx context.Context) {
-	if err := s.cache.Delete(ctx, categoryCacheKey); err != nil {
+	if err := s.cache.Delete(ctx, categoryRootsCacheKey); err != nil {
+		s.log.Warn("failed to invalidate category cache", zap.Error(err))
+	}
+	if err := s.cache.DeleteByPrefix(ctx, categoryTreeCachePrefix); err != nil {
 		s.log.Warn("failed to invalidate category cache", zap.Error(err))
 	}
 }
 
+// rootOf returns the ID of the top-level ancestor of the category.
+func (s *CategoryService) rootOf(ctx context.Context, id int) (int, error) {
+	path, err := s.repo.FindAncestors(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return path[0].ID, nil
+}
+
+// GetBySlug returns a category by slug in the given locale. A renamed category's
+// old slug yields *domain.SlugMovedError with the current slug.
+func (s *CategoryService) GetBySlug(ctx context.Context, slug, locale string) (*domain.Category, error) {
+	cat, err := s.repo.FindBySlug(ctx, slug)
+	if errors.Is(err, domain.ErrCategoryNotFound) && s.slugHistory != nil {
+		if newSlug, rerr := s.slugHistory.Resolve(ctx, domain.SlugEntityCategory, slug); rerr == nil {
+			return nil, &domain.SlugMovedError{NewSlug: newSlug}
+		}
+	}
+	if err == nil && s.translations != nil {
+		s.translations.TranslateCategory(ctx, cat, locale)
+	}
+	return cat, err
+}
+
+// Breadcrumbs returns the path from the top-level category down to the given one.
+func (s *CategoryService) Breadcrumbs(ctx context.Context, slug, locale string) ([]domain.Category, error) {
+	cat, err := s.GetBySlug(ctx, slug, "")
+	if err != nil {
+		return nil, err
+	}
+	path, err := s.repo.FindAncestors(ctx, cat.ID)
+	if err != nil {
+		return nil, err
+	}
+	if s.translations != nil {
+		s.translations.TranslateCategories(ctx, path, locale)
+	}
+	return path, nil
+}
+
+// validateParent checks that parentID exists and is not inside the subtree of id.
+// It gives early errors only: repo.Update repeats the check under a tree lock.
+func (s *CategoryService) validateParent(ctx context.Context, id, parentID int) error {
+	if _, err := s.repo.FindByID(ctx, parentID); err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return domain.ErrCategoryParentNotFound
+		}
+		return err
+	}
+
+	subtree, err := s.repo.FindSubtreeIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, sid := range subtree {
+		if sid == parentID {
+			return domain.ErrCategoryCycle
+		}
+	}
+	return nil
+}
+
+// Move re-parents a category together with all its descendants.
+func (s *CategoryService) Move(ctx context.Context, id int, input MoveCategoryInput) (*domain.Category, error) {
+	cat, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var newParent *int
+	if input.ParentID != nil && *input.ParentID != 0 {
+		if err := s.validateParent(ctx, id, *input.ParentID); err != nil {
+			return nil, err
+		}
+		newParent = input.ParentID
+	}
+
+	oldRoot, err := s.rootOf(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cat.ParentID = newParent
+	if input.DisplayOrder != nil {
+		cat.DisplayOrder = *input.DisplayOrder
+	}
+	cat.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, cat); err != nil {
+		return nil, fmt.Errorf("move category: %w", err)
+	}
+
+	// Both the branch it left and the branch it joined change.
+	s.invalidateRoot(ctx, oldRoot, oldRoot == id)
+	s.invalidateSubtreeOf(ctx, id)
+	s.invalidateProductLists(ctx)
+
+	s.log.Info("category moved", zap.Int("id", id), zap.Any("parentId", newParent))
+	return cat, nil
+}
+
+// Reorder sets DisplayOrder for several categories at once.
+func (s *CategoryService) Reorder(ctx context.Context, orders []domain.CategoryOrder) error {
+	if err := s.repo.UpdateDisplayOrders(ctx, orders); err != nil {
+		return err
+	}
+
+	roots := make(map[int]bool)
+	for _, o := range orders {
+		path, err := s.repo.FindAncestors(ctx, o.ID)
+		if err != nil {
+			s.invalidateCategoryCache(ctx)
+			return nil
+		}
+		root := path[0].ID
+		roots[root] = roots[root] || len(path) == 1
+	}
+	for root, isRoot := range roots {
+		s.invalidateRoot(ctx, root, isRoot)
+	}
+
+	s.log.Info("categories reordered", zap.Int("count", len(orders)))
+	return nil
+}
+
+// invalidateProductLists drops cached product lists: category filters include subcategories.
+func (s *CategoryService) invalidateProductLists(ctx context.Context) {
+	if err := s.cache.DeleteByPrefix(ctx, productCachePrefix); err != nil {
+		s.log.Warn("failed to invalidate product cache", zap.Error(err))
+	}
+}
+
 // GetByID returns a category by ID.
 func (s *CategoryService) GetByID(ctx context.Context, id int) (*domain.Category, error) {
 	return s.repo.FindByID(ctx, id)
@@ -137,6 +358,7 @@ func (s *CategoryService) Update(ctx context.Context, id int, input UpdateCatego
 	if err != nil {
 		return nil, err
 	}
+	prevSlug := cat.Slug
 
 	if input.Name != nil {
 		cat.Name = *input.Name
@@ -153,13 +375,16 @@ func (s *CategoryService) Update(ctx context.Context, id int, input UpdateCatego
 	if input.Description != nil {
 		cat.Description = input.Description
 	}
+	parentChanged := false
 	if input.ParentID != nil {
 		if *input.ParentID == 0 {
+			parentChanged = cat.ParentID != nil
 			cat.ParentID = nil
-		} else {
-			if *input.ParentID == id {
-				return nil, fmt.Errorf("category cannot be its own parent")
+		} else if cat.ParentID == nil || *cat.ParentID != *input.ParentID {
+			if err := s.validateParent(ctx, id, *input.ParentID); err != nil {
+				return nil, err
 			}
+			parentChanged = true
 			cat.ParentID = input.ParentID
 		}
 	}
@@ -172,6 +397,20 @@ func (s *CategoryService) Update(ctx context.Context, id int, input UpdateCatego
 	if input.IsActive != nil {
 		cat.IsActive = *input.IsActive
 	}
+	if input.MetaTitle != nil {
+		cat.MetaTitle = input.MetaTitle
+	}
+	if input.MetaDescription != nil {
+		cat.MetaDescription = input.MetaDescription
+	}
+	if input.H1 != nil {
+		cat.H1 = input.H1
+	}
+
+	oldRoot, err := s.rootOf(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
 	cat.UpdatedAt = time.Now()
 
@@ -179,7 +418,15 @@ func (s *CategoryService) Update(ctx context.Context, id int, input UpdateCatego
 		return nil, fmt.Errorf("update category: %w", err)
 	}
 
-	s.invalidateCategoryCache(ctx)
+	if s.slugHistory != nil && cat.Slug != prevSlug {
+		s.slugHistory.Record(ctx, domain.SlugEntityCategory, cat.ID, prevSlug, cat.Slug)
+	}
+
+	s.invalidateRoot(ctx, oldRoot, oldRoot == id)
+	if parentChanged {
+		s.invalidateSubtreeOf(ctx, id)
+		s.invalidateProductLists(ctx)
+	}
 	s.log.Info("category updated", zap.Int("id", cat.ID))
 	return cat, nil
 }
@@ -206,11 +453,16 @@ func (s *CategoryService) Delete(ctx context.Context, id int) error {
 		return domain.ErrCategoryHasProducts
 	}
 
+	root, err := s.rootOf(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("delete category: %w", err)
 	}
 
-	s.invalidateCategoryCache(ctx)
+	s.invalidateRoot(ctx, root, root == id)
 	s.log.Info("category deleted", zap.Int("id", id))
 	return nil
 }
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// CompanyInput represents company requisites sent by the customer.
+// KPP is required for organizations and must be empty for individual entrepreneurs.
+type CompanyInput struct {
+	LegalName    string  `json:"legalName" binding:"required,max=255"`
+	INN          string  `json:"inn" binding:"required"`
+	KPP          *string `json:"kpp"`
+	OGRN         string  `json:"ogrn" binding:"required"`
+	LegalAddress string  `json:"legalAddress" binding:"required"`
+}
+
+// CompanyService manages the company requisites of B2B customers.
+type CompanyService struct {
+	repo domain.CompanyRepository
+	log  *zap.Logger
+}
+
+// NewCompanyService creates a new company service.
+func NewCompanyService(repo domain.CompanyRepository, log *zap.Logger) *CompanyService {
+	return &CompanyService{repo: repo, log: log}
+}
+
+// Get returns the user's company.
+func (s *CompanyService) Get(ctx context.Context, userID int) (*domain.Company, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// Save creates or replaces the user's company requisites.
+func (s *CompanyService) Save(ctx context.Context, userID int, input CompanyInput) (*domain.Company, error) {
+	company, err := s.repo.FindByUserID(ctx, userID)
+	if errors.Is(err, domain.ErrCompanyNotFound) {
+		company = &domain.Company{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	company.LegalName = strings.TrimSpace(input.LegalName)
+	company.INN = strings.TrimSpace(input.INN)
+	company.OGRN = strings.TrimSpace(input.OGRN)
+	company.LegalAddress = strings.TrimSpace(input.LegalAddress)
+	company.KPP = nil
+	if input.KPP != nil {
+		if kpp := strings.ToUpper(strings.TrimSpace(*input.KPP)); kpp != "" {
+			company.KPP = &kpp
+		}
+	}
+	if err := validateCompany(company); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, company); err != nil {
+		return nil, fmt.Errorf("save company: %w", err)
+	}
+
+	s.log.Info("company saved", zap.Int("userId", userID), zap.String("inn", company.INN))
+	return company, nil
+}
+
+// Delete removes the user's company requisites. Past orders keep no link to it.
+func (s *CompanyService) Delete(ctx context.Context, userID int) error {
+	return s.repo.DeleteByUserID(ctx, userID)
+}
+
+// GetByID returns a company for the admin panel.
+func (s *CompanyService) GetByID(ctx context.Context, id int) (*domain.Company, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// List returns companies for the admin panel.
+func (s *CompanyService) List(ctx context.Context, filter domain.CompanyFilter) ([]domain.Company, int64, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// validateCompany checks the INN and OGRN check digits and that the KPP and
+// OGRN match the kind of company the INN belongs to.
+func validateCompany(c *domain.Company) error {
+	if err := domain.ValidateINN(c.INN); err != nil {
+		return err
+	}
+	if err := domain.ValidateOGRN(c.OGRN); err != nil {
+		return err
+	}
+
+	if c.IsEntrepreneur() {
+		if c.KPP != nil {
+			return domain.ErrInvalidKPP
+		}
+		if len(c.OGRN) != 15 {
+			return domain.ErrInvalidOGRN
+		}
+		return nil
+	}
+
+	if c.KPP == nil {
+		return domain.ErrInvalidKPP
+	}
+	if err := domain.ValidateKPP(*c.KPP); err != nil {
+		return err
+	}
+	if len(c.OGRN) != 13 {
+		return domain.ErrInvalidOGRN
+	}
+	return nil
+}
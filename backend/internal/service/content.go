@@ -12,27 +12,43 @@ import (
 	"github.com/brown/3d-print-shop/internal/domain"
 )
 
+const contentCachePrefix = "content:"
 const contentCacheTTL = 10 * time.Minute
 
 type ContentService struct {
-	repo  domain.ContentBlockRepository
-	cache *cache.Store
-	log   *zap.Logger
+	repo         domain.ContentBlockRepository
+	translations *TranslationService
+	cache        *cache.Store
+	log          *zap.Logger
 }
 
 func NewContentService(repo domain.ContentBlockRepository, cache *cache.Store, log *zap.Logger) *ContentService {
 	return &ContentService{repo: repo, cache: cache, log: log}
 }
 
-func contentCacheKey(slug string) string {
-	return fmt.Sprintf("content:%s", slug)
+// SetTranslationService enables translated content blocks.
+func (s *ContentService) SetTranslationService(ts *TranslationService) {
+	s.translations = ts
 }
 
-// GetBlock returns content block data by slug. Uses Redis cache.
-func (s *ContentService) GetBlock(ctx context.Context, slug string) (json.RawMessage, error) {
+// contentCacheKey is "content:<slug>", with "@<locale>" appended for translated versions.
+func contentCacheKey(slug, locale string) string {
+	if locale == "" {
+		return fmt.Sprintf("%s%s", contentCachePrefix, slug)
+	}
+	return fmt.Sprintf("%s%s@%s", contentCachePrefix, slug, locale)
+}
+
+// GetBlock returns content block data by slug in the given locale. Uses Redis cache.
+func (s *ContentService) GetBlock(ctx context.Context, slug, locale string) (json.RawMessage, error) {
+	if s.translations == nil || !s.translations.translated(locale) {
+		locale = ""
+	}
+	key := contentCacheKey(slug, locale)
+
 	// Try cache
 	var cached json.RawMessage
-	if found, err := s.cache.Get(ctx, contentCacheKey(slug), &cached); err == nil && found {
+	if found, err := s.cache.Get(ctx, key, &cached); err == nil && found {
 		return cached, nil
 	}
 
@@ -41,8 +57,13 @@ func (s *ContentService) GetBlock(ctx context.Context, slug string) (json.RawMes
 		return nil, fmt.Errorf("find content block %q: %w", slug, err)
 	}
 
-	_ = s.cache.Set(ctx, contentCacheKey(slug), block.Data, contentCacheTTL)
-	return block.Data, nil
+	data := block.Data
+	if locale != "" {
+		data = s.translations.TranslateContent(ctx, block.ID, block.Data, locale)
+	}
+
+	_ = s.cache.Set(ctx, key, data, contentCacheTTL)
+	return data, nil
 }
 
 // UpdateBlock updates a content block's data and invalidates cache.
@@ -57,7 +78,9 @@ func (s *ContentService) UpdateBlock(ctx context.Context, slug string, data json
 		return fmt.Errorf("upsert content block %q: %w", slug, err)
 	}
 
-	_ = s.cache.Delete(ctx, contentCacheKey(slug))
+	// Locales without a translation serve the default data, drop them too.
+	_ = s.cache.Delete(ctx, contentCacheKey(slug, ""))
+	_ = s.cache.DeleteByPrefix(ctx, contentCacheKey(slug, "")+"@")
 
 	s.log.Info("content block updated", zap.String("slug", slug))
 	return nil
@@ -111,6 +111,7 @@ type CustomOrderService struct {
 	notifier        domain.OrderNotifier
 	emailService    *EmailService
 	s3              *storage.S3Client
+	numbers         *OrderNumberGenerator
 	db              *gorm.DB
 	log             *zap.Logger
 }
@@ -126,11 +127,17 @@ func NewCustomOrderService(
 		orderRepo:       orderRepo,
 		customOrderRepo: customOrderRepo,
 		userRepo:        userRepo,
+		numbers:         defaultOrderNumbers(orderRepo),
 		db:              db,
 		log:             log,
 	}
 }
 
+// SetOrderNumberGenerator задаёт формат номеров индивидуальных заказов.
+func (s *CustomOrderService) SetOrderNumberGenerator(g *OrderNumberGenerator) {
+	s.numbers = g
+}
+
 func (s *CustomOrderService) SetPaymentService(ps *PaymentService) {
 	s.paymentService = ps
 }
@@ -164,7 +171,7 @@ func (s *CustomOrderService) SubmitRequest(ctx context.Context, input SubmitCust
 		}
 	}
 
-	orderNumber, err := s.orderRepo.NextOrderNumber(ctx)
+	orderNumber, err := s.numbers.Next(ctx, orderSeriesCustom)
 	if err != nil {
 		return nil, fmt.Errorf("generate order number: %w", err)
 	}
@@ -245,7 +252,7 @@ func (s *CustomOrderService) CreateByAdmin(ctx context.Context, input CreateCust
 		subtotal = math.Round(subtotal*100) / 100
 	}
 
-	orderNumber, err := s.orderRepo.NextOrderNumber(ctx)
+	orderNumber, err := s.numbers.Next(ctx, orderSeriesCustom)
 	if err != nil {
 		return nil, fmt.Errorf("generate order number: %w", err)
 	}
@@ -413,7 +420,10 @@ func (s *CustomOrderService) MarkPaidManually(ctx context.Context, orderID int)
 	return s.db.WithContext(ctx).
 		Model(&domain.Order{}).
 		Where("id = ?", orderID).
-		Update("is_paid", true).Error
+		Updates(map[string]interface{}{
+			"is_paid":     true,
+			"paid_amount": gorm.Expr("total_price"),
+		}).Error
 }
 
 // UpdateAdminDetails — обновляет admin_notes, print_settings, file_urls, bitrix поля, total_price.
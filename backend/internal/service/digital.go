@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/storage"
+)
+
+const (
+	maxDigitalFileSize = 200 << 20 // 200 MB
+	// presignTTL is how long the S3 URL behind a single download request is valid.
+	presignTTL = 5 * time.Minute
+)
+
+var allowedDigitalExtensions = map[string]string{
+	".stl":  "model/stl",
+	".obj":  "model/obj",
+	".3mf":  "model/3mf",
+	".step": "application/step",
+	".stp":  "application/step",
+	".zip":  "application/zip",
+	".pdf":  "application/pdf",
+}
+
+var (
+	ErrDigitalFileTooLarge    = errors.New("файл слишком большой (максимум 200 MB)")
+	ErrDigitalUnsupportedFile = errors.New("неподдерживаемый формат файла (STL, OBJ, 3MF, STEP, ZIP, PDF)")
+	ErrProductNotDigital      = errors.New("product is not digital")
+	ErrDigitalStorageDisabled = errors.New("file storage not configured")
+)
+
+// DigitalService manages private files of digital products and issues
+// expiring, download-count-limited links after payment.
+type DigitalService struct {
+	repo         domain.DigitalRepository
+	productRepo  domain.ProductRepository
+	orderRepo    domain.OrderRepository
+	s3           *storage.S3Client
+	emailService *EmailService
+	notifier     domain.DigitalDownloadNotifier
+	cfg          config.DigitalConfig
+	log          *zap.Logger
+}
+
+// NewDigitalService creates a new digital product service.
+func NewDigitalService(
+	repo domain.DigitalRepository,
+	productRepo domain.ProductRepository,
+	orderRepo domain.OrderRepository,
+	s3 *storage.S3Client,
+	cfg config.DigitalConfig,
+	log *zap.Logger,
+) *DigitalService {
+	return &DigitalService{
+		repo:        repo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		s3:          s3,
+		cfg:         cfg,
+		log:         log,
+	}
+}
+
+// SetEmailService sets the email service used to deliver download links.
+func (s *DigitalService) SetEmailService(es *EmailService) {
+	s.emailService = es
+}
+
+// SetNotifier sets the Telegram notifier used to deliver download links.
+func (s *DigitalService) SetNotifier(n domain.DigitalDownloadNotifier) {
+	s.notifier = n
+}
+
+// UploadFile stores a private file for a digital product.
+func (s *DigitalService) UploadFile(ctx context.Context, productID int, fileName string, file io.Reader, fileSize int64) (*domain.ProductFile, error) {
+	if s.s3 == nil {
+		return nil, ErrDigitalStorageDisabled
+	}
+
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsDigital {
+		return nil, ErrProductNotDigital
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	contentType, ok := allowedDigitalExtensions[ext]
+	if !ok {
+		return nil, ErrDigitalUnsupportedFile
+	}
+	if fileSize > maxDigitalFileSize {
+		return nil, ErrDigitalFileTooLarge
+	}
+
+	key := fmt.Sprintf("digital/%d/%s%s", productID, uuid.New().String(), ext)
+	if err := s.s3.UploadPrivate(ctx, key, file, contentType); err != nil {
+		return nil, fmt.Errorf("upload to s3: %w", err)
+	}
+
+	pf := &domain.ProductFile{
+		ProductID:   productID,
+		FileName:    filepath.Base(fileName),
+		S3Key:       key,
+		ContentType: contentType,
+		SizeBytes:   fileSize,
+	}
+	if err := s.repo.CreateFile(ctx, pf); err != nil {
+		_ = s.s3.Delete(ctx, key)
+		return nil, fmt.Errorf("save product file: %w", err)
+	}
+
+	s.log.Info("digital file uploaded", zap.Int("productId", productID), zap.String("key", key))
+	return pf, nil
+}
+
+// ListFiles returns files attached to a digital product.
+func (s *DigitalService) ListFiles(ctx context.Context, productID int) ([]domain.ProductFile, error) {
+	return s.repo.ListFilesByProductIDs(ctx, []int{productID})
+}
+
+// DeleteFile removes a file from S3 and the database. Issued links to it stop working.
+func (s *DigitalService) DeleteFile(ctx context.Context, fileID int) error {
+	pf, err := s.repo.FindFileByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteFile(ctx, fileID); err != nil {
+		return err
+	}
+	if s.s3 != nil {
+		if err := s.s3.Delete(ctx, pf.S3Key); err != nil {
+			s.log.Warn("failed to delete digital file from s3", zap.String("key", pf.S3Key), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// IssueForOrder creates download links for every digital item of a paid order and
+// sends the new links to the buyer. Safe to call more than once: files already
+// issued are skipped and the buyer is only notified when new links were created.
+func (s *DigitalService) IssueForOrder(ctx context.Context, orderID int) error {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if !order.IsPaid {
+		return nil
+	}
+
+	var productIDs []int
+	for _, item := range order.Items {
+		if item.ProductID != nil && item.Product != nil && item.Product.IsDigital {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	files, err := s.repo.ListFilesByProductIDs(ctx, productIDs)
+	if err != nil {
+		return fmt.Errorf("list digital files: %w", err)
+	}
+	if len(files) == 0 {
+		s.log.Warn("paid digital order has no files to deliver", zap.String("order", order.OrderNumber))
+		return nil
+	}
+
+	expiresAt := time.Now().Add(s.cfg.LinkTTL)
+	links := make([]domain.DownloadLink, 0, len(files))
+	tokens := make(map[string]bool, len(files))
+	for _, f := range files {
+		token, err := newDownloadToken()
+		if err != nil {
+			return err
+		}
+		tokens[token] = true
+		links = append(links, domain.DownloadLink{
+			Token:         token,
+			OrderID:       order.ID,
+			UserID:        order.UserID,
+			ProductID:     f.ProductID,
+			ProductFileID: f.ID,
+			MaxDownloads:  s.cfg.MaxDownloads,
+			ExpiresAt:     expiresAt,
+		})
+	}
+	if err := s.repo.CreateLinks(ctx, links); err != nil {
+		return fmt.Errorf("create download links: %w", err)
+	}
+
+	all, err := s.repo.ListLinksByOrderID(ctx, order.ID)
+	if err != nil {
+		return fmt.Errorf("reload download links: %w", err)
+	}
+	// Links of files issued before keep their old tokens; only ours are new.
+	var issued []domain.DownloadLink
+	for _, link := range all {
+		if tokens[link.Token] {
+			issued = append(issued, link)
+		}
+	}
+	if len(issued) == 0 {
+		return nil
+	}
+	s.fillURLs(issued)
+
+	s.log.Info("download links issued", zap.String("order", order.OrderNumber), zap.Int("links", len(issued)))
+
+	if s.emailService != nil {
+		s.emailService.SendDigitalDownloads(order, issued)
+	}
+	if s.notifier != nil {
+		if err := s.notifier.NotifyDigitalDownloads(ctx, order, issued); err != nil {
+			s.log.Warn("failed to send download links via telegram", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// ListMyDownloads returns the download links of a user ("My downloads").
+func (s *DigitalService) ListMyDownloads(ctx context.Context, userID int) ([]domain.DownloadLink, error) {
+	links, err := s.repo.ListLinksByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.fillURLs(links)
+	return links, nil
+}
+
+// ResolveDownload checks the link, counts the download, and returns a short-lived S3 URL.
+func (s *DigitalService) ResolveDownload(ctx context.Context, token string) (string, error) {
+	if s.s3 == nil {
+		return "", ErrDigitalStorageDisabled
+	}
+
+	link, err := s.repo.FindLinkByToken(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if link.File == nil {
+		return "", domain.ErrProductFileNotFound
+	}
+
+	now := time.Now()
+	if !now.Before(link.ExpiresAt) {
+		return "", domain.ErrDownloadLinkExpired
+	}
+
+	ok, err := s.repo.ConsumeDownload(ctx, link.ID, now)
+	if err != nil {
+		return "", fmt.Errorf("consume download: %w", err)
+	}
+	if !ok {
+		return "", domain.ErrDownloadLimitReached
+	}
+
+	return s.s3.PresignGet(ctx, link.File.S3Key, link.File.FileName, presignTTL)
+}
+
+func (s *DigitalService) fillURLs(links []domain.DownloadLink) {
+	base := strings.TrimRight(s.cfg.APIURL, "/")
+	for i := range links {
+		links[i].URL = base + "/downloads/" + links[i].Token
+	}
+}
+
+func newDownloadToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate download token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,609 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+//go:embed document_fonts/*.ttf
+var documentFonts embed.FS
+
+var (
+	ErrDocumentNotFound   = errors.New("document not found")
+	ErrBankDetailsMissing = errors.New("company bank details are not configured")
+)
+
+// Order documents available for download.
+const (
+	DocumentPackingSlip = "packing-slip"
+	DocumentInvoice     = "invoice"
+	DocumentBankInvoice = "bank-invoice"
+)
+
+// Document is a rendered PDF file.
+type Document struct {
+	FileName string
+	Content  []byte
+}
+
+// DocumentService renders order documents as PDF: packing slips for the
+// warehouse, receipts for customers and bank-transfer invoices for legal entities.
+type DocumentService struct {
+	orderRepo       domain.OrderRepository
+	pickupPointRepo domain.PickupPointRepository
+	company         config.CompanyConfig
+	log             *zap.Logger
+	regular         []byte
+	bold            []byte
+}
+
+func NewDocumentService(orderRepo domain.OrderRepository, pickupPointRepo domain.PickupPointRepository, company config.CompanyConfig, log *zap.Logger) (*DocumentService, error) {
+	regular, err := documentFonts.ReadFile("document_fonts/DejaVuSans.ttf")
+	if err != nil {
+		return nil, fmt.Errorf("read document font: %w", err)
+	}
+	bold, err := documentFonts.ReadFile("document_fonts/DejaVuSans-Bold.ttf")
+	if err != nil {
+		return nil, fmt.Errorf("read document font: %w", err)
+	}
+
+	return &DocumentService{
+		orderRepo:       orderRepo,
+		pickupPointRepo: pickupPointRepo,
+		company:         company,
+		log:             log,
+		regular:         regular,
+		bold:            bold,
+	}, nil
+}
+
+// OrderDocument renders any document of an order for the admin panel.
+func (s *DocumentService) OrderDocument(ctx context.Context, orderID int, kind string) (*Document, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(ctx, order, kind)
+}
+
+// CustomerDocument renders a document for the customer, who reaches it by the
+// order number like the order page. Packing slips are internal.
+func (s *DocumentService) CustomerDocument(ctx context.Context, orderNumber, kind string) (*Document, error) {
+	if kind == DocumentPackingSlip {
+		return nil, ErrDocumentNotFound
+	}
+	order, err := s.orderRepo.FindByOrderNumber(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(ctx, order, kind)
+}
+
+// ConfirmationDocument renders the invoice attached to the order confirmation
+// email: the bank-transfer invoice for orders paid by invoice, otherwise the
+// customer invoice.
+func (s *DocumentService) ConfirmationDocument(ctx context.Context, order *domain.Order) (*Document, error) {
+	if order.PaymentMethod == domain.PaymentMethodInvoice && s.company.HasBankDetails() {
+		return s.render(ctx, order, DocumentBankInvoice)
+	}
+	return s.render(ctx, order, DocumentInvoice)
+}
+
+func (s *DocumentService) render(ctx context.Context, order *domain.Order, kind string) (*Document, error) {
+	var (
+		content []byte
+		err     error
+	)
+	switch kind {
+	case DocumentPackingSlip:
+		content, err = s.packingSlip(s.withPickupPoint(ctx, order))
+	case DocumentInvoice:
+		content, err = s.invoice(s.withPickupPoint(ctx, order))
+	case DocumentBankInvoice:
+		if !s.company.HasBankDetails() {
+			return nil, ErrBankDetailsMissing
+		}
+		content, err = s.bankInvoice(order)
+	default:
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render %s: %w", kind, err)
+	}
+
+	return &Document{
+		FileName: fmt.Sprintf("%s-%s.pdf", kind, order.OrderNumber),
+		Content:  content,
+	}, nil
+}
+
+// withPickupPoint loads the pickup point, which order queries do not preload.
+func (s *DocumentService) withPickupPoint(ctx context.Context, order *domain.Order) *domain.Order {
+	if order.PickupPointID == nil || order.PickupPoint != nil {
+		return order
+	}
+	point, err := s.pickupPointRepo.FindByID(ctx, *order.PickupPointID)
+	if err != nil {
+		s.log.Warn("failed to load pickup point for document",
+			zap.Error(err),
+			zap.String("orderNumber", order.OrderNumber),
+		)
+		return order
+	}
+	o := *order
+	o.PickupPoint = point
+	return &o
+}
+
+func (s *DocumentService) packingSlip(order *domain.Order) ([]byte, error) {
+	pdf := s.newPDF()
+
+	docTitle(pdf, fmt.Sprintf("Упаковочный лист к заказу № %s от %s", order.OrderNumber, order.CreatedAt.Format("02.01.2006")))
+	docField(pdf, "Получатель", order.CustomerName)
+	docField(pdf, "Телефон", order.CustomerPhone)
+	docField(pdf, "Доставка", deliveryMethodRu(order.DeliveryMethod))
+	docField(pdf, deliveryTargetLabel(order), deliveryTarget(order))
+	if order.TrackingNumber != nil {
+		docField(pdf, "Трек-номер", *order.TrackingNumber)
+	}
+	if order.Notes != nil && *order.Notes != "" {
+		docField(pdf, "Комментарий", *order.Notes)
+	}
+	pdf.Ln(4)
+
+	table := docTable{
+		headers: []string{"№", "Наименование", "Артикул", "Кол-во", "Собрано"},
+		widths:  []float64{10, 95, 40, 17, 18},
+		aligns:  []string{"C", "L", "L", "R", "C"},
+	}
+	table.header(pdf)
+	units := 0
+	for i, item := range order.Items {
+		table.row(pdf, fmt.Sprint(i+1), orderItemName(item), orderItemSKU(item), fmt.Sprint(item.Quantity), "")
+		units += item.Quantity
+	}
+	pdf.Ln(3)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Всего позиций: %d, единиц товара: %d", len(order.Items), units), "", 1, "L", false, 0, "")
+
+	pdf.Ln(12)
+	docSignatures(pdf, "Собрал", "", "Проверил", "")
+
+	return output(pdf)
+}
+
+func (s *DocumentService) invoice(order *domain.Order) ([]byte, error) {
+	pdf := s.newPDF()
+
+	docTitle(pdf, fmt.Sprintf("Заказ № %s от %s", order.OrderNumber, order.CreatedAt.Format("02.01.2006")))
+	seller := s.company.Name
+	if s.company.INN != "" {
+		seller += ", ИНН " + s.company.INN
+	}
+	docField(pdf, "Продавец", seller)
+	docField(pdf, "Покупатель", order.CustomerName)
+	docField(pdf, "Телефон", order.CustomerPhone)
+	if order.CustomerEmail != nil {
+		docField(pdf, "Email", *order.CustomerEmail)
+	}
+	docField(pdf, "Доставка", deliveryMethodRu(order.DeliveryMethod))
+	if target := deliveryTarget(order); target != "" {
+		docField(pdf, deliveryTargetLabel(order), target)
+	}
+	docField(pdf, "Оплата", paymentMethodRu(order.PaymentMethod))
+	pdf.Ln(4)
+
+	table := docTable{
+		headers: []string{"№", "Наименование", "Кол-во", "Цена, руб.", "Сумма, руб."},
+		widths:  []float64{10, 100, 18, 26, 26},
+		aligns:  []string{"C", "L", "R", "R", "R"},
+	}
+	table.header(pdf)
+	for i, item := range order.Items {
+		table.row(pdf, fmt.Sprint(i+1), orderItemName(item), fmt.Sprint(item.Quantity),
+			formatAmount(item.UnitPrice), formatAmount(item.TotalPrice))
+	}
+	pdf.Ln(3)
+
+	docTotal(pdf, "Товары:", formatAmount(order.Subtotal), false)
+	if order.DiscountAmount > 0 {
+		label := "Скидка:"
+		if order.PromoCode != nil {
+			label = fmt.Sprintf("Скидка по промокоду %s:", *order.PromoCode)
+		}
+		docTotal(pdf, label, "-"+formatAmount(order.DiscountAmount), false)
+	}
+	if order.BonusDiscount > 0 {
+		docTotal(pdf, "Оплачено бонусами:", "-"+formatAmount(order.BonusDiscount), false)
+	}
+	if order.DeliveryCost > 0 {
+		docTotal(pdf, "Доставка:", formatAmount(order.DeliveryCost), false)
+	}
+	docTotal(pdf, "Итого:", formatAmount(order.TotalPrice), true)
+	if order.IsPaid {
+		docTotal(pdf, "Оплачено:", formatAmount(order.PaidAmount), false)
+	} else if due := order.TotalPrice - order.PaidAmount; due > 0 {
+		docTotal(pdf, "К оплате:", formatAmount(due), true)
+	}
+
+	pdf.Ln(8)
+	pdf.SetFont("dejavu", "", 8)
+	footer := "Спасибо за заказ!"
+	if s.company.Phone != "" || s.company.Email != "" {
+		footer += " Вопросы по заказу: " + strings.Trim(s.company.Phone+", "+s.company.Email, ", ")
+	}
+	pdf.MultiCell(0, 4, footer, "", "L", false)
+
+	return output(pdf)
+}
+
+// bankInvoice renders a "счёт на оплату" in the usual Russian layout: bank
+// details box, parties, items, amount in words and signatures.
+func (s *DocumentService) bankInvoice(order *domain.Order) ([]byte, error) {
+	c := s.company
+	pdf := s.newPDF()
+
+	// Bank details box.
+	pdf.SetFont("dejavu", "", 9)
+	x := pdf.GetX()
+	pdf.CellFormat(100, 10, c.BankName, "LTR", 0, "L", false, 0, "")
+	pdf.CellFormat(15, 5, "БИК", "LTR", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 5, c.BIK, "LTR", 1, "L", false, 0, "")
+	pdf.SetX(x + 100)
+	pdf.CellFormat(15, 5, "Сч. №", "LR", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 5, c.CorrAccount, "LR", 1, "L", false, 0, "")
+	pdf.SetFontSize(7)
+	pdf.CellFormat(100, 4, "Банк получателя", "LBR", 0, "L", false, 0, "")
+	pdf.CellFormat(15, 4, "", "LBR", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 4, "", "LBR", 1, "L", false, 0, "")
+	pdf.SetFontSize(9)
+	pdf.CellFormat(50, 5, "ИНН "+c.INN, "1", 0, "L", false, 0, "")
+	pdf.CellFormat(50, 5, "КПП "+c.KPP, "1", 0, "L", false, 0, "")
+	pdf.CellFormat(15, 5, "Сч. №", "LTR", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 5, c.Account, "LTR", 1, "L", false, 0, "")
+	pdf.CellFormat(100, 10, fitText(pdf, c.Name, 98), "LR", 0, "L", false, 0, "")
+	pdf.CellFormat(15, 10, "", "LR", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 10, "", "LR", 1, "L", false, 0, "")
+	pdf.SetFontSize(7)
+	pdf.CellFormat(100, 4, "Получатель", "LBR", 0, "L", false, 0, "")
+	pdf.CellFormat(15, 4, "", "LBR", 0, "L", false, 0, "")
+	pdf.CellFormat(65, 4, "", "LBR", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	docTitle(pdf, fmt.Sprintf("Счёт на оплату № %s от %s", order.OrderNumber, order.CreatedAt.Format("02.01.2006")))
+	pdf.Line(pdf.GetX(), pdf.GetY(), pdf.GetX()+180, pdf.GetY())
+	pdf.Ln(2)
+
+	docField(pdf, "Поставщик", joinRequisites(c.Name, "ИНН "+c.INN, prefixed("КПП ", c.KPP), c.Address, prefixed("тел.: ", c.Phone)))
+	docField(pdf, "Покупатель", buyerRequisites(order))
+	docField(pdf, "Основание", "Заказ № "+order.OrderNumber)
+	pdf.Ln(4)
+
+	table := docTable{
+		headers: []string{"№", "Товары (работы, услуги)", "Кол-во", "Ед.", "Цена", "Сумма"},
+		widths:  []float64{10, 92, 16, 12, 25, 25},
+		aligns:  []string{"C", "L", "R", "C", "R", "R"},
+	}
+	table.header(pdf)
+	lines := 0
+	for _, item := range order.Items {
+		lines++
+		table.row(pdf, fmt.Sprint(lines), orderItemName(item), fmt.Sprint(item.Quantity), "шт",
+			formatAmount(item.UnitPrice), formatAmount(item.TotalPrice))
+	}
+	if order.DeliveryCost > 0 {
+		lines++
+		table.row(pdf, fmt.Sprint(lines), "Доставка: "+deliveryMethodRu(order.DeliveryMethod), "1", "усл",
+			formatAmount(order.DeliveryCost), formatAmount(order.DeliveryCost))
+	}
+	pdf.Ln(2)
+
+	docTotal(pdf, "Итого:", formatAmount(order.Subtotal+order.DeliveryCost), true)
+	if discount := order.DiscountAmount + order.BonusDiscount; discount > 0 {
+		docTotal(pdf, "Скидка:", "-"+formatAmount(discount), true)
+	}
+	if c.VATRate > 0 {
+		vat := math.Round(order.TotalPrice*float64(c.VATRate)/float64(100+c.VATRate)*100) / 100
+		docTotal(pdf, fmt.Sprintf("В том числе НДС (%d%%):", c.VATRate), formatAmount(vat), true)
+	} else {
+		docTotal(pdf, "Без налога (НДС)", "-", true)
+	}
+	docTotal(pdf, "Всего к оплате:", formatAmount(order.TotalPrice), true)
+	if order.PaidAmount > 0 && !order.IsPaid {
+		docTotal(pdf, "Оплачено ранее:", formatAmount(order.PaidAmount), false)
+		docTotal(pdf, "Остаток к оплате:", formatAmount(order.TotalPrice-order.PaidAmount), true)
+	}
+	pdf.Ln(2)
+
+	pdf.SetFont("dejavu", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Всего наименований %d, на сумму %s руб.", lines, formatAmount(order.TotalPrice)), "", 1, "L", false, 0, "")
+	pdf.SetFont("dejavu", "B", 10)
+	pdf.MultiCell(0, 6, amountInWords(order.TotalPrice), "", "L", false)
+	pdf.Ln(3)
+
+	pdf.SetFont("dejavu", "", 9)
+	due := order.CreatedAt.AddDate(0, 0, c.InvoiceDays)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Оплатить не позднее %s. В назначении платежа укажите номер счёта. "+
+		"Оплата данного счёта означает согласие с условиями поставки товара. "+
+		"Товар отпускается по факту поступления денег на расчётный счёт поставщика.", due.Format("02.01.2006")), "", "L", false)
+	pdf.Line(pdf.GetX(), pdf.GetY()+2, pdf.GetX()+180, pdf.GetY()+2)
+	pdf.Ln(12)
+
+	docSignatures(pdf, "Руководитель", c.Director, "Бухгалтер", c.Accountant)
+
+	return output(pdf)
+}
+
+// buyerRequisites describes the payer of a bank-transfer invoice: the
+// company of a B2B order, otherwise the customer.
+func buyerRequisites(order *domain.Order) string {
+	if c := order.Company; c != nil {
+		kpp := ""
+		if c.KPP != nil {
+			kpp = "КПП " + *c.KPP
+		}
+		return joinRequisites(c.LegalName, "ИНН "+c.INN, kpp, c.LegalAddress)
+	}
+	return joinRequisites(order.CustomerName, prefixed("тел.: ", order.CustomerPhone))
+}
+
+func (s *DocumentService) newPDF() *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("dejavu", "", s.regular)
+	pdf.AddUTF8FontFromBytes("dejavu", "B", s.bold)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetCreator(s.company.Name, true)
+	pdf.AddPage()
+	pdf.SetFont("dejavu", "", 10)
+	return pdf
+}
+
+func output(pdf *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func docTitle(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont("dejavu", "B", 14)
+	pdf.MultiCell(0, 8, title, "", "L", false)
+	pdf.Ln(3)
+	pdf.SetFont("dejavu", "", 10)
+}
+
+// docField prints a "label: value" line; long values wrap under the value column.
+func docField(pdf *fpdf.Fpdf, label, value string) {
+	if value == "" {
+		return
+	}
+	pdf.SetFont("dejavu", "", 10)
+	pdf.CellFormat(35, 6, label+":", "", 0, "L", false, 0, "")
+	pdf.SetFont("dejavu", "B", 10)
+	pdf.MultiCell(0, 6, value, "", "L", false)
+	pdf.SetFont("dejavu", "", 10)
+}
+
+// docTotal prints a right-aligned total line under the items table.
+func docTotal(pdf *fpdf.Fpdf, label, value string, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	pdf.SetFont("dejavu", style, 10)
+	pdf.CellFormat(150, 6, label, "", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 6, value, "", 1, "R", false, 0, "")
+	pdf.SetFont("dejavu", "", 10)
+}
+
+func docSignatures(pdf *fpdf.Fpdf, leftRole, leftName, rightRole, rightName string) {
+	pdf.SetFont("dejavu", "B", 10)
+	pdf.CellFormat(28, 6, leftRole, "", 0, "L", false, 0, "")
+	pdf.SetFont("dejavu", "", 10)
+	pdf.CellFormat(62, 6, leftName, "B", 0, "R", false, 0, "")
+	pdf.CellFormat(4, 6, "", "", 0, "L", false, 0, "")
+	pdf.SetFont("dejavu", "B", 10)
+	pdf.CellFormat(24, 6, rightRole, "", 0, "L", false, 0, "")
+	pdf.SetFont("dejavu", "", 10)
+	pdf.CellFormat(62, 6, rightName, "B", 1, "R", false, 0, "")
+}
+
+// docTable draws a bordered table with a bold header. Cells are kept to one
+// line: long values are shortened to the column width.
+type docTable struct {
+	headers []string
+	widths  []float64
+	aligns  []string
+}
+
+func (t docTable) header(pdf *fpdf.Fpdf) {
+	pdf.SetFont("dejavu", "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	for i, h := range t.headers {
+		pdf.CellFormat(t.widths[i], 7, h, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("dejavu", "", 9)
+}
+
+func (t docTable) row(pdf *fpdf.Fpdf, cells ...string) {
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+7 > pageHeight-bottom {
+		pdf.AddPage()
+		t.header(pdf)
+	}
+	for i, cell := range cells {
+		pdf.CellFormat(t.widths[i], 7, fitText(pdf, cell, t.widths[i]-2), "1", 0, t.aligns[i], false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// fitText shortens text with an ellipsis to fit the given width in the current font.
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+func deliveryTargetLabel(order *domain.Order) string {
+	if order.PickupPointID != nil {
+		return "Пункт выдачи"
+	}
+	return "Адрес"
+}
+
+func deliveryTarget(order *domain.Order) string {
+	if p := order.PickupPoint; p != nil {
+		return joinRequisites(p.Name, p.City, p.Address)
+	}
+	if order.DeliveryAddress != nil {
+		return *order.DeliveryAddress
+	}
+	return ""
+}
+
+func orderItemSKU(item domain.OrderItem) string {
+	if item.Product != nil && item.Product.SKU != nil {
+		return *item.Product.SKU
+	}
+	return ""
+}
+
+func joinRequisites(parts ...string) string {
+	nonEmpty := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
+
+func prefixed(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + value
+}
+
+// formatAmount renders money as "12 345,60" for documents.
+func formatAmount(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	digits := fmt.Sprint(cents / 100)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(d)
+	}
+	sign := ""
+	if amount < 0 && cents > 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, b.String(), cents%100)
+}
+
+var (
+	wordsHundreds = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+	wordsTens     = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	wordsTeens    = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	wordsOnes     = []string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	wordsOnesFem  = []string{"", "одна", "две", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+)
+
+var wordsScales = []struct {
+	value          int64
+	one, few, many string
+	feminine       bool
+}{
+	{1_000_000_000, "миллиард", "миллиарда", "миллиардов", false},
+	{1_000_000, "миллион", "миллиона", "миллионов", false},
+	{1_000, "тысяча", "тысячи", "тысяч", true},
+}
+
+// amountInWords spells a ruble amount for bank-transfer invoices:
+// "Одна тысяча двести рублей 50 копеек".
+func amountInWords(amount float64) string {
+	cents := int64(math.Round(amount * 100))
+	rubles, kopecks := cents/100, cents%100
+
+	var words []string
+	rest := rubles
+	for _, sc := range wordsScales {
+		if n := rest / sc.value; n > 0 {
+			words = append(words, triadWords(n, sc.feminine)...)
+			words = append(words, pluralRu(n, sc.one, sc.few, sc.many))
+			rest %= sc.value
+		}
+	}
+	words = append(words, triadWords(rest, false)...)
+	if len(words) == 0 {
+		words = []string{"ноль"}
+	}
+
+	text := strings.Join(words, " ")
+	r := []rune(text)
+	text = strings.ToUpper(string(r[0])) + string(r[1:])
+	return fmt.Sprintf("%s %s %02d %s", text, pluralRu(rubles, "рубль", "рубля", "рублей"),
+		kopecks, pluralRu(kopecks, "копейка", "копейки", "копеек"))
+}
+
+// triadWords spells a number below 1000.
+func triadWords(n int64, feminine bool) []string {
+	var words []string
+	if h := n / 100; h > 0 {
+		words = append(words, wordsHundreds[h])
+	}
+	n %= 100
+	switch {
+	case n >= 10 && n < 20:
+		words = append(words, wordsTeens[n-10])
+	default:
+		if t := n / 10; t > 0 {
+			words = append(words, wordsTens[t])
+		}
+		if o := n % 10; o > 0 {
+			if feminine {
+				words = append(words, wordsOnesFem[o])
+			} else {
+				words = append(words, wordsOnes[o])
+			}
+		}
+	}
+	return words
+}
+
+// pluralRu picks the Russian plural form for n: 1 рубль, 2 рубля, 5 рублей.
+func pluralRu(n int64, one, few, many string) string {
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	default:
+		return many
+	}
+}
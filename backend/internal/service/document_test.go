@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestAmountInWords(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{0, "Ноль рублей 00 копеек"},
+		{0.01, "Ноль рублей 01 копейка"},
+		{1, "Один рубль 00 копеек"},
+		{2.02, "Два рубля 02 копейки"},
+		{11.11, "Одиннадцать рублей 11 копеек"},
+		{21.21, "Двадцать один рубль 21 копейка"},
+		{112, "Сто двенадцать рублей 00 копеек"},
+		{1200.5, "Одна тысяча двести рублей 50 копеек"},
+		{2022.99, "Две тысячи двадцать два рубля 99 копеек"},
+		{15000, "Пятнадцать тысяч рублей 00 копеек"},
+		{1_000_001, "Один миллион один рубль 00 копеек"},
+		{3_452_814.1, "Три миллиона четыреста пятьдесят две тысячи восемьсот четырнадцать рублей 10 копеек"},
+	}
+	for _, tt := range tests {
+		if got := amountInWords(tt.amount); got != tt.want {
+			t.Errorf("amountInWords(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{0, "0,00"},
+		{12345.6, "12 345,60"},
+		{1_000_000, "1 000 000,00"},
+		{-99.5, "-99,50"},
+	}
+	for _, tt := range tests {
+		if got := formatAmount(tt.amount); got != tt.want {
+			t.Errorf("formatAmount(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"embed"
 	"encoding/base64"
 	"fmt"
 	"html/template"
 	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -22,6 +26,7 @@ type EmailService struct {
 	cfg       config.SMTPConfig
 	log       *zap.Logger
 	templates *template.Template
+	documents *DocumentService
 }
 
 func NewEmailService(cfg config.SMTPConfig, log *zap.Logger) (*EmailService, error) {
@@ -37,6 +42,18 @@ func NewEmailService(cfg config.SMTPConfig, log *zap.Logger) (*EmailService, err
 	}, nil
 }
 
+// SetDocumentService enables the PDF invoice attached to order confirmations.
+func (s *EmailService) SetDocumentService(ds *DocumentService) {
+	s.documents = ds
+}
+
+// emailAttachment is a file attached to an email.
+type emailAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
 type orderEmailData struct {
 	OrderNumber    string
 	CustomerName   string
@@ -51,6 +68,7 @@ type orderEmailData struct {
 	DeliveryMethod string
 	PaymentMethod  string
 	TrackingNumber string
+	CancelReason   string
 	Year           int
 }
 
@@ -73,8 +91,22 @@ func (s *EmailService) SendOrderCreated(order *domain.Order) {
 		return
 	}
 
+	var attachments []emailAttachment
+	if s.documents != nil {
+		doc, err := s.documents.ConfirmationDocument(context.Background(), order)
+		if err != nil {
+			s.log.Warn("failed to render order invoice", zap.Error(err), zap.String("order", order.OrderNumber))
+		} else {
+			attachments = append(attachments, emailAttachment{
+				FileName:    doc.FileName,
+				ContentType: "application/pdf",
+				Content:     doc.Content,
+			})
+		}
+	}
+
 	subject := fmt.Sprintf("Заказ #%s оформлен — АВАНГАРД", order.OrderNumber)
-	if err := s.send(*order.CustomerEmail, subject, buf.String()); err != nil {
+	if err := s.send(*order.CustomerEmail, subject, buf.String(), attachments...); err != nil {
 		s.log.Warn("failed to send order_created email",
 			zap.Error(err),
 			zap.String("to", *order.CustomerEmail),
@@ -142,6 +174,143 @@ func (s *EmailService) SendVerificationCode(to, code string) {
 	s.log.Info("verification email sent", zap.String("to", to))
 }
 
+type digitalFileData struct {
+	ProductName string
+	FileName    string
+	URL         string
+}
+
+// SendDigitalDownloads emails download links for a paid digital order.
+func (s *EmailService) SendDigitalDownloads(order *domain.Order, links []domain.DownloadLink) {
+	if order.CustomerEmail == nil || *order.CustomerEmail == "" || len(links) == 0 {
+		return
+	}
+
+	data := struct {
+		OrderNumber  string
+		CustomerName string
+		Files        []digitalFileData
+		MaxDownloads int
+		ExpiresAt    string
+		Year         int
+	}{
+		OrderNumber:  order.OrderNumber,
+		CustomerName: order.CustomerName,
+		MaxDownloads: links[0].MaxDownloads,
+		ExpiresAt:    links[0].ExpiresAt.Format("02.01.2006 15:04"),
+		Year:         2026,
+	}
+	for _, l := range links {
+		f := digitalFileData{URL: l.URL}
+		if l.Product != nil {
+			f.ProductName = l.Product.Name
+		}
+		if l.File != nil {
+			f.FileName = l.File.FileName
+		}
+		data.Files = append(data.Files, f)
+	}
+
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "digital_downloads.html", data); err != nil {
+		s.log.Warn("failed to render digital_downloads email", zap.Error(err))
+		return
+	}
+
+	subject := fmt.Sprintf("Файлы заказа #%s — АВАНГАРД", order.OrderNumber)
+	if err := s.send(*order.CustomerEmail, subject, buf.String()); err != nil {
+		s.log.Warn("failed to send digital_downloads email",
+			zap.Error(err),
+			zap.String("to", *order.CustomerEmail),
+			zap.String("order", order.OrderNumber),
+		)
+		return
+	}
+
+	s.log.Info("digital_downloads email sent",
+		zap.String("to", *order.CustomerEmail),
+		zap.String("order", order.OrderNumber),
+	)
+}
+
+// SendBackInStock notifies a subscriber that a product is available again.
+// Returns the error so the caller can keep the subscription for a retry.
+func (s *EmailService) SendBackInStock(to string, product *domain.Product, productURL string) error {
+	data := struct {
+		ProductName string
+		Price       string
+		ProductURL  string
+		Year        int
+	}{
+		ProductName: product.Name,
+		Price:       formatPrice(product.Price),
+		ProductURL:  productURL,
+		Year:        2026,
+	}
+
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "back_in_stock.html", data); err != nil {
+		return fmt.Errorf("render back_in_stock email: %w", err)
+	}
+
+	subject := fmt.Sprintf("%s снова в наличии — АВАНГАРД", product.Name)
+	if err := s.send(to, subject, buf.String()); err != nil {
+		return err
+	}
+
+	s.log.Info("back_in_stock email sent", zap.String("to", to), zap.Int("productId", product.ID))
+	return nil
+}
+
+// SendCartReminder reminds a customer about the products left in the cart.
+// Returns the error so the caller does not record an undelivered reminder.
+func (s *EmailService) SendCartReminder(to, name string, msg *domain.CartReminderMessage) error {
+	data := struct {
+		CustomerName   string
+		Items          []orderItemData
+		Total          string
+		CartURL        string
+		UnsubscribeURL string
+		PromoCode      string
+		PromoDiscount  string
+		PromoExpires   string
+		Year           int
+	}{
+		CustomerName:   name,
+		Total:          formatPrice(msg.Total),
+		CartURL:        msg.CartURL,
+		UnsubscribeURL: msg.UnsubscribeURL,
+		Year:           2026,
+	}
+	for _, item := range msg.Items {
+		data.Items = append(data.Items, orderItemData{
+			Name:      item.Product.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: formatPrice(item.Product.Price),
+			Total:     formatPrice(item.Product.Price * float64(item.Quantity)),
+		})
+	}
+	if p := msg.Promo; p != nil {
+		data.PromoCode = p.Code
+		data.PromoDiscount = formatDiscount(p.DiscountType, p.DiscountValue)
+		if p.ExpiresAt != nil {
+			data.PromoExpires = p.ExpiresAt.Format("02.01.2006 15:04")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, "cart_reminder.html", data); err != nil {
+		return fmt.Errorf("render cart_reminder email: %w", err)
+	}
+
+	if err := s.send(to, "Товары ждут вас в корзине — АВАНГАРД", buf.String()); err != nil {
+		return err
+	}
+
+	s.log.Info("cart_reminder email sent", zap.String("to", to))
+	return nil
+}
+
 func (s *EmailService) buildOrderData(order *domain.Order) orderEmailData {
 	data := orderEmailData{
 		OrderNumber:    order.OrderNumber,
@@ -161,6 +330,9 @@ func (s *EmailService) buildOrderData(order *domain.Order) orderEmailData {
 	if order.TrackingNumber != nil {
 		data.TrackingNumber = *order.TrackingNumber
 	}
+	if order.CancelReason != nil {
+		data.CancelReason = *order.CancelReason
+	}
 
 	for _, item := range order.Items {
 		data.Items = append(data.Items, orderItemData{
@@ -174,17 +346,24 @@ func (s *EmailService) buildOrderData(order *domain.Order) orderEmailData {
 	return data
 }
 
-func (s *EmailService) send(to, subject, htmlBody string) error {
+func (s *EmailService) send(to, subject, htmlBody string, attachments ...emailAttachment) error {
 	from := s.cfg.FromEmail
 	fromHeader := fmt.Sprintf("%s <%s>", s.cfg.FromName, from)
 
-	msg := "MIME-Version: 1.0\r\n" +
-		"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
+	headers := "MIME-Version: 1.0\r\n" +
 		fmt.Sprintf("From: %s\r\n", fromHeader) +
 		fmt.Sprintf("To: %s\r\n", to) +
-		fmt.Sprintf("Subject: =?UTF-8?B?%s?=\r\n", base64Encode(subject)) +
-		"\r\n" +
-		htmlBody
+		fmt.Sprintf("Subject: =?UTF-8?B?%s?=\r\n", base64Encode(subject))
+
+	var msg string
+	if len(attachments) == 0 {
+		msg = headers +
+			"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
+			"\r\n" +
+			htmlBody
+	} else {
+		msg = headers + multipartBody(htmlBody, attachments)
+	}
 
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 	auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
@@ -233,6 +412,34 @@ func (s *EmailService) send(to, subject, htmlBody string) error {
 	return client.Quit()
 }
 
+// multipartBody builds a multipart/mixed body with the HTML part and base64
+// encoded attachments, starting with its Content-Type header.
+func multipartBody(htmlBody string, attachments []emailAttachment) string {
+	boundary := "avangard-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n\r\n")
+	b.WriteString(htmlBody)
+	b.WriteString("\r\n")
+
+	for _, a := range attachments {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; name=\"%s\"\r\n", a.ContentType, a.FileName)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=\"%s\"\r\n\r\n", a.FileName)
+		encoded := base64.StdEncoding.EncodeToString(a.Content)
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.String()
+}
+
 func statusToRussian(status string) string {
 	switch status {
 	case "new":
@@ -260,6 +467,8 @@ func deliveryMethodRu(method string) string {
 		return "Курьером"
 	case "post":
 		return "Почтой"
+	case domain.DeliveryMethodDigital:
+		return "Скачивание файлов"
 	default:
 		return method
 	}
@@ -273,6 +482,8 @@ func paymentMethodRu(method string) string {
 		return "Наличными"
 	case "transfer":
 		return "Перевод"
+	case domain.PaymentMethodInvoice:
+		return "По счёту (безналичный расчёт)"
 	default:
 		return method
 	}
@@ -285,6 +496,14 @@ func formatPrice(price float64) string {
 	return fmt.Sprintf("%.2f ₽", price)
 }
 
+// formatDiscount renders a promo discount as "10%" or "500 ₽".
+func formatDiscount(discountType string, value float64) string {
+	if discountType == "percent" {
+		return fmt.Sprintf("%g%%", value)
+	}
+	return formatPrice(value)
+}
+
 func base64Encode(s string) string {
 	return base64.StdEncoding.EncodeToString([]byte(s))
 }
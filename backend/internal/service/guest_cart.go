@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+const guestCartKeyPrefix = "cart:guest:"
+
+// guestCart is the Redis representation of an anonymous visitor's cart.
+// Item IDs are local to the cart so the /cart/items/:id routes work for guests too.
+type guestCart struct {
+	Items  []guestCartItem `json:"items"`
+	NextID int             `json:"nextId"`
+}
+
+type guestCartItem struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"productId"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (g *guestCart) find(pred func(*guestCartItem) bool) *guestCartItem {
+	for i := range g.Items {
+		if pred(&g.Items[i]) {
+			return &g.Items[i]
+		}
+	}
+	return nil
+}
+
+// SetGuestCarts enables carts for anonymous visitors, kept in Redis and
+// identified by a signed token.
+func (s *CartService) SetGuestCarts(store *cache.Store, cfg config.CartConfig) {
+	s.guestStore = store
+	s.guestCfg = cfg
+}
+
+// GuestCartsEnabled reports whether anonymous visitors can use the cart.
+func (s *CartService) GuestCartsEnabled() bool {
+	return s.guestStore != nil
+}
+
+// newGuestToken returns "<id>.<signature>" for a fresh guest cart.
+func (s *CartService) newGuestToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate cart token: %w", err)
+	}
+	id := hex.EncodeToString(b)
+	return id + "." + s.signGuestID(id), nil
+}
+
+func (s *CartService) signGuestID(id string) string {
+	return hex.EncodeToString(hmacSHA256([]byte(s.guestCfg.TokenSecret), []byte("guest-cart:"+id)))
+}
+
+// guestKey verifies the token signature and returns the Redis key of the cart.
+func (s *CartService) guestKey(token string) (string, error) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || id == "" || !hmac.Equal([]byte(sig), []byte(s.signGuestID(id))) {
+		return "", domain.ErrInvalidCartToken
+	}
+	return guestCartKeyPrefix + id, nil
+}
+
+func (s *CartService) loadGuest(ctx context.Context, token string) (*guestCart, string, error) {
+	key, err := s.guestKey(token)
+	if err != nil {
+		return nil, "", err
+	}
+	var cart guestCart
+	if _, err := s.guestStore.Get(ctx, key, &cart); err != nil {
+		return nil, "", fmt.Errorf("load guest cart: %w", err)
+	}
+	return &cart, key, nil
+}
+
+// saveGuest stores the cart, restarting its TTL: the cart expires after a
+// period of inactivity, not after creation.
+func (s *CartService) saveGuest(ctx context.Context, key string, cart *guestCart) error {
+	if len(cart.Items) == 0 {
+		return s.guestStore.Delete(ctx, key)
+	}
+	if err := s.guestStore.Set(ctx, key, cart, s.guestCfg.GuestTTL); err != nil {
+		return fmt.Errorf("save guest cart: %w", err)
+	}
+	return nil
+}
+
+// GetGuestCart returns the cart of an anonymous visitor. An expired cart is empty.
+func (s *CartService) GetGuestCart(ctx context.Context, token string) (*domain.Cart, error) {
+	if token == "" {
+		return s.buildCart(ctx, nil, nil)
+	}
+	cart, _, err := s.loadGuest(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildGuestCart(ctx, token, cart)
+}
+
+// AddGuestItem adds a product to a guest cart. An empty token starts a new
+// cart; the returned cart carries the token to use from then on.
+func (s *CartService) AddGuestItem(ctx context.Context, token string, input AddToCartInput) (*domain.Cart, error) {
+	product, err := s.productRepo.FindByID(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsActive {
+		return nil, domain.ErrProductInactive
+	}
+
+	if token == "" {
+		if token, err = s.newGuestToken(); err != nil {
+			return nil, err
+		}
+	}
+	cart, key, err := s.loadGuest(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if existing := cart.find(func(i *guestCartItem) bool { return i.ProductID == input.ProductID }); existing != nil {
+		newQty := existing.Quantity + input.Quantity
+		if _, _, ok := product.StockSplit(newQty); !ok {
+			return nil, domain.ErrInsufficientStock
+		}
+		existing.Quantity = newQty
+		existing.UpdatedAt = now
+	} else {
+		if _, _, ok := product.StockSplit(input.Quantity); !ok {
+			return nil, domain.ErrInsufficientStock
+		}
+		cart.NextID++
+		cart.Items = append(cart.Items, guestCartItem{
+			ID:        cart.NextID,
+			ProductID: input.ProductID,
+			Quantity:  input.Quantity,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.saveGuest(ctx, key, cart); err != nil {
+		return nil, err
+	}
+	return s.buildGuestCart(ctx, token, cart)
+}
+
+// UpdateGuestItem sets the quantity of a guest cart item.
+func (s *CartService) UpdateGuestItem(ctx context.Context, token string, itemID int, input UpdateCartItemInput) (*domain.Cart, error) {
+	cart, key, err := s.loadGuest(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	item := cart.find(func(i *guestCartItem) bool { return i.ID == itemID })
+	if item == nil {
+		return nil, domain.ErrCartItemNotFound
+	}
+
+	product, err := s.productRepo.FindByID(ctx, item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, ok := product.StockSplit(input.Quantity); !ok {
+		return nil, domain.ErrInsufficientStock
+	}
+
+	item.Quantity = input.Quantity
+	item.UpdatedAt = time.Now()
+	if err := s.saveGuest(ctx, key, cart); err != nil {
+		return nil, err
+	}
+	return s.buildGuestCart(ctx, token, cart)
+}
+
+// RemoveGuestItem removes an item from a guest cart.
+func (s *CartService) RemoveGuestItem(ctx context.Context, token string, itemID int) (*domain.Cart, error) {
+	cart, key, err := s.loadGuest(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	n := len(cart.Items)
+	cart.Items = slices.DeleteFunc(cart.Items, func(i guestCartItem) bool { return i.ID == itemID })
+	if len(cart.Items) == n {
+		return nil, domain.ErrCartItemNotFound
+	}
+
+	if err := s.saveGuest(ctx, key, cart); err != nil {
+		return nil, err
+	}
+	return s.buildGuestCart(ctx, token, cart)
+}
+
+// ClearGuest empties a guest cart.
+func (s *CartService) ClearGuest(ctx context.Context, token string) error {
+	key, err := s.guestKey(token)
+	if err != nil {
+		return err
+	}
+	if err := s.guestStore.Delete(ctx, key); err != nil {
+		return fmt.Errorf("clear guest cart: %w", err)
+	}
+	return nil
+}
+
+// buildGuestCart resolves products of a guest cart. Items whose product was
+// deleted or deactivated since are left out.
+func (s *CartService) buildGuestCart(ctx context.Context, token string, cart *guestCart) (*domain.Cart, error) {
+	products, err := s.guestProducts(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.CartItem, 0, len(cart.Items))
+	for _, gi := range cart.Items {
+		product, ok := products[gi.ProductID]
+		if !ok || !product.IsActive {
+			continue
+		}
+		items = append(items, domain.CartItem{
+			ID:        gi.ID,
+			ProductID: gi.ProductID,
+			Quantity:  gi.Quantity,
+			CreatedAt: gi.CreatedAt,
+			UpdatedAt: gi.UpdatedAt,
+			Product:   product,
+		})
+	}
+
+	result, err := s.buildCart(ctx, nil, items)
+	if err != nil {
+		return nil, err
+	}
+	result.Token = token
+	return result, nil
+}
+
+func (s *CartService) guestProducts(ctx context.Context, cart *guestCart) (map[int]domain.Product, error) {
+	ids := make([]int, len(cart.Items))
+	for i, item := range cart.Items {
+		ids[i] = item.ProductID
+	}
+	found, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("find cart products: %w", err)
+	}
+	products := make(map[int]domain.Product, len(found))
+	for _, p := range found {
+		products[p.ID] = p
+	}
+	return products, nil
+}
+
+// MergeGuestCart moves a guest cart into the user's cart after login.
+// Quantities of products already in the user's cart are added up and cut
+// down to what is in stock; unavailable products are dropped.
+func (s *CartService) MergeGuestCart(ctx context.Context, userID int, token string) error {
+	if s.guestStore == nil || token == "" {
+		return nil
+	}
+	cart, key, err := s.loadGuest(ctx, token)
+	if err != nil {
+		return err
+	}
+	if len(cart.Items) == 0 {
+		return nil
+	}
+
+	products, err := s.guestProducts(ctx, cart)
+	if err != nil {
+		return err
+	}
+
+	merged := 0
+	for _, gi := range cart.Items {
+		product, ok := products[gi.ProductID]
+		if !ok || !product.IsActive {
+			continue
+		}
+
+		existing, err := s.cartRepo.FindItem(ctx, userID, gi.ProductID)
+		if err != nil && !errors.Is(err, domain.ErrCartItemNotFound) {
+			return fmt.Errorf("find cart item: %w", err)
+		}
+		current := 0
+		if existing != nil {
+			current = existing.Quantity
+		}
+
+		qty := reconcileQuantity(&product, current+gi.Quantity)
+		if qty < current+gi.Quantity {
+			s.log.Info("guest cart quantity reduced to stock",
+				zap.Int("userID", userID), zap.Int("productID", gi.ProductID),
+				zap.Int("requested", current+gi.Quantity), zap.Int("quantity", qty))
+		}
+		if qty <= current {
+			continue
+		}
+
+		if existing != nil {
+			err = s.cartRepo.UpdateQuantity(ctx, existing.ID, userID, qty)
+		} else {
+			err = s.cartRepo.AddItem(ctx, &domain.CartItem{UserID: userID, ProductID: gi.ProductID, Quantity: qty})
+		}
+		if err != nil {
+			return fmt.Errorf("merge cart item: %w", err)
+		}
+		merged++
+	}
+
+	if err := s.guestStore.Delete(ctx, key); err != nil {
+		s.log.Warn("failed to delete merged guest cart", zap.Error(err))
+	}
+	s.log.Info("guest cart merged", zap.Int("userID", userID), zap.Int("items", merged))
+	return nil
+}
+
+// reconcileQuantity returns the largest quantity up to want that can be ordered.
+func reconcileQuantity(p *domain.Product, want int) int {
+	if _, _, ok := p.StockSplit(want); ok {
+		return want
+	}
+	return max(min(p.StockQuantity, want), 0)
+}
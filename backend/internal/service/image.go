@@ -10,122 +10,138 @@ import (
 	"io"
 	"path"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/disintegration/imaging"
+	"github.com/gen2brain/webp" // WebP encoder; also registers the WebP decoder for image.Decode
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	_ "golang.org/x/image/webp" // register WebP decoder for image.Decode
 
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/config"
 	"github.com/brown/3d-print-shop/internal/domain"
 	"github.com/brown/3d-print-shop/internal/storage"
 )
 
 const (
-	maxImageSize   = 10 << 20 // 10 MB
-	maxOriginalPx  = 2000
-	largePx        = 1200
-	mediumPx       = 800
-	thumbnailPx    = 300
+	maxImageSize = 10 << 20 // 10 MB
+	// maxImagePixels rejects decompression bombs before they reach a worker.
+	maxImagePixels = 50_000_000
+
+	// Widths behind the legacy urlLarge/urlMedium/urlThumbnail fields. They are
+	// filled from the configured preset closest to each width.
+	largePx     = 1200
+	mediumPx    = 800
+	thumbnailPx = 300
+
+	originalVariant = "original"
+
+	imageProcessTimeout = 2 * time.Minute
+	imagePollInterval   = 10 * time.Second
+	imageStuckAfter     = 10 * time.Minute
+	imageMaxAttempts    = 3
 )
 
-var allowedContentTypes = map[string]bool{
-	"image/jpeg": true,
-	"image/png":  true,
-	"image/webp": true,
+var allowedContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
 }
 
+// legacyVariants are the files written before size presets were configurable.
+var legacyVariants = []string{"large", "medium", "thumbnail"}
+
+// ImageService stores product image uploads and renders their size variants
+// in background workers.
 type ImageService struct {
-	imageRepo domain.ProductImageRepository
+	imageRepo   domain.ProductImageRepository
 	productRepo domain.ProductRepository
-	s3        *storage.S3Client
-	log       *zap.Logger
+	s3          *storage.S3Client
+	cfg         config.ImageConfig
+	cache       *cache.Store
+	wake        chan struct{}
+	log         *zap.Logger
 }
 
 func NewImageService(
 	imageRepo domain.ProductImageRepository,
 	productRepo domain.ProductRepository,
 	s3 *storage.S3Client,
+	cfg config.ImageConfig,
 	log *zap.Logger,
 ) *ImageService {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
 	return &ImageService{
 		imageRepo:   imageRepo,
 		productRepo: productRepo,
 		s3:          s3,
+		cfg:         cfg,
+		wake:        make(chan struct{}, cfg.Workers),
 		log:         log,
 	}
 }
 
+// SetCache enables product cache invalidation once new variants are published.
+func (s *ImageService) SetCache(c *cache.Store) {
+	s.cache = c
+}
+
 type ImageUploadResult struct {
 	Image *domain.ProductImage `json:"image"`
 }
 
-// Upload processes and uploads an image for a product.
+// Upload stores the source file and queues the image for processing.
+// The returned image is pending; its URL points at the source until the
+// variants are ready.
 func (s *ImageService) Upload(ctx context.Context, productID int, fileData io.Reader, contentType string, fileSize int64) (*domain.ProductImage, error) {
-	// Validate content type
-	if !allowedContentTypes[contentType] {
+	if _, ok := allowedContentTypes[contentType]; !ok {
 		return nil, fmt.Errorf("unsupported image format: %s (allowed: JPEG, PNG, WebP)", contentType)
 	}
 
-	// Validate file size
 	if fileSize > maxImageSize {
 		return nil, fmt.Errorf("image too large: %d bytes (max: %d MB)", fileSize, maxImageSize>>20)
 	}
 
-	// Check product exists
-	_, err := s.productRepo.FindByID(ctx, productID)
-	if err != nil {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
 		return nil, err
 	}
 
-	// Read file data
-	data, err := io.ReadAll(fileData)
+	data, err := io.ReadAll(io.LimitReader(fileData, maxImageSize+1))
 	if err != nil {
 		return nil, fmt.Errorf("read image data: %w", err)
 	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image too large (max: %d MB)", maxImageSize>>20)
+	}
 
-	// Decode image
-	src, _, err := image.Decode(bytes.NewReader(data))
+	return s.store(ctx, productID, data)
+}
+
+// store saves the source file of an already validated product and queues it.
+// The format is taken from the file header, not from client-supplied types.
+func (s *ImageService) store(ctx context.Context, productID int, data []byte) (*domain.ProductImage, error) {
+	// Only the header is decoded here, full decoding happens in the worker.
+	imgCfg, format, err := image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("decode image: %w", err)
 	}
-
-	// Generate unique prefix
-	id := uuid.New().String()
-	prefix := fmt.Sprintf("products/%d/%s", productID, id)
-
-	// Determine output format
-	ext := ".jpg"
-	outputContentType := "image/jpeg"
-	if contentType == "image/png" {
-		ext = ".png"
-		outputContentType = "image/png"
+	contentType := "image/" + format
+	ext, ok := allowedContentTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image format: %s (allowed: JPEG, PNG, WebP)", format)
 	}
-
-	// Resize and upload all versions
-	versions := []struct {
-		suffix string
-		width  int
-	}{
-		{"original", maxOriginalPx},
-		{"large", largePx},
-		{"medium", mediumPx},
-		{"thumbnail", thumbnailPx},
+	if imgCfg.Width*imgCfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("image resolution too large: %dx%d", imgCfg.Width, imgCfg.Height)
 	}
 
-	urls := make(map[string]string, 4)
-	for _, v := range versions {
-		resized := resizeImage(src, v.width)
-		encoded, err := encodeImage(resized, contentType)
-		if err != nil {
-			return nil, fmt.Errorf("encode %s: %w", v.suffix, err)
-		}
-
-		key := path.Join(prefix, v.suffix+ext)
-		url, err := s.s3.Upload(ctx, key, encoded, outputContentType)
-		if err != nil {
-			return nil, fmt.Errorf("upload %s: %w", v.suffix, err)
-		}
-		urls[v.suffix] = url
+	prefix := fmt.Sprintf("products/%d/%s", productID, uuid.New().String())
+	sourceKey := path.Join(prefix, "source"+ext)
+	sourceURL, err := s.s3.Upload(ctx, sourceKey, data, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("upload source: %w", err)
 	}
 
 	// Check if this is the first image — make it main
@@ -134,17 +150,13 @@ func (s *ImageService) Upload(ctx context.Context, productID int, fileData io.Re
 		return nil, err
 	}
 
-	urlLarge := urls["large"]
-	urlMedium := urls["medium"]
-	urlThumb := urls["thumbnail"]
-
 	img := &domain.ProductImage{
 		ProductID:    productID,
-		URL:          urls["original"],
-		URLLarge:     &urlLarge,
-		URLMedium:    &urlMedium,
-		URLThumbnail: &urlThumb,
+		URL:          sourceURL,
+		Variants:     domain.ImageVariants{},
 		S3Key:        prefix,
+		SourceKey:    &sourceKey,
+		Status:       domain.ImageStatusPending,
 		IsMain:       count == 0, // first image becomes main
 		DisplayOrder: int(count),
 	}
@@ -152,8 +164,9 @@ func (s *ImageService) Upload(ctx context.Context, productID int, fileData io.Re
 	if err := s.imageRepo.Create(ctx, img); err != nil {
 		return nil, err
 	}
+	s.notify(1)
 
-	s.log.Info("image uploaded",
+	s.log.Info("image queued",
 		zap.Int("productID", productID),
 		zap.Int("imageID", img.ID),
 		zap.String("s3Key", prefix),
@@ -205,6 +218,359 @@ func (s *ImageService) GetByProductID(ctx context.Context, productID int) ([]dom
 	return s.imageRepo.FindByProductID(ctx, productID)
 }
 
+// Reorder sets the display order of a product's images. imageIDs must list
+// every image of the product exactly once.
+func (s *ImageService) Reorder(ctx context.Context, productID int, imageIDs []int) ([]domain.ProductImage, error) {
+	images, err := s.imageRepo.FindByProductID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(imageIDs) != len(images) {
+		return nil, domain.ErrImageOrderMismatch
+	}
+	owned := make(map[int]bool, len(images))
+	for _, img := range images {
+		owned[img.ID] = true
+	}
+	for _, id := range imageIDs {
+		if !owned[id] {
+			return nil, domain.ErrImageOrderMismatch
+		}
+		delete(owned, id) // a repeated ID is not found the second time
+	}
+
+	if err := s.imageRepo.UpdateDisplayOrders(ctx, productID, imageIDs); err != nil {
+		return nil, err
+	}
+	s.invalidateProductCache(ctx)
+
+	s.log.Info("images reordered", zap.Int("productID", productID), zap.Int("count", len(imageIDs)))
+	return s.imageRepo.FindByProductID(ctx, productID)
+}
+
+// Reprocess queues a single image for processing again, e.g. after a failure.
+func (s *ImageService) Reprocess(ctx context.Context, imageID int) error {
+	if err := s.imageRepo.Requeue(ctx, imageID); err != nil {
+		return err
+	}
+	s.notify(1)
+	return nil
+}
+
+// Regenerate queues images rendered with other size presets than the current
+// ones. With force every image is queued.
+func (s *ImageService) Regenerate(ctx context.Context, force bool) (int64, error) {
+	signature := s.presetSignature()
+	if force {
+		signature = ""
+	}
+	queued, err := s.imageRepo.RequeueStale(ctx, signature)
+	if err != nil {
+		return 0, fmt.Errorf("requeue images: %w", err)
+	}
+	s.notify(int(queued))
+
+	s.log.Info("image regeneration queued", zap.Int64("images", queued), zap.Bool("force", force))
+	return queued, nil
+}
+
+// ImagePresetInfo describes a configured size preset.
+type ImagePresetInfo struct {
+	Name  string `json:"name"`
+	Width int    `json:"width"`
+}
+
+// ImageProcessingStatus is the state of the image queue for the admin panel.
+type ImageProcessingStatus struct {
+	Counts  map[string]int64  `json:"counts"`
+	Presets []ImagePresetInfo `json:"presets"`
+	Workers int               `json:"workers"`
+}
+
+// Status returns image counts per processing status and the active presets.
+func (s *ImageService) Status(ctx context.Context) (*ImageProcessingStatus, error) {
+	counts, err := s.imageRepo.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	presets := []ImagePresetInfo{{Name: originalVariant, Width: s.cfg.MaxPx}}
+	for _, p := range s.cfg.Presets {
+		presets = append(presets, ImagePresetInfo{Name: p.Name, Width: p.Width})
+	}
+	return &ImageProcessingStatus{Counts: counts, Presets: presets, Workers: s.cfg.Workers}, nil
+}
+
+// StartWorkers processes queued images until ctx is cancelled. Images left in
+// processing by a crashed instance are returned to the queue periodically.
+func (s *ImageService) StartWorkers(ctx context.Context) {
+	s.log.Info("starting image workers", zap.Int("workers", s.cfg.Workers))
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runWorker(ctx)
+		}()
+	}
+
+	s.requeueStuck(ctx)
+	ticker := time.NewTicker(imageStuckAfter)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			s.log.Info("stopping image workers")
+			return
+		case <-ticker.C:
+			s.requeueStuck(ctx)
+		}
+	}
+}
+
+func (s *ImageService) requeueStuck(ctx context.Context) {
+	n, err := s.imageRepo.RequeueStuck(ctx, time.Now().Add(-imageStuckAfter))
+	if err != nil {
+		s.log.Error("failed to requeue stuck images", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		s.log.Warn("stuck images requeued", zap.Int64("images", n))
+		s.notify(int(n))
+	}
+}
+
+func (s *ImageService) runWorker(ctx context.Context) {
+	for {
+		if s.processNext(ctx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-time.After(imagePollInterval):
+		}
+	}
+}
+
+// notify wakes up to n idle workers without blocking.
+func (s *ImageService) notify(n int) {
+	for i := 0; i < n && i < s.cfg.Workers; i++ {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// processNext handles one queued image. It reports whether an image was taken.
+func (s *ImageService) processNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	img, err := s.imageRepo.ClaimPending(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("failed to claim queued image", zap.Error(err))
+		}
+		return false
+	}
+	if img == nil {
+		return false
+	}
+
+	started := time.Now()
+	procCtx, cancel := context.WithTimeout(ctx, imageProcessTimeout)
+	err = s.process(procCtx, img)
+	cancel()
+
+	// The result is saved even when shutdown interrupted processing.
+	saveCtx := context.WithoutCancel(ctx)
+	if err != nil {
+		msg := err.Error()
+		img.Error = &msg
+		img.Status = domain.ImageStatusFailed
+		if img.Attempts < imageMaxAttempts {
+			img.Status = domain.ImageStatusPending
+		}
+		s.log.Error("image processing failed",
+			zap.Int("imageID", img.ID),
+			zap.Int("attempt", img.Attempts),
+			zap.String("status", img.Status),
+			zap.Error(err),
+		)
+	} else {
+		s.log.Info("image processed",
+			zap.Int("imageID", img.ID),
+			zap.Int("variants", len(img.Variants)),
+			zap.Duration("took", time.Since(started)),
+		)
+	}
+
+	if err := s.imageRepo.SaveProcessing(saveCtx, img); err != nil {
+		s.log.Error("failed to save image processing result", zap.Int("imageID", img.ID), zap.Error(err))
+	}
+	if img.Status == domain.ImageStatusReady {
+		s.invalidateProductCache(saveCtx)
+	}
+	return true
+}
+
+// invalidateProductCache drops cached product lists and SEO data that embed image URLs.
+func (s *ImageService) invalidateProductCache(ctx context.Context) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.DeleteByPrefix(ctx, productCachePrefix); err != nil {
+		s.log.Warn("failed to invalidate product cache", zap.Error(err))
+	}
+	invalidateSEOCache(ctx, s.cache, s.log)
+}
+
+// process renders the original and every preset as JPEG (PNG for sources with
+// transparency) plus WebP, uploads them and fills img with the new URLs.
+func (s *ImageService) process(ctx context.Context, img *domain.ProductImage) error {
+	sourceKey, err := s.ensureSource(ctx, img)
+	if err != nil {
+		return err
+	}
+
+	data, err := s.s3.Download(ctx, sourceKey)
+	if err != nil {
+		return fmt.Errorf("download source: %w", err)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode image: %w", err)
+	}
+
+	ext, contentType := ".jpg", "image/jpeg"
+	if path.Ext(sourceKey) == ".png" || hasAlpha(src) {
+		ext, contentType = ".png", "image/png"
+	}
+
+	presets := append([]config.ImagePreset{{Name: originalVariant, Width: s.cfg.MaxPx}}, s.cfg.Presets...)
+	variants := make(domain.ImageVariants, len(presets))
+	for _, p := range presets {
+		resized := resizeImage(src, p.Width)
+
+		encoded, err := encodeImage(resized, contentType, s.cfg.JPEGQuality)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", p.Name, err)
+		}
+		url, err := s.s3.Upload(ctx, path.Join(img.S3Key, p.Name+ext), encoded, contentType)
+		if err != nil {
+			return fmt.Errorf("upload %s: %w", p.Name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := webp.Encode(&buf, resized, webp.Options{Quality: s.cfg.WebPQuality, Method: webp.DefaultMethod}); err != nil {
+			return fmt.Errorf("encode %s webp: %w", p.Name, err)
+		}
+		webpURL, err := s.s3.Upload(ctx, path.Join(img.S3Key, p.Name+".webp"), buf.Bytes(), "image/webp")
+		if err != nil {
+			return fmt.Errorf("upload %s webp: %w", p.Name, err)
+		}
+
+		variants[p.Name] = domain.ImageVariant{Width: resized.Bounds().Dx(), URL: url, WebP: webpURL}
+	}
+
+	s.deleteDroppedVariants(ctx, img, variants, ext)
+
+	original := variants[originalVariant]
+	signature := s.presetSignature()
+	now := time.Now()
+	img.URL = original.URL
+	img.URLWebP = &original.WebP
+	img.URLLarge = s.closestVariantURL(variants, largePx)
+	img.URLMedium = s.closestVariantURL(variants, mediumPx)
+	img.URLThumbnail = s.closestVariantURL(variants, thumbnailPx)
+	img.Variants = variants
+	img.Status = domain.ImageStatusReady
+	img.Error = nil
+	img.PresetSignature = &signature
+	img.ProcessedAt = &now
+	return nil
+}
+
+// ensureSource returns the S3 key of the unprocessed upload. Images uploaded
+// before background processing only have the resized original, which is
+// copied to a source file first so regeneration never reads its own output.
+func (s *ImageService) ensureSource(ctx context.Context, img *domain.ProductImage) (string, error) {
+	if img.SourceKey != nil {
+		return *img.SourceKey, nil
+	}
+
+	ext := path.Ext(img.URL)
+	data, err := s.s3.Download(ctx, path.Join(img.S3Key, originalVariant+ext))
+	if err != nil {
+		return "", fmt.Errorf("download legacy original: %w", err)
+	}
+	contentType := "image/jpeg"
+	if ext == ".png" {
+		contentType = "image/png"
+	}
+	sourceKey := path.Join(img.S3Key, "source"+ext)
+	if _, err := s.s3.Upload(ctx, sourceKey, data, contentType); err != nil {
+		return "", fmt.Errorf("copy legacy original: %w", err)
+	}
+	img.SourceKey = &sourceKey
+	return sourceKey, nil
+}
+
+// deleteDroppedVariants removes files of presets that are no longer configured.
+func (s *ImageService) deleteDroppedVariants(ctx context.Context, img *domain.ProductImage, current domain.ImageVariants, ext string) {
+	previous := make([]string, 0, len(img.Variants))
+	for name := range img.Variants {
+		previous = append(previous, name)
+	}
+	if img.PresetSignature == nil {
+		previous = append(previous, legacyVariants...)
+	}
+
+	for _, name := range previous {
+		if _, ok := current[name]; ok {
+			continue
+		}
+		for _, key := range []string{path.Join(img.S3Key, name+ext), path.Join(img.S3Key, name+".webp")} {
+			if err := s.s3.Delete(ctx, key); err != nil {
+				s.log.Warn("failed to delete dropped image variant", zap.String("key", key), zap.Error(err))
+			}
+		}
+	}
+}
+
+// closestVariantURL picks the preset whose width is nearest to px.
+func (s *ImageService) closestVariantURL(variants domain.ImageVariants, px int) *string {
+	best := originalVariant
+	bestDiff := -1
+	for _, p := range s.cfg.Presets {
+		diff := p.Width - px
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff {
+			best, bestDiff = p.Name, diff
+		}
+	}
+	url := variants[best].URL
+	return &url
+}
+
+// presetSignature identifies the settings variants were rendered with.
+func (s *ImageService) presetSignature() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d", originalVariant, s.cfg.MaxPx)
+	for _, p := range s.cfg.Presets {
+		fmt.Fprintf(&b, ",%s:%d", p.Name, p.Width)
+	}
+	fmt.Fprintf(&b, ";jpeg=%d;webp=%d", s.cfg.JPEGQuality, s.cfg.WebPQuality)
+	return b.String()
+}
+
 // resizeImage resizes an image to fit within maxWidth, maintaining aspect ratio.
 // If the image is already smaller, it returns the original.
 func resizeImage(src image.Image, maxWidth int) image.Image {
@@ -216,7 +582,7 @@ func resizeImage(src image.Image, maxWidth int) image.Image {
 }
 
 // encodeImage encodes an image to bytes in the specified format.
-func encodeImage(img image.Image, contentType string) ([]byte, error) {
+func encodeImage(img image.Image, contentType string, jpegQuality int) ([]byte, error) {
 	var buf bytes.Buffer
 	switch {
 	case strings.Contains(contentType, "png"):
@@ -224,9 +590,17 @@ func encodeImage(img image.Image, contentType string) ([]byte, error) {
 			return nil, err
 		}
 	default:
-		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
 			return nil, err
 		}
 	}
 	return buf.Bytes(), nil
 }
+
+// hasAlpha reports whether the image has transparent pixels.
+func hasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return false
+}
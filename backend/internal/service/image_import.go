@@ -0,0 +1,358 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+const (
+	maxImportURLs      = 20
+	importConcurrency  = 4
+	importTimeout      = 30 * time.Second
+	importMaxRedirects = 3
+
+	maxImageArchiveSize    = 200 << 20 // 200 MB compressed
+	maxImageArchiveTotal   = 1 << 30   // 1 GB uncompressed
+	maxImageArchiveEntries = 500
+)
+
+var (
+	ErrImageArchiveTooLarge = errors.New("архив слишком большой (максимум 200 MB)")
+	ErrImageArchiveInvalid  = errors.New("некорректный ZIP-архив")
+
+	errImportAddressForbidden = errors.New("address is not allowed")
+)
+
+// blockedImportPrefixes are special-purpose ranges not covered by the net/netip
+// helpers used in isPublicAddr.
+var blockedImportPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64 can reach private IPv4
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// archiveSuffix matches "-2", "_03", " (4)" after the SKU in archive file names.
+var archiveSuffix = regexp.MustCompile(`^(.+?)(?:[\s_\-.]+\d{1,3}|\s*\(\d{1,3}\))$`)
+
+// importHTTPClient fetches images from admin-supplied URLs. The dialer checks
+// every resolved address, so redirects and DNS rebinding cannot reach internal
+// hosts; environment proxies are ignored for the same reason.
+var importHTTPClient = &http.Client{
+	Timeout: importTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(_, address string, _ syscall.RawConn) error {
+				addrPort, err := netip.ParseAddrPort(address)
+				if err != nil || !isPublicAddr(addrPort.Addr()) {
+					return fmt.Errorf("%w: %s", errImportAddressForbidden, address)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout:    10 * time.Second,
+		ResponseHeaderTimeout:  15 * time.Second,
+		MaxResponseHeaderBytes: 64 << 10,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= importMaxRedirects {
+			return errors.New("too many redirects")
+		}
+		return validateImportURL(req.URL)
+	},
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range blockedImportPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateImportURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" || u.User != nil {
+		return errors.New("invalid URL host")
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return fmt.Errorf("port %s is not allowed", port)
+	}
+	return nil
+}
+
+// ImageImportResult is the outcome of importing a single URL.
+type ImageImportResult struct {
+	URL   string               `json:"url"`
+	Image *domain.ProductImage `json:"image,omitempty"`
+	Error string               `json:"error,omitempty"`
+}
+
+// ImportFromURLs downloads images for a product and queues them for processing.
+// Results keep the order of urls; one failed URL does not stop the others.
+func (s *ImageService) ImportFromURLs(ctx context.Context, productID int, urls []string) ([]ImageImportResult, error) {
+	if len(urls) > maxImportURLs {
+		return nil, fmt.Errorf("too many URLs: %d (max: %d)", len(urls), maxImportURLs)
+	}
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	// Downloads run in parallel, images are stored in input order so
+	// display order matches the list.
+	data := make([][]byte, len(urls))
+	errs := make([]error, len(urls))
+	sem := make(chan struct{}, importConcurrency)
+	var wg sync.WaitGroup
+	for i, rawURL := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			data[i], errs[i] = fetchImage(ctx, rawURL)
+		}()
+	}
+	wg.Wait()
+
+	results := make([]ImageImportResult, len(urls))
+	imported := 0
+	for i, rawURL := range urls {
+		results[i].URL = rawURL
+		if errs[i] == nil {
+			results[i].Image, errs[i] = s.store(ctx, productID, data[i])
+		}
+		if errs[i] != nil {
+			results[i].Error = errs[i].Error()
+			s.log.Warn("image import failed", zap.Int("productID", productID), zap.String("url", rawURL), zap.Error(errs[i]))
+			continue
+		}
+		imported++
+	}
+
+	s.log.Info("images imported from urls",
+		zap.Int("productID", productID),
+		zap.Int("requested", len(urls)),
+		zap.Int("imported", imported),
+	)
+	return results, nil
+}
+
+func fetchImage(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if err := validateImportURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	req.Header.Set("Accept", "image/jpeg, image/png, image/webp")
+
+	resp, err := importHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download: unexpected status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if _, ok := allowedContentTypes[mediaType]; !ok {
+		return nil, fmt.Errorf("unsupported content type %q (allowed: JPEG, PNG, WebP)", mediaType)
+	}
+	if resp.ContentLength > maxImageSize {
+		return nil, fmt.Errorf("image too large: %d bytes (max: %d MB)", resp.ContentLength, maxImageSize>>20)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image too large (max: %d MB)", maxImageSize>>20)
+	}
+	return data, nil
+}
+
+// ImageArchiveItem is one image file of an uploaded archive.
+type ImageArchiveItem struct {
+	File      string `json:"file"`
+	SKU       string `json:"sku,omitempty"`
+	ProductID int    `json:"productId,omitempty"`
+	ImageID   int    `json:"imageId,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ImageArchiveResult summarises a bulk ZIP upload.
+type ImageArchiveResult struct {
+	Attached  int                `json:"attached"`
+	Items     []ImageArchiveItem `json:"items"`
+	Unmatched []string           `json:"unmatched"`
+	Skipped   []string           `json:"skipped"`
+}
+
+// UploadArchive attaches images from a ZIP archive to products by SKU. A file
+// named after the SKU, optionally with a sequence suffix ("PLA-001.jpg",
+// "PLA-001_2.jpg", "PLA-001 (3).png"), goes to that product; files are added
+// in name order.
+func (s *ImageService) UploadArchive(ctx context.Context, r io.ReaderAt, size int64) (*ImageArchiveResult, error) {
+	if size > maxImageArchiveSize {
+		return nil, ErrImageArchiveTooLarge
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrImageArchiveInvalid
+	}
+
+	result := &ImageArchiveResult{Items: []ImageArchiveItem{}, Unmatched: []string{}, Skipped: []string{}}
+
+	var files []*zip.File
+	for _, f := range zr.File {
+		base := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.Contains(f.Name, "__MACOSX/") {
+			continue
+		}
+		switch strings.ToLower(path.Ext(base)) {
+		case ".jpg", ".jpeg", ".png", ".webp":
+			files = append(files, f)
+		default:
+			result.Skipped = append(result.Skipped, f.Name)
+		}
+	}
+	if len(files) > maxImageArchiveEntries {
+		return nil, fmt.Errorf("%w: больше %d файлов", ErrImageArchiveInvalid, maxImageArchiveEntries)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	// Collect exact and suffix-trimmed candidates, then look them up in one query.
+	candidates := make([][]string, len(files))
+	var skus []string
+	for i, f := range files {
+		candidates[i] = skuCandidates(f.Name)
+		skus = append(skus, candidates[i]...)
+	}
+	bySKU := make(map[string]domain.Product)
+	if len(skus) > 0 {
+		products, err := s.productRepo.FindBySKUs(ctx, skus)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range products {
+			bySKU[strings.ToLower(*p.SKU)] = p
+		}
+	}
+
+	var total uint64
+	for i, f := range files {
+		var product *domain.Product
+		for _, c := range candidates[i] {
+			if p, ok := bySKU[strings.ToLower(c)]; ok {
+				product = &p
+				break
+			}
+		}
+		if product == nil {
+			result.Unmatched = append(result.Unmatched, f.Name)
+			continue
+		}
+
+		item := ImageArchiveItem{File: f.Name, SKU: *product.SKU, ProductID: product.ID}
+		total += f.UncompressedSize64
+		if total > maxImageArchiveTotal {
+			return nil, fmt.Errorf("%w: слишком большой объём после распаковки", ErrImageArchiveInvalid)
+		}
+
+		img, err := s.storeArchiveFile(ctx, product.ID, f)
+		if err != nil {
+			item.Error = err.Error()
+			s.log.Warn("archive image failed", zap.String("file", f.Name), zap.Error(err))
+		} else {
+			item.ImageID = img.ID
+			result.Attached++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	s.log.Info("image archive processed",
+		zap.Int("files", len(files)),
+		zap.Int("attached", result.Attached),
+		zap.Int("unmatched", len(result.Unmatched)),
+	)
+	return result, nil
+}
+
+func (s *ImageService) storeArchiveFile(ctx context.Context, productID int, f *zip.File) (*domain.ProductImage, error) {
+	if f.UncompressedSize64 > maxImageSize {
+		return nil, fmt.Errorf("image too large (max: %d MB)", maxImageSize>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open archive entry: %w", err)
+	}
+	defer rc.Close()
+
+	// The declared size can lie, the limit is enforced on the actual data too.
+	data, err := io.ReadAll(io.LimitReader(rc, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read archive entry: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image too large (max: %d MB)", maxImageSize>>20)
+	}
+	return s.store(ctx, productID, data)
+}
+
+// skuCandidates returns possible SKUs for an archive file name: the name
+// without extension, then the name without a trailing sequence number.
+func skuCandidates(name string) []string {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	stem := strings.TrimSpace(strings.TrimSuffix(base, path.Ext(base)))
+	if stem == "" {
+		return nil
+	}
+	candidates := []string{stem}
+	if m := archiveSuffix.FindStringSubmatch(stem); m != nil && m[1] != stem {
+		candidates = append(candidates, m[1])
+	}
+	return candidates
+}
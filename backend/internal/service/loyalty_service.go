@@ -237,6 +237,46 @@ func (s *LoyaltyService) DeductBonuses(ctx context.Context, tx *gorm.DB, userID
 	return nil
 }
 
+// RefundBonuses returns bonuses spent on an order that was cancelled, within the given transaction.
+func (s *LoyaltyService) RefundBonuses(ctx context.Context, tx *gorm.DB, userID int, amount float64, orderID int) error {
+	return s.creditBonuses(ctx, tx, userID, amount, orderID, "order_refund", "Возврат бонусов за отменённый заказ")
+}
+
+// ReturnExcessBonuses returns the part of the bonuses spent on an order that
+// no longer fits it after an edit, within the given transaction.
+func (s *LoyaltyService) ReturnExcessBonuses(ctx context.Context, tx *gorm.DB, userID int, amount float64, orderID int) error {
+	return s.creditBonuses(ctx, tx, userID, amount, orderID, "order_refund", "Возврат бонусов после изменения заказа")
+}
+
+// CreditReturn pays out a refund for returned items as store credit in
+// bonuses, within the given transaction.
+func (s *LoyaltyService) CreditReturn(ctx context.Context, tx *gorm.DB, userID int, amount float64, orderID int) error {
+	return s.creditBonuses(ctx, tx, userID, amount, orderID, "return_credit", "Возврат за товары по заявке на возврат")
+}
+
+func (s *LoyaltyService) creditBonuses(ctx context.Context, tx *gorm.DB, userID int, amount float64, orderID int, txType, desc string) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	if err := tx.WithContext(ctx).Model(&domain.User{}).Where("id = ?", userID).
+		UpdateColumn("bonus_balance", gorm.Expr("bonus_balance + ?", amount)).Error; err != nil {
+		return fmt.Errorf("credit bonuses: %w", err)
+	}
+
+	bonusTx := &domain.BonusTransaction{
+		UserID:      userID,
+		Amount:      amount,
+		Type:        txType,
+		ReferenceID: &orderID,
+		Description: &desc,
+	}
+	if err := tx.Create(bonusTx).Error; err != nil {
+		return fmt.Errorf("create credit tx: %w", err)
+	}
+	return nil
+}
+
 // ReferralInfoResponse is returned by GetReferralInfo.
 type ReferralInfoResponse struct {
 	ReferralCode   string  `json:"referralCode"`
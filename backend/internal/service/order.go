@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -14,18 +15,26 @@ import (
 
 type CreateOrderInput struct {
 	Items           []OrderItemInput `json:"items" binding:"required,min=1,dive"`
-	CustomerName    string           `json:"customerName" binding:"required"`
-	CustomerPhone   string           `json:"customerPhone" binding:"required"`
+	CustomerName    string           `json:"customerName"`  // required unless taken from addressId
+	CustomerPhone   string           `json:"customerPhone"` // required unless taken from addressId
 	CustomerEmail   *string          `json:"customerEmail"`
-	DeliveryMethod  string           `json:"deliveryMethod" binding:"required,oneof=pickup courier pickup_point"`
+	DeliveryMethod  string           `json:"deliveryMethod" binding:"omitempty,oneof=pickup courier pickup_point"` // required unless all items are digital
 	DeliveryAddress *string          `json:"deliveryAddress"`
-	PaymentMethod   string           `json:"paymentMethod" binding:"required,oneof=card cash"`
+	PaymentMethod   string           `json:"paymentMethod" binding:"required,oneof=card cash invoice"` // invoice: bank transfer, needs company requisites
 	PromoCode       *string          `json:"promoCode"`
 	BonusAmount     float64          `json:"bonusAmount"`
 	Notes           *string          `json:"notes"`
 	TelegramID      *int64           `json:"telegramId"`
 	PickupPointID   *int             `json:"pickupPointId"`
 	City            *string          `json:"city"`
+	// AddressID fills delivery and contact fields left empty from the
+	// customer's address book. Only for signed-in customers.
+	AddressID *int `json:"addressId"`
+	// ForCompany places the order for the customer's company. Implied by
+	// the "invoice" payment method.
+	ForCompany bool `json:"forCompany"`
+	// UserID is the signed-in customer, set by the handler.
+	UserID *int `json:"-"`
 }
 
 type OrderItemInput struct {
@@ -42,7 +51,18 @@ type OrderService struct {
 	loyaltyService  *LoyaltyService
 	emailService    *EmailService
 	paymentService  *PaymentService
+	production      *ProductionService
+	stats           *ProductStatsService
 	notifier        domain.OrderNotifier
+	pricing         *PricingEngine
+	settingsRepo    domain.OrderSettingsRepository
+	changeRepo      domain.OrderChangeRepository
+	numbers         *OrderNumberGenerator
+	cartReminders   *CartReminderService
+	addresses       *AddressService
+	companies       *CompanyService
+	stock           *StockService
+	cartService     *CartService
 	db              *gorm.DB
 	log             *zap.Logger
 }
@@ -60,6 +80,32 @@ func (s *OrderService) SetNotifier(n domain.OrderNotifier) {
 // SetDeliveryService sets the delivery service for cost calculation.
 func (s *OrderService) SetDeliveryService(ds *DeliveryService) {
 	s.deliveryService = ds
+	s.pricing.deliveryService = ds
+}
+
+// SetPriceListService sets the service resolving quantity price breaks and customer price lists.
+func (s *OrderService) SetPriceListService(ps *PriceListService) {
+	s.pricing.prices = ps
+}
+
+// SetStockService sets the stock ledger, used for back-in-stock alerts when an order returns stock.
+func (s *OrderService) SetStockService(ss *StockService) {
+	s.stock = ss
+}
+
+// SetCartReminderService sets the service that attributes orders to cart reminders.
+func (s *OrderService) SetCartReminderService(cr *CartReminderService) {
+	s.cartReminders = cr
+}
+
+// SetAddressService sets the address book used to fill orders placed with an addressId.
+func (s *OrderService) SetAddressService(as *AddressService) {
+	s.addresses = as
+}
+
+// SetCompanyService sets the service providing company requisites of B2B orders.
+func (s *OrderService) SetCompanyService(cs *CompanyService) {
+	s.companies = cs
 }
 
 // SetEmailService sets the email service for order notifications.
@@ -72,6 +118,22 @@ func (s *OrderService) SetPaymentService(ps *PaymentService) {
 	s.paymentService = ps
 }
 
+// SetProductionService sets the service used to estimate ship dates of made-to-order items.
+func (s *OrderService) SetProductionService(ps *ProductionService) {
+	s.production = ps
+	s.pricing.production = ps
+}
+
+// SetOrderNumberGenerator sets the configured order number format.
+func (s *OrderService) SetOrderNumberGenerator(g *OrderNumberGenerator) {
+	s.numbers = g
+}
+
+// SetProductStatsService sets the service that maintains product sales counters.
+func (s *OrderService) SetProductStatsService(ps *ProductStatsService) {
+	s.stats = ps
+}
+
 func NewOrderService(
 	orderRepo domain.OrderRepository,
 	productRepo domain.ProductRepository,
@@ -85,102 +147,73 @@ func NewOrderService(
 		productRepo:  productRepo,
 		userRepo:     userRepo,
 		promoService: promoService,
+		pricing:      NewPricingEngine(productRepo, promoService, log),
+		numbers:      defaultOrderNumbers(orderRepo),
 		db:           db,
 		log:          log,
 	}
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput) (*domain.Order, error) {
-	// 1. Load and validate products
-	productIDs := make([]int, len(input.Items))
-	qtyMap := make(map[int]int)
-	for i, item := range input.Items {
-		productIDs[i] = item.ProductID
-		qtyMap[item.ProductID] = item.Quantity
+	if input.AddressID != nil {
+		address, err := s.findAddress(ctx, input.UserID, *input.AddressID)
+		if err != nil {
+			return nil, err
+		}
+		applyAddress(&input, address)
 	}
-
-	products, err := s.productRepo.FindByIDs(ctx, productIDs)
-	if err != nil {
-		return nil, fmt.Errorf("load products: %w", err)
+	input.CustomerName = strings.TrimSpace(input.CustomerName)
+	input.CustomerPhone = strings.TrimSpace(input.CustomerPhone)
+	if input.CustomerName == "" || input.CustomerPhone == "" {
+		return nil, domain.ErrCustomerContactRequired
 	}
 
-	productMap := make(map[int]*domain.Product)
-	for i := range products {
-		productMap[products[i].ID] = &products[i]
-	}
-
-	// Validate all products exist, are active, and have stock
-	var subtotal float64
-	orderItems := make([]domain.OrderItem, 0, len(input.Items))
-	for _, item := range input.Items {
-		p, ok := productMap[item.ProductID]
-		if !ok {
-			return nil, domain.ErrProductNotFound
-		}
-		if !p.IsActive {
-			return nil, domain.ErrProductInactive
-		}
-		if p.StockQuantity < item.Quantity {
-			return nil, domain.ErrInsufficientStock
-		}
-
-		itemTotal := math.Round(p.Price*float64(item.Quantity)*100) / 100
-		subtotal += itemTotal
-
-		productID := item.ProductID
-		orderItems = append(orderItems, domain.OrderItem{
-			ProductID:  &productID,
-			Quantity:   item.Quantity,
-			UnitPrice:  p.Price,
-			TotalPrice: itemTotal,
-		})
-	}
-	subtotal = math.Round(subtotal*100) / 100
-
-	// 2. Validate promo code if provided
-	var discountAmount float64
-	var promoCode *string
-	if input.PromoCode != nil && *input.PromoCode != "" {
-		result, err := s.promoService.Validate(ctx, ValidatePromoInput{
-			Code:       *input.PromoCode,
-			OrderTotal: subtotal,
-		})
+	// Orders paid by invoice are billed to the customer's company.
+	var companyID *int
+	if input.ForCompany || input.PaymentMethod == domain.PaymentMethodInvoice {
+		company, err := s.findCompany(ctx, input.UserID)
 		if err != nil {
 			return nil, err
 		}
-		discountAmount = result.DiscountAmount
-		promoCode = input.PromoCode
+		companyID = &company.ID
 	}
 
-	// 3. Calculate delivery cost
-	var deliveryCost float64
-	var deliveryProvider *string
-	var estimatedDelivery *string
-	if input.DeliveryMethod == "courier" && input.City != nil && *input.City != "" && s.deliveryService != nil {
-		cost, estimated, err := s.deliveryService.CalculateCourierCost(ctx, *input.City, subtotal-discountAmount, 0)
-		if err == nil {
-			deliveryCost = cost
-			prov := s.deliveryService.provider.Name()
-			deliveryProvider = &prov
-			estimatedDelivery = &estimated
-		}
+	// 1-4. Price the order: products, promo code, delivery.
+	// Repeated lines of one product share its stock, so they are priced as one.
+	input.Items = mergeOrderItems(input.Items)
+	quote, err := s.pricing.Quote(ctx, PricingInput{
+		Items:          input.Items,
+		DeliveryMethod: input.DeliveryMethod,
+		City:           input.City,
+		PromoCode:      input.PromoCode,
+		UserID:         input.UserID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load products: %w", err)
 	}
-
-	// 4. Calculate total (bonusDiscount computed after userID resolution)
-	totalPrice := math.Round((subtotal-discountAmount+deliveryCost)*100) / 100
-	if totalPrice < 0 {
-		totalPrice = 0
+	if err := quote.Err(); err != nil {
+		return nil, err
 	}
 
+	orderItems, stockTake := orderItemsFromQuote(quote)
+
 	// 5. Generate order number
-	orderNumber, err := s.orderRepo.NextOrderNumber(ctx)
+	orderNumber, err := s.numbers.Next(ctx, orderSeriesRegular)
 	if err != nil {
 		return nil, fmt.Errorf("generate order number: %w", err)
 	}
 
-	// 6. Link Telegram user if telegramId is provided
+	// 6. Link the signed-in customer, or the Telegram user if telegramId is provided
 	var userID *int
-	if input.TelegramID != nil && *input.TelegramID != 0 {
+	var bonusBalance float64
+	if input.UserID != nil {
+		user, err := s.userRepo.FindByID(ctx, *input.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("load customer: %w", err)
+		}
+		userID = &user.ID
+		bonusBalance = user.BonusBalance
+	} else if input.TelegramID != nil && *input.TelegramID != 0 {
 		user, err := s.userRepo.FindByTelegramID(ctx, *input.TelegramID)
 		if err != nil && errors.Is(err, domain.ErrUserNotFound) {
 			// Create new user from Telegram data
@@ -198,6 +231,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 			}
 		} else if err == nil {
 			userID = &user.ID
+			bonusBalance = user.BonusBalance
 			// Update phone if user didn't have one
 			if user.Phone == nil || *user.Phone == "" {
 				user.Phone = &input.CustomerPhone
@@ -206,21 +240,12 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 		}
 	}
 
-	// 8. Calculate bonus discount (after userID is known)
-	var bonusDiscount float64
-	if input.BonusAmount > 0 && userID != nil {
-		maxBonus := subtotal - discountAmount
-		if input.BonusAmount > maxBonus {
-			bonusDiscount = maxBonus
-		} else {
-			bonusDiscount = input.BonusAmount
-		}
-		bonusDiscount = math.Round(bonusDiscount*100) / 100
-		totalPrice = math.Round((totalPrice-bonusDiscount)*100) / 100
-		if totalPrice < 0 {
-			totalPrice = 0
-		}
+	// 8. Apply bonuses (after userID is known)
+	if userID != nil {
+		quote.ApplyBonuses(input.BonusAmount, bonusBalance)
 	}
+	bonusDiscount := quote.BonusDiscount
+	promoCode := quote.PromoCode
 
 	// 9. Create order in transaction
 	order := &domain.Order{
@@ -228,21 +253,22 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 		UserID:            userID,
 		OrderType:         "regular",
 		Status:            "new",
-		Subtotal:          subtotal,
-		DiscountAmount:    discountAmount,
+		Subtotal:          quote.Subtotal,
+		DiscountAmount:    quote.DiscountAmount,
 		BonusDiscount:     bonusDiscount,
-		DeliveryCost:      deliveryCost,
-		TotalPrice:        totalPrice,
+		DeliveryCost:      quote.DeliveryCost,
+		TotalPrice:        quote.TotalPrice,
 		PromoCode:         promoCode,
-		DeliveryMethod:    input.DeliveryMethod,
+		DeliveryMethod:    quote.DeliveryMethod,
 		DeliveryAddress:   input.DeliveryAddress,
 		PaymentMethod:     input.PaymentMethod,
+		CompanyID:         companyID,
 		CustomerName:      input.CustomerName,
 		CustomerPhone:     input.CustomerPhone,
 		CustomerEmail:     input.CustomerEmail,
 		PickupPointID:     input.PickupPointID,
-		DeliveryProvider:  deliveryProvider,
-		EstimatedDelivery: estimatedDelivery,
+		DeliveryProvider:  quote.DeliveryProvider,
+		EstimatedDelivery: quote.EstimatedDelivery,
 		Notes:             input.Notes,
 		Items:             orderItems,
 	}
@@ -253,16 +279,19 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 			return fmt.Errorf("create order: %w", err)
 		}
 
-		// Decrease stock for each product
-		for _, item := range input.Items {
-			result := tx.Model(&domain.Product{}).
-				Where("id = ? AND stock_quantity >= ?", item.ProductID, item.Quantity).
-				UpdateColumn("stock_quantity", gorm.Expr("stock_quantity - ?", item.Quantity))
-			if result.Error != nil {
-				return fmt.Errorf("update stock: %w", result.Error)
+		// Decrease stock for the units shipped from stock, one movement per product
+		for _, productID := range sortedKeys(stockTake, nil) {
+			fromStock := stockTake[productID]
+			if fromStock == 0 {
+				continue
 			}
-			if result.RowsAffected == 0 {
-				return domain.ErrInsufficientStock
+			if err := moveStock(tx, &domain.StockMovement{
+				ProductID: productID,
+				Type:      domain.StockMovementSale,
+				Quantity:  -fromStock,
+				OrderID:   &order.ID,
+			}); err != nil {
+				return err
 			}
 		}
 
@@ -300,6 +329,8 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 	)
 
 	// Initiate payment for card orders — synchronous so the link is in the response.
+	// Invoice orders get a bank-transfer invoice instead: it is attached to the
+	// confirmation email and can be downloaded from the order page.
 	if input.PaymentMethod == "card" && s.paymentService != nil {
 		if _, payErr := s.paymentService.InitiatePayment(ctx, created); payErr != nil {
 			s.log.Warn("failed to initiate payment link", zap.Error(payErr))
@@ -328,7 +359,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 				if pErr != nil {
 					continue
 				}
-				if p.StockQuantity > 0 && p.StockQuantity < 5 {
+				if p.FulfillmentMode != domain.FulfillmentMadeToOrder && p.StockQuantity > 0 && p.StockQuantity < 5 {
 					if err := s.notifier.NotifyAdminLowStock(bgCtx, p); err != nil {
 						s.log.Warn("failed to send low stock notification", zap.Error(err))
 					}
@@ -340,11 +371,85 @@ func (s *OrderService) CreateOrder(ctx context.Context, input CreateOrderInput)
 		if s.emailService != nil {
 			s.emailService.SendOrderCreated(created)
 		}
+
+		if s.cartReminders != nil {
+			s.cartReminders.TrackConversion(bgCtx, created)
+		}
 	}()
 
 	return created, nil
 }
 
+// orderItemsFromQuote turns priced lines into order items and returns the
+// units to take from stock per product.
+func orderItemsFromQuote(quote *Quote) ([]domain.OrderItem, map[int]int) {
+	stockTake := make(map[int]int)
+	items := make([]domain.OrderItem, 0, len(quote.Lines))
+	for _, line := range quote.Lines {
+		stockTake[line.ProductID] += line.FromStock
+		productID := line.ProductID
+		items = append(items, domain.OrderItem{
+			ProductID:          &productID,
+			Quantity:           line.Quantity,
+			ProductionQuantity: line.ToProduce,
+			StockQuantity:      line.FromStock,
+			UnitPrice:          line.UnitPrice,
+			TotalPrice:         line.TotalPrice,
+		})
+	}
+	return items, stockTake
+}
+
+// findAddress loads an address book entry of the signed-in customer.
+func (s *OrderService) findAddress(ctx context.Context, userID *int, addressID int) (*domain.UserAddress, error) {
+	if userID == nil || s.addresses == nil {
+		return nil, domain.ErrAddressNotFound
+	}
+	return s.addresses.Get(ctx, *userID, addressID)
+}
+
+// findCompany returns the company of the signed-in customer.
+func (s *OrderService) findCompany(ctx context.Context, userID *int) (*domain.Company, error) {
+	if userID == nil || s.companies == nil {
+		return nil, domain.ErrCompanyRequired
+	}
+	company, err := s.companies.Get(ctx, *userID)
+	if errors.Is(err, domain.ErrCompanyNotFound) {
+		return nil, domain.ErrCompanyRequired
+	}
+	return company, err
+}
+
+// applyAddress fills the fields the customer left empty from an address book
+// entry. Delivery fields are taken only when the delivery method matches the
+// address type, so an address can also serve as contacts for store pickup.
+func applyAddress(input *CreateOrderInput, a *domain.UserAddress) {
+	if input.DeliveryMethod == "" {
+		input.DeliveryMethod = a.Type
+	}
+	if input.DeliveryMethod == a.Type {
+		if input.City == nil {
+			input.City = a.City
+		}
+		switch a.Type {
+		case domain.AddressTypeCourier:
+			if input.DeliveryAddress == nil {
+				input.DeliveryAddress = a.Address
+			}
+		case domain.AddressTypePickupPoint:
+			if input.PickupPointID == nil {
+				input.PickupPointID = a.PickupPointID
+			}
+		}
+	}
+	if strings.TrimSpace(input.CustomerName) == "" && a.RecipientName != nil {
+		input.CustomerName = *a.RecipientName
+	}
+	if strings.TrimSpace(input.CustomerPhone) == "" && a.Phone != nil {
+		input.CustomerPhone = *a.Phone
+	}
+}
+
 func (s *OrderService) GetByOrderNumber(ctx context.Context, orderNumber string) (*domain.Order, error) {
 	return s.orderRepo.FindByOrderNumber(ctx, orderNumber)
 }
@@ -382,10 +487,27 @@ func (s *OrderService) UpdateStatus(ctx context.Context, id int, newStatus strin
 		return domain.ErrOrderStatusInvalid
 	}
 
-	if err := s.orderRepo.UpdateStatus(ctx, id, newStatus); err != nil {
+	if newStatus == "cancelled" {
+		if err := s.cancel(ctx, order); err != nil {
+			return err
+		}
+	} else if err := s.orderRepo.UpdateStatus(ctx, id, newStatus); err != nil {
 		return err
 	}
 
+	if s.stats != nil {
+		var statsErr error
+		switch newStatus {
+		case "delivered":
+			statsErr = s.stats.CountSale(ctx, id)
+		case "cancelled":
+			statsErr = s.stats.UncountSale(ctx, id)
+		}
+		if statsErr != nil {
+			s.log.Warn("failed to update sales count", zap.Int("orderId", id), zap.Error(statsErr))
+		}
+	}
+
 	// Send notification and credit referrer bonus asynchronously
 	go func() {
 		bgCtx := context.Background()
@@ -415,6 +537,60 @@ func (s *OrderService) UpdateStatus(ctx context.Context, id int, newStatus strin
 	return nil
 }
 
+// cancel cancels the order and returns the units taken from stock.
+func (s *OrderService) cancel(ctx context.Context, order *domain.Order) error {
+	var restocked []*domain.StockMovement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&domain.Order{}).
+			Where("id = ? AND status = ?", order.ID, order.Status).
+			Updates(map[string]interface{}{
+				"status":       "cancelled",
+				"cancelled_at": now,
+				"updated_at":   now,
+			})
+		if res.Error != nil {
+			return fmt.Errorf("cancel order: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			// The status was changed in the meantime.
+			return domain.ErrOrderStatusInvalid
+		}
+
+		var err error
+		restocked, err = restoreOrderStock(tx, order)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.stock.Changed(restocked...)
+	return nil
+}
+
+// restoreOrderStock returns the units of a cancelled order that were taken
+// from stock; printed and digital units never were.
+func restoreOrderStock(tx *gorm.DB, order *domain.Order) ([]*domain.StockMovement, error) {
+	var restocked []*domain.StockMovement
+	for _, item := range order.Items {
+		if item.ProductID == nil || item.StockQuantity <= 0 {
+			continue
+		}
+		m := &domain.StockMovement{
+			ProductID: *item.ProductID,
+			Type:      domain.StockMovementCancellation,
+			Quantity:  item.StockQuantity,
+			OrderID:   &order.ID,
+		}
+		if err := moveStock(tx, m); err != nil {
+			return nil, fmt.Errorf("restore stock: %w", err)
+		}
+		restocked = append(restocked, m)
+	}
+	return restocked, nil
+}
+
 func (s *OrderService) ListOrders(ctx context.Context, filter domain.OrderFilter) ([]domain.Order, int64, error) {
 	return s.orderRepo.List(ctx, filter)
 }
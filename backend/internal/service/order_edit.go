@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// EditOrderInput is the new content of an order. Items replace the current
+// items; omitted delivery fields keep their values.
+type EditOrderInput struct {
+	Items           []OrderItemInput `json:"items" binding:"required,min=1,dive"`
+	DeliveryMethod  *string          `json:"deliveryMethod" binding:"omitempty,oneof=pickup courier pickup_point"`
+	DeliveryAddress *string          `json:"deliveryAddress"`
+	PickupPointID   *int             `json:"pickupPointId"`
+	// City is needed to recalculate courier delivery. Without it the current
+	// delivery cost is kept.
+	City *string `json:"city"`
+	// RemovePromo drops the promo code, e.g. when the order no longer reaches
+	// its minimum amount.
+	RemovePromo bool    `json:"removePromo"`
+	Comment     *string `json:"comment"`
+	// EditedBy is the admin making the change, set by the handler.
+	EditedBy *int `json:"-"`
+}
+
+// EditOrderResult is the edited order and its log entry. PaymentError is set
+// when the order was saved but the payment link or refund failed and has to
+// be handled manually.
+type EditOrderResult struct {
+	Order        *domain.Order       `json:"order"`
+	Change       *domain.OrderChange `json:"change"`
+	PaymentError string              `json:"paymentError,omitempty"`
+}
+
+// SetOrderChangeRepo sets the repository of the order edit log.
+func (s *OrderService) SetOrderChangeRepo(repo domain.OrderChangeRepository) {
+	s.changeRepo = repo
+}
+
+// ListChanges returns the edit log of an order, newest first.
+func (s *OrderService) ListChanges(ctx context.Context, orderID int) ([]domain.OrderChange, error) {
+	if _, err := s.orderRepo.FindByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if s.changeRepo == nil {
+		return []domain.OrderChange{}, nil
+	}
+	return s.changeRepo.ListByOrderID(ctx, orderID)
+}
+
+// EditOrder replaces the items and delivery of an order that has not been
+// shipped yet. The order is repriced by the same rules as CreateOrder, with
+// the stock it already holds counted as available; the stock difference,
+// returned bonuses and the log entry are saved in one transaction. If the
+// total no longer matches what was paid, a payment link for the difference
+// is issued or the overpayment is refunded.
+func (s *OrderService) EditOrder(ctx context.Context, id int, input EditOrderInput) (*EditOrderResult, error) {
+	order, err := s.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.OrderType != "regular" || !slices.Contains(domain.EditableOrderStatuses, order.Status) {
+		return nil, domain.ErrOrderNotEditable
+	}
+
+	// Used to price the edit; the stock difference is taken from the locked rows.
+	reserved, _ := heldQuantities(order.Items)
+
+	method := order.DeliveryMethod
+	if input.DeliveryMethod != nil {
+		method = *input.DeliveryMethod
+	}
+	if method == domain.DeliveryMethodDigital {
+		// Let the quote decide again whether the order is still digital-only.
+		method = ""
+	}
+	promoCode := order.PromoCode
+	if input.RemovePromo {
+		promoCode = nil
+	}
+
+	quote, err := s.pricing.Quote(ctx, PricingInput{
+		Items:          mergeOrderItems(input.Items),
+		DeliveryMethod: method,
+		City:           input.City,
+		PromoCode:      promoCode,
+		PromoApplied:   promoCode != nil,
+		Reserved:       reserved,
+		UserID:         order.UserID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load products: %w", err)
+	}
+	if err := quote.Err(); err != nil {
+		return nil, err
+	}
+
+	// Courier cost depends on the city, which orders do not store.
+	if quote.DeliveryMethod == "courier" && order.DeliveryMethod == "courier" && (input.City == nil || *input.City == "") {
+		quote.DeliveryCost = order.DeliveryCost
+		quote.DeliveryProvider = order.DeliveryProvider
+		quote.TotalPrice = math.Round((quote.TotalPrice+order.DeliveryCost)*100) / 100
+	}
+	if quote.EstimatedDelivery == nil {
+		quote.EstimatedDelivery = order.EstimatedDelivery
+	}
+
+	// Bonuses already spent stay on the order up to the new cap; the rest is returned.
+	if order.BonusDiscount > 0 {
+		quote.ApplyBonuses(order.BonusDiscount, order.BonusDiscount)
+	}
+	bonusReturn := math.Round((order.BonusDiscount-quote.BonusDiscount)*100) / 100
+
+	deliveryAddress := order.DeliveryAddress
+	if input.DeliveryAddress != nil {
+		deliveryAddress = input.DeliveryAddress
+	}
+	pickupPointID := order.PickupPointID
+	if input.PickupPointID != nil {
+		pickupPointID = input.PickupPointID
+	}
+	switch quote.DeliveryMethod {
+	case "courier":
+		pickupPointID = nil
+	case "pickup_point":
+		deliveryAddress = nil
+	default:
+		deliveryAddress, pickupPointID = nil, nil
+	}
+
+	items, stockTake := orderItemsFromQuote(quote)
+	newQuantity := make(map[int]int)
+	for _, line := range quote.Lines {
+		newQuantity[line.ProductID] += line.Quantity
+	}
+
+	// Decide how the payment follows the new total.
+	isPaid := order.IsPaid
+	paidAmount := order.PaidAmount
+	action := domain.OrderPaymentActionNone
+	var paymentAmount float64
+	switch {
+	case paidAmount > quote.TotalPrice:
+		action = domain.OrderPaymentActionRefund
+		paymentAmount = math.Round((paidAmount-quote.TotalPrice)*100) / 100
+		if order.PaymentMethod != "card" {
+			// Cash is handed back by staff; card refunds lower the amount once they succeed.
+			paidAmount = quote.TotalPrice
+		}
+	case paidAmount > 0 && paidAmount < quote.TotalPrice:
+		isPaid = false
+		if order.PaymentMethod == "card" {
+			action = domain.OrderPaymentActionLink
+			paymentAmount = math.Round((quote.TotalPrice-paidAmount)*100) / 100
+		}
+	case paidAmount == 0 && order.PaymentMethod == "card" && order.PaymentLink != nil && quote.TotalPrice != order.TotalPrice:
+		action = domain.OrderPaymentActionLink
+		paymentAmount = quote.TotalPrice
+	}
+	if paidAmount > 0 && paidAmount >= quote.TotalPrice {
+		isPaid = true
+	}
+
+	edited := *order
+	edited.Items = items
+	edited.DeliveryMethod = quote.DeliveryMethod
+	edited.DeliveryAddress = deliveryAddress
+	edited.PickupPointID = pickupPointID
+	edited.PromoCode = quote.PromoCode
+	edited.Subtotal = quote.Subtotal
+	edited.DiscountAmount = quote.DiscountAmount
+	edited.DeliveryCost = quote.DeliveryCost
+	edited.BonusDiscount = quote.BonusDiscount
+	edited.TotalPrice = quote.TotalPrice
+
+	change := &domain.OrderChange{
+		OrderID:       order.ID,
+		UserID:        input.EditedBy,
+		Changes:       domain.DiffOrderSnapshots(domain.SnapshotOrder(order), domain.SnapshotOrder(&edited)),
+		OldTotal:      order.TotalPrice,
+		NewTotal:      quote.TotalPrice,
+		PaymentAction: action,
+		PaymentAmount: paymentAmount,
+		Comment:       input.Comment,
+	}
+
+	var restocked []*domain.StockMovement
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the order so that concurrent edits, payments and cancellations
+		// apply their changes one after another, each on the current items.
+		var locked domain.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Items").
+			First(&locked, order.ID).Error; err != nil {
+			return fmt.Errorf("lock order: %w", err)
+		}
+		if !slices.Contains(domain.EditableOrderStatuses, locked.Status) {
+			return domain.ErrOrderNotEditable
+		}
+		if locked.PaidAmount != order.PaidAmount || locked.IsPaid != order.IsPaid {
+			// The payment decision above is based on the old amount.
+			return domain.ErrOrderChanged
+		}
+		reserved, oldQuantity := heldQuantities(locked.Items)
+
+		res := tx.Model(&domain.Order{}).
+			Where("id = ? AND status IN ?", order.ID, domain.EditableOrderStatuses).
+			Updates(map[string]interface{}{
+				"subtotal":           quote.Subtotal,
+				"discount_amount":    quote.DiscountAmount,
+				"delivery_cost":      quote.DeliveryCost,
+				"bonus_discount":     quote.BonusDiscount,
+				"total_price":        quote.TotalPrice,
+				"promo_code":         quote.PromoCode,
+				"delivery_method":    quote.DeliveryMethod,
+				"delivery_address":   deliveryAddress,
+				"pickup_point_id":    pickupPointID,
+				"delivery_provider":  quote.DeliveryProvider,
+				"estimated_delivery": quote.EstimatedDelivery,
+				"is_paid":            isPaid,
+				"paid_amount":        paidAmount,
+				"updated_at":         time.Now(),
+			})
+		if res.Error != nil {
+			return fmt.Errorf("update order: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrOrderNotEditable
+		}
+
+		if err := tx.Where("order_id = ?", order.ID).Delete(&domain.OrderItem{}).Error; err != nil {
+			return fmt.Errorf("delete order items: %w", err)
+		}
+		for i := range items {
+			items[i].OrderID = order.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return fmt.Errorf("create order items: %w", err)
+		}
+
+		// Apply the stock difference, in product order to avoid deadlocks.
+		// Units taken off the order go back to stock as a cancellation.
+		for _, productID := range sortedKeys(reserved, stockTake) {
+			delta := stockTake[productID] - reserved[productID]
+			if delta == 0 {
+				continue
+			}
+			m := &domain.StockMovement{
+				ProductID: productID,
+				Type:      domain.StockMovementSale,
+				Quantity:  -delta,
+				OrderID:   &order.ID,
+				CreatedBy: input.EditedBy,
+			}
+			if delta < 0 {
+				m.Type = domain.StockMovementCancellation
+				restocked = append(restocked, m)
+			}
+			if err := moveStock(tx, m); err != nil {
+				return err
+			}
+		}
+
+		// Sales already counted for a paid order follow its items.
+		if locked.SalesCounted {
+			for _, productID := range sortedKeys(oldQuantity, newQuantity) {
+				delta := newQuantity[productID] - oldQuantity[productID]
+				if delta == 0 {
+					continue
+				}
+				if err := tx.Model(&domain.Product{}).
+					Where("id = ?", productID).
+					UpdateColumn("sales_count", gorm.Expr("GREATEST(sales_count + ?, 0)", delta)).Error; err != nil {
+					return fmt.Errorf("update sales count: %w", err)
+				}
+			}
+		}
+
+		if bonusReturn > 0 && order.UserID != nil && s.loyaltyService != nil {
+			if err := s.loyaltyService.ReturnExcessBonuses(ctx, tx, *order.UserID, bonusReturn, order.ID); err != nil {
+				return err
+			}
+		}
+
+		if order.PromoCode != nil && quote.PromoCode == nil {
+			if err := tx.Model(&domain.PromoCode{}).
+				Where("UPPER(code) = UPPER(?) AND used_count > 0", *order.PromoCode).
+				UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+				return fmt.Errorf("release promo code: %w", err)
+			}
+		}
+
+		if err := tx.Create(change).Error; err != nil {
+			return fmt.Errorf("log order change: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("order edited",
+		zap.String("orderNumber", order.OrderNumber),
+		zap.Float64("oldTotal", order.TotalPrice),
+		zap.Float64("newTotal", quote.TotalPrice),
+		zap.String("paymentAction", action),
+	)
+	s.stock.Changed(restocked...)
+
+	result := &EditOrderResult{Change: change}
+	if err := s.settleEdit(ctx, order.ID, order.IsPaid, action, paymentAmount); err != nil {
+		s.log.Warn("failed to settle order edit payment",
+			zap.String("orderNumber", order.OrderNumber),
+			zap.String("paymentAction", action),
+			zap.Error(err),
+		)
+		result.PaymentError = err.Error()
+	}
+
+	result.Order, err = s.orderRepo.FindByID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// settleEdit issues the payment link or card refund decided by EditOrder.
+// wasPaid is whether the order was fully paid before the edit.
+func (s *OrderService) settleEdit(ctx context.Context, orderID int, wasPaid bool, action string, amount float64) error {
+	if action == domain.OrderPaymentActionNone || s.paymentService == nil {
+		return nil
+	}
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case domain.OrderPaymentActionLink:
+		if wasPaid {
+			// The previous payment succeeded and must not be cancelled;
+			// InitiatePayment charges only the difference.
+			_, err = s.paymentService.InitiatePayment(ctx, order)
+		} else {
+			// Replaces the pending link; a partly paid order is charged the rest.
+			_, err = s.paymentService.RegeneratePaymentLink(ctx, order.ID)
+		}
+		return err
+	case domain.OrderPaymentActionRefund:
+		if order.PaymentMethod != "card" {
+			return nil
+		}
+		return s.paymentService.Refund(ctx, s.db, order, amount)
+	}
+	return nil
+}
+
+// heldQuantities returns, per product, the units an order took from stock
+// and the units ordered. Digital and printed units hold no stock.
+func heldQuantities(items []domain.OrderItem) (reserved, quantity map[int]int) {
+	reserved = make(map[int]int)
+	quantity = make(map[int]int)
+	for _, item := range items {
+		if item.ProductID == nil {
+			continue
+		}
+		reserved[*item.ProductID] += item.StockQuantity
+		quantity[*item.ProductID] += item.Quantity
+	}
+	return reserved, quantity
+}
+
+// mergeOrderItems sums the quantities of repeated products.
+func mergeOrderItems(items []OrderItemInput) []OrderItemInput {
+	merged := make([]OrderItemInput, 0, len(items))
+	index := make(map[int]int)
+	for _, item := range items {
+		if i, ok := index[item.ProductID]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// sortedKeys returns the keys of both maps in ascending order.
+func sortedKeys(a, b map[int]int) []int {
+	keys := make([]int, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+func TestHeldQuantities(t *testing.T) {
+	p1, p2, digital := 1, 2, 3
+	items := []domain.OrderItem{
+		{ProductID: &p1, Quantity: 2, StockQuantity: 2},
+		{ProductID: &p1, Quantity: 3, StockQuantity: 1, ProductionQuantity: 2},
+		{ProductID: &p2, Quantity: 4, ProductionQuantity: 4},
+		{ProductID: &digital, Quantity: 1},
+		{Quantity: 1}, // custom item
+	}
+
+	reserved, quantity := heldQuantities(items)
+	if want := map[int]int{p1: 3, p2: 0, digital: 0}; !reflect.DeepEqual(reserved, want) {
+		t.Errorf("reserved = %v, want %v", reserved, want)
+	}
+	if want := map[int]int{p1: 5, p2: 4, digital: 1}; !reflect.DeepEqual(quantity, want) {
+		t.Errorf("quantity = %v, want %v", quantity, want)
+	}
+}
+
+func TestMergeOrderItems(t *testing.T) {
+	got := mergeOrderItems([]OrderItemInput{
+		{ProductID: 1, Quantity: 2},
+		{ProductID: 2, Quantity: 1},
+		{ProductID: 1, Quantity: 3},
+	})
+	want := []OrderItemInput{{ProductID: 1, Quantity: 5}, {ProductID: 2, Quantity: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeOrderItems = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+const (
+	unpaidOrdersCheckInterval = time.Minute
+	unpaidOrdersBatchSize     = 50
+	unpaidCancelReason        = "Оплата не поступила вовремя"
+)
+
+// SetOrderSettingsRepo enables admin-editable order settings, including the
+// automatic cancellation of unpaid card orders.
+func (s *OrderService) SetOrderSettingsRepo(repo domain.OrderSettingsRepository) {
+	s.settingsRepo = repo
+}
+
+// GetSettings returns order settings.
+func (s *OrderService) GetSettings(ctx context.Context) (*domain.OrderSettings, error) {
+	return s.settingsRepo.Get(ctx)
+}
+
+// UpdateOrderSettingsInput is the input for updating order settings.
+type UpdateOrderSettingsInput struct {
+	UnpaidCancelEnabled *bool `json:"unpaidCancelEnabled"`
+	UnpaidGraceMinutes  *int  `json:"unpaidGraceMinutes" binding:"omitempty,min=0,max=10080"`
+}
+
+// UpdateSettings updates order settings.
+func (s *OrderService) UpdateSettings(ctx context.Context, input UpdateOrderSettingsInput) (*domain.OrderSettings, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.UnpaidCancelEnabled != nil {
+		settings.UnpaidCancelEnabled = *input.UnpaidCancelEnabled
+	}
+	if input.UnpaidGraceMinutes != nil {
+		settings.UnpaidGraceMinutes = *input.UnpaidGraceMinutes
+	}
+	settings.UpdatedAt = time.Now()
+
+	if err := s.settingsRepo.Update(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("order settings updated",
+		zap.Bool("unpaidCancelEnabled", settings.UnpaidCancelEnabled),
+		zap.Int("unpaidGraceMinutes", settings.UnpaidGraceMinutes),
+	)
+	return settings, nil
+}
+
+// StartUnpaidOrderCanceller periodically cancels card orders that were not
+// paid before their payment link expired. Blocks until ctx is cancelled.
+func (s *OrderService) StartUnpaidOrderCanceller(ctx context.Context) {
+	s.log.Info("starting unpaid order canceller")
+
+	ticker := time.NewTicker(unpaidOrdersCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("stopping unpaid order canceller")
+			return
+		case <-ticker.C:
+			if _, err := s.CancelExpiredUnpaid(ctx); err != nil {
+				s.log.Error("unpaid order check failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// CancelExpiredUnpaid cancels unpaid card orders whose payment link expired
+// more than the configured grace period ago. Returns the number cancelled.
+func (s *OrderService) CancelExpiredUnpaid(ctx context.Context) (int, error) {
+	if s.settingsRepo == nil {
+		return 0, nil
+	}
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("load order settings: %w", err)
+	}
+	if !settings.UnpaidCancelEnabled {
+		return 0, nil
+	}
+
+	before := time.Now().Add(-time.Duration(settings.UnpaidGraceMinutes) * time.Minute)
+	orders, err := s.orderRepo.FindExpiredUnpaid(ctx, before, unpaidOrdersBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("find expired unpaid orders: %w", err)
+	}
+
+	cancelled := 0
+	for i := range orders {
+		ok, err := s.cancelUnpaid(ctx, &orders[i])
+		if err != nil {
+			s.log.Warn("failed to cancel unpaid order",
+				zap.String("orderNumber", orders[i].OrderNumber), zap.Error(err))
+			continue
+		}
+		if ok {
+			cancelled++
+		}
+	}
+	if cancelled > 0 {
+		s.log.Info("unpaid orders cancelled", zap.Int("count", cancelled))
+	}
+	return cancelled, nil
+}
+
+// errOrderNoLongerUnpaid aborts a cancellation when the order was paid or
+// changed by an admin in the meantime.
+var errOrderNoLongerUnpaid = errors.New("order is no longer new and unpaid")
+
+// cancelUnpaid cancels the payment, then the order: stock, bonuses and the
+// promo code use are given back. Returns false if the order turned out to be paid.
+func (s *OrderService) cancelUnpaid(ctx context.Context, order *domain.Order) (bool, error) {
+	if s.paymentService != nil {
+		paid, err := s.paymentService.CancelPendingPayment(ctx, order)
+		if err != nil {
+			return false, err
+		}
+		if paid {
+			s.log.Info("expired order was paid, not cancelling", zap.String("orderNumber", order.OrderNumber))
+			return false, nil
+		}
+	}
+
+	now := time.Now()
+	reason := unpaidCancelReason
+	var restocked []*domain.StockMovement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Order{}).
+			Where("id = ? AND status = 'new' AND is_paid = false AND paid_amount = 0", order.ID).
+			Updates(map[string]interface{}{
+				"status":        "cancelled",
+				"cancel_reason": reason,
+				"cancelled_at":  now,
+				"updated_at":    now,
+			})
+		if res.Error != nil {
+			return fmt.Errorf("cancel order: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return errOrderNoLongerUnpaid
+		}
+
+		var err error
+		if restocked, err = restoreOrderStock(tx, order); err != nil {
+			return err
+		}
+
+		if order.BonusDiscount > 0 && order.UserID != nil && s.loyaltyService != nil {
+			if err := s.loyaltyService.RefundBonuses(ctx, tx, *order.UserID, order.BonusDiscount, order.ID); err != nil {
+				return err
+			}
+		}
+
+		if order.PromoCode != nil {
+			if err := tx.Model(&domain.PromoCode{}).
+				Where("UPPER(code) = UPPER(?) AND used_count > 0", *order.PromoCode).
+				UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+				return fmt.Errorf("release promo code: %w", err)
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errOrderNoLongerUnpaid) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	s.log.Info("unpaid order cancelled", zap.String("orderNumber", order.OrderNumber))
+	s.stock.Changed(restocked...)
+
+	cancelled, err := s.orderRepo.FindByID(ctx, order.ID)
+	if err != nil {
+		s.log.Warn("failed to load cancelled order for notification", zap.Error(err))
+		return true, nil
+	}
+	if s.notifier != nil {
+		if err := s.notifier.NotifyOrderStatusChanged(ctx, cancelled); err != nil {
+			s.log.Warn("failed to send status notification", zap.Error(err))
+		}
+	}
+	if s.emailService != nil {
+		s.emailService.SendOrderStatusChanged(cancelled)
+	}
+	return true, nil
+}
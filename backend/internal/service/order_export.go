@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// exportPageSize is the number of orders loaded per query during an export.
+const exportPageSize = 100
+
+var orderExportHeader = []string{
+	"Номер", "Дата", "Статус", "Тип", "Покупатель", "Телефон", "Email",
+	"Компания", "ИНН", "КПП", "Способ оплаты", "Оплачен", "Сумма", "Оплачено",
+	"Платёжное поручение", "Доставка",
+}
+
+// ExportOrdersCSV writes the orders matching the filter as CSV for Excel:
+// UTF-8 with BOM, ";" separated, decimal comma. Page and Limit are ignored.
+func (s *OrderService) ExportOrdersCSV(ctx context.Context, filter domain.OrderFilter, w io.Writer) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	if err := cw.Write(orderExportHeader); err != nil {
+		return err
+	}
+
+	filter.Limit = exportPageSize
+	for filter.Page = 1; ; filter.Page++ {
+		orders, total, err := s.orderRepo.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("list orders for export: %w", err)
+		}
+		for i := range orders {
+			if err := cw.Write(orderExportRow(&orders[i])); err != nil {
+				return err
+			}
+		}
+		if len(orders) < exportPageSize || int64(filter.Page*exportPageSize) >= total {
+			break
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func orderExportRow(o *domain.Order) []string {
+	var companyName, inn, kpp string
+	if c := o.Company; c != nil {
+		companyName, inn = c.LegalName, c.INN
+		if c.KPP != nil {
+			kpp = *c.KPP
+		}
+	}
+	paid := "нет"
+	if o.IsPaid {
+		paid = "да"
+	}
+	return []string{
+		o.OrderNumber,
+		o.CreatedAt.Format("02.01.2006 15:04"),
+		statusToRussian(o.Status),
+		o.OrderType,
+		o.CustomerName,
+		o.CustomerPhone,
+		derefString(o.CustomerEmail),
+		companyName,
+		inn,
+		kpp,
+		paymentMethodRu(o.PaymentMethod),
+		paid,
+		csvAmount(o.TotalPrice),
+		csvAmount(o.PaidAmount),
+		derefString(o.PaymentReference),
+		deliveryMethodRu(o.DeliveryMethod),
+	}
+}
+
+// csvAmount formats money with a decimal comma, as Excel expects in the ru locale.
+func csvAmount(v float64) string {
+	return strings.Replace(fmt.Sprintf("%.2f", v), ".", ",", 1)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// Order number series. Each has its own daily counter and prefix.
+const (
+	orderSeriesRegular = "regular"
+	orderSeriesCustom  = "custom"
+)
+
+// OrderNumberGenerator issues order numbers of the form PREFIX-YYYYMMDD-NNNN,
+// optionally followed by a Luhn check digit.
+type OrderNumberGenerator struct {
+	orderRepo domain.OrderRepository
+	cfg       config.OrderNumberConfig
+}
+
+func NewOrderNumberGenerator(orderRepo domain.OrderRepository, cfg config.OrderNumberConfig) *OrderNumberGenerator {
+	return &OrderNumberGenerator{orderRepo: orderRepo, cfg: cfg}
+}
+
+// defaultOrderNumbers is used until a configured generator is set.
+func defaultOrderNumbers(orderRepo domain.OrderRepository) *OrderNumberGenerator {
+	return NewOrderNumberGenerator(orderRepo, config.OrderNumberConfig{Prefix: "ORD", CustomPrefix: "CUS", Digits: 4})
+}
+
+// Next returns the next number of a series.
+func (g *OrderNumberGenerator) Next(ctx context.Context, series string) (string, error) {
+	prefix := g.cfg.Prefix
+	if series == orderSeriesCustom {
+		prefix = g.cfg.CustomPrefix
+	}
+
+	day := time.Now()
+	seq, err := g.orderRepo.NextOrderSequence(ctx, series, day)
+	if err != nil {
+		return "", err
+	}
+
+	digits := fmt.Sprintf("%s%0*d", day.Format("20060102"), g.cfg.Digits, seq)
+	number := fmt.Sprintf("%s-%s-%s", prefix, digits[:8], digits[8:])
+	if g.cfg.CheckDigit {
+		number += fmt.Sprint(luhnCheckDigit(digits))
+	}
+	return number, nil
+}
+
+// luhnCheckDigit computes the Luhn check digit of a string of decimal digits.
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// sequenceRepo returns a fixed counter value; other repository methods are not used.
+type sequenceRepo struct {
+	domain.OrderRepository
+	seq    int
+	series string
+}
+
+func (r *sequenceRepo) NextOrderSequence(_ context.Context, series string, _ time.Time) (int, error) {
+	r.series = series
+	return r.seq, nil
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+	}{
+		{"7992739871", 3},
+		{"411111111111111", 1},
+		{"0", 0},
+		{"2026101800001", 2},
+		{"2026101800010", 3},
+	}
+	for _, tt := range tests {
+		if got := luhnCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("luhnCheckDigit(%q) = %d, want %d", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestLuhnCheckDigit_DetectsSingleDigitErrors(t *testing.T) {
+	digits := "2026101800042"
+	check := luhnCheckDigit(digits)
+	for i := range digits {
+		for d := byte('0'); d <= '9'; d++ {
+			if d == digits[i] {
+				continue
+			}
+			typo := digits[:i] + string(d) + digits[i+1:]
+			if luhnCheckDigit(typo) == check {
+				t.Errorf("typo %q has the same check digit as %q", typo, digits)
+			}
+		}
+	}
+}
+
+func TestOrderNumberGenerator_Next(t *testing.T) {
+	day := time.Now().Format("20060102")
+	tests := []struct {
+		name   string
+		cfg    config.OrderNumberConfig
+		series string
+		seq    int
+		want   string
+	}{
+		{
+			name:   "regular",
+			cfg:    config.OrderNumberConfig{Prefix: "ORD", CustomPrefix: "CUS", Digits: 4},
+			series: orderSeriesRegular,
+			seq:    7,
+			want:   `^ORD-` + day + `-0007$`,
+		},
+		{
+			name:   "custom series prefix",
+			cfg:    config.OrderNumberConfig{Prefix: "ORD", CustomPrefix: "CUS", Digits: 4},
+			series: orderSeriesCustom,
+			seq:    12,
+			want:   `^CUS-` + day + `-0012$`,
+		},
+		{
+			name:   "counter wider than digits",
+			cfg:    config.OrderNumberConfig{Prefix: "ORD", CustomPrefix: "CUS", Digits: 3},
+			series: orderSeriesRegular,
+			seq:    12345,
+			want:   `^ORD-` + day + `-12345$`,
+		},
+		{
+			name:   "with check digit",
+			cfg:    config.OrderNumberConfig{Prefix: "ORD", CustomPrefix: "CUS", Digits: 4, CheckDigit: true},
+			series: orderSeriesRegular,
+			seq:    42,
+			want:   `^ORD-` + day + `-0042[0-9]$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &sequenceRepo{seq: tt.seq}
+			got, err := NewOrderNumberGenerator(repo, tt.cfg).Next(context.Background(), tt.series)
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if !regexp.MustCompile(tt.want).MatchString(got) {
+				t.Errorf("Next = %q, want match %s", got, tt.want)
+			}
+			if repo.series != tt.series {
+				t.Errorf("counter series = %q, want %q", repo.series, tt.series)
+			}
+			if tt.cfg.CheckDigit {
+				digits := regexp.MustCompile(`[^0-9]`).ReplaceAllString(got, "")
+				body, check := digits[:len(digits)-1], int(digits[len(digits)-1]-'0')
+				if luhnCheckDigit(body) != check {
+					t.Errorf("Next = %q: check digit %d, want %d", got, check, luhnCheckDigit(body))
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -20,6 +22,8 @@ type PaymentService struct {
 	db        *gorm.DB
 	log       *zap.Logger
 	appURL    string // used to build return URLs and mock confirmation links
+	digital   *DigitalService
+	stats     *ProductStatsService
 }
 
 func NewPaymentService(
@@ -38,6 +42,16 @@ func NewPaymentService(
 	}
 }
 
+// SetDigitalService enables issuing download links once a digital order is paid.
+func (s *PaymentService) SetDigitalService(ds *DigitalService) {
+	s.digital = ds
+}
+
+// SetProductStatsService enables counting sales when an order is paid.
+func (s *PaymentService) SetProductStatsService(ps *ProductStatsService) {
+	s.stats = ps
+}
+
 // ProviderName returns the name of the active payment provider.
 func (s *PaymentService) ProviderName() string {
 	return s.provider.Name()
@@ -46,14 +60,18 @@ func (s *PaymentService) ProviderName() string {
 // InitiatePayment creates a payment for the given order, saves the payment link
 // to the database, and returns the payment URL.
 // Called automatically after order creation when payment_method == "card".
+// If part of the order is already paid, the link is for the rest.
 func (s *PaymentService) InitiatePayment(ctx context.Context, order *domain.Order) (string, error) {
 	description := fmt.Sprintf("Заказ %s — АВАНГАРД 3D Print", order.OrderNumber)
+	if order.PaidAmount > 0 {
+		description = fmt.Sprintf("Доплата по заказу %s — АВАНГАРД 3D Print", order.OrderNumber)
+	}
 	returnURL := fmt.Sprintf("%s/order/%s", s.appURL, order.OrderNumber)
 
 	result, err := s.provider.CreatePayment(ctx, payment.CreatePaymentInput{
 		OrderID:       order.ID,
 		OrderNumber:   order.OrderNumber,
-		Amount:        order.TotalPrice,
+		Amount:        math.Round((order.TotalPrice-order.PaidAmount)*100) / 100,
 		Description:   description,
 		CustomerEmail: order.CustomerEmail,
 		ReturnURL:     returnURL,
@@ -121,8 +139,10 @@ func (s *PaymentService) HandleWebhook(ctx context.Context, body []byte, headers
 		Model(&domain.Order{}).
 		Where("id = ?", order.ID).
 		Updates(map[string]interface{}{
-			"is_paid":    true,
-			"updated_at": now,
+			"is_paid":             true,
+			"paid_amount":         gorm.Expr("total_price"),
+			"captured_payment_id": gorm.Expr("COALESCE(captured_payment_id, payment_provider_id)"),
+			"updated_at":          now,
 		}).Error; err != nil {
 		return fmt.Errorf("mark order %d as paid: %w", order.ID, err)
 	}
@@ -132,6 +152,7 @@ func (s *PaymentService) HandleWebhook(ctx context.Context, body []byte, headers
 		zap.String("provider", s.provider.Name()),
 	)
 
+	s.onPaid(order.ID)
 	return nil
 }
 
@@ -142,8 +163,10 @@ func (s *PaymentService) MarkPaidByOrderNumber(ctx context.Context, orderNumber
 		Model(&domain.Order{}).
 		Where("order_number = ? AND is_paid = false", orderNumber).
 		Updates(map[string]interface{}{
-			"is_paid":    true,
-			"updated_at": time.Now(),
+			"is_paid":             true,
+			"paid_amount":         gorm.Expr("total_price"),
+			"captured_payment_id": gorm.Expr("COALESCE(captured_payment_id, payment_provider_id)"),
+			"updated_at":          time.Now(),
 		})
 	if result.Error != nil {
 		return fmt.Errorf("mark order %s as paid: %w", orderNumber, result.Error)
@@ -156,6 +179,165 @@ func (s *PaymentService) MarkPaidByOrderNumber(ctx context.Context, orderNumber
 		zap.String("orderNumber", orderNumber),
 		zap.String("provider", s.provider.Name()),
 	)
+
+	if order, err := s.orderRepo.FindByOrderNumber(ctx, orderNumber); err == nil {
+		s.onPaid(order.ID)
+	}
+	return nil
+}
+
+// InvoicePaymentInput records a bank transfer received against an invoice.
+// Amount defaults to the rest of the invoice; a smaller amount is a partial payment.
+type InvoicePaymentInput struct {
+	PaymentReference string   `json:"paymentReference" binding:"required,max=100"` // payment order number
+	Amount           *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// RecordInvoicePayment registers a bank transfer for an order paid by invoice.
+// The invoice number is the order number printed on the bank-transfer invoice.
+// The order becomes paid once transfers cover its total.
+func (s *PaymentService) RecordInvoicePayment(ctx context.Context, invoiceNumber string, input InvoicePaymentInput) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByOrderNumber(ctx, invoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	if order.PaymentMethod != domain.PaymentMethodInvoice {
+		return nil, domain.ErrOrderNotFound
+	}
+	if order.IsPaid {
+		return nil, domain.ErrOrderAlreadyPaid
+	}
+	if order.Status == "cancelled" {
+		return nil, domain.ErrOrderStatusInvalid
+	}
+
+	due := math.Round((order.TotalPrice-order.PaidAmount)*100) / 100
+	amount := due
+	if input.Amount != nil {
+		amount = math.Round(*input.Amount*100) / 100
+	}
+	if amount <= 0 || amount > due {
+		return nil, domain.ErrPaymentAmountInvalid
+	}
+
+	reference := strings.TrimSpace(input.PaymentReference)
+	if order.PaymentReference != nil && *order.PaymentReference != "" {
+		reference = *order.PaymentReference + ", " + reference
+	}
+	paid := amount >= due
+
+	// Guarded by paid_amount so two concurrent registrations cannot both apply.
+	result := s.db.WithContext(ctx).
+		Model(&domain.Order{}).
+		Where("id = ? AND is_paid = false AND paid_amount = ?", order.ID, order.PaidAmount).
+		Updates(map[string]interface{}{
+			"paid_amount":       gorm.Expr("paid_amount + ?", amount),
+			"is_paid":           paid,
+			"payment_reference": reference,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("record invoice payment %s: %w", invoiceNumber, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("order %s was changed concurrently, retry", invoiceNumber)
+	}
+
+	s.log.Info("invoice payment recorded",
+		zap.String("orderNumber", order.OrderNumber),
+		zap.Float64("amount", amount),
+		zap.String("paymentReference", input.PaymentReference),
+		zap.Bool("paid", paid),
+	)
+
+	if paid {
+		s.onPaid(order.ID)
+	}
+	return s.orderRepo.FindByID(ctx, order.ID)
+}
+
+// onPaid runs post-payment actions asynchronously: sales counters and delivery of digital files.
+func (s *PaymentService) onPaid(orderID int) {
+	go func() {
+		bgCtx := context.Background()
+		if s.stats != nil {
+			if err := s.stats.CountSale(bgCtx, orderID); err != nil {
+				s.log.Warn("failed to count sale", zap.Int("orderId", orderID), zap.Error(err))
+			}
+		}
+		if s.digital != nil {
+			if err := s.digital.IssueForOrder(bgCtx, orderID); err != nil {
+				s.log.Warn("failed to issue download links", zap.Int("orderId", orderID), zap.Error(err))
+			}
+		}
+	}()
+}
+
+// CancelPendingPayment cancels the payment of an order that is about to be
+// cancelled for non-payment. If the provider reports it as succeeded (the
+// webhook was lost), the order is marked paid instead and paid is true.
+func (s *PaymentService) CancelPendingPayment(ctx context.Context, order *domain.Order) (paid bool, err error) {
+	if order.PaymentProviderID == nil || *order.PaymentProviderID == "" {
+		return false, nil
+	}
+	providerID := *order.PaymentProviderID
+
+	status, err := s.provider.GetPaymentStatus(ctx, providerID)
+	if err != nil {
+		return false, fmt.Errorf("get payment status: %w", err)
+	}
+	switch status.Status {
+	case "succeeded":
+		if err := s.MarkPaidByOrderNumber(ctx, order.OrderNumber); err != nil {
+			return false, err
+		}
+		return true, nil
+	case "cancelled", "refunded":
+		return false, nil
+	}
+
+	// An expired payment may no longer be cancellable; the link is dead either way.
+	if err := s.provider.CancelPayment(ctx, providerID); err != nil {
+		s.log.Warn("failed to cancel expired payment at provider",
+			zap.String("orderNumber", order.OrderNumber),
+			zap.Error(err),
+		)
+	}
+	return false, nil
+}
+
+// Refund returns amount of a paid card order to the customer through the
+// payment the order was paid with and lowers the order's paid amount through db, so the
+// caller can record the refund in its own transaction. A refusal of the
+// provider is reported as domain.ErrRefundDeclined; any other error means the
+// money may already be on its way back.
+func (s *PaymentService) Refund(ctx context.Context, db *gorm.DB, order *domain.Order, amount float64) error {
+	paymentID := order.CapturedPaymentID
+	if paymentID == nil {
+		// Paid before captured payments were recorded.
+		paymentID = order.PaymentProviderID
+	}
+	if paymentID == nil || *paymentID == "" {
+		return fmt.Errorf("order %s has no payment to refund", order.OrderNumber)
+	}
+	if err := s.provider.RefundPayment(ctx, *paymentID, amount); err != nil {
+		return fmt.Errorf("%w via %s: %w", domain.ErrRefundDeclined, s.provider.Name(), err)
+	}
+
+	if err := db.WithContext(ctx).
+		Model(&domain.Order{}).
+		Where("id = ?", order.ID).
+		Updates(map[string]interface{}{
+			"paid_amount": gorm.Expr("GREATEST(paid_amount - ?, 0)", amount),
+			"updated_at":  time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("save refund of order %d: %w", order.ID, err)
+	}
+
+	s.log.Info("payment refunded",
+		zap.String("orderNumber", order.OrderNumber),
+		zap.Float64("amount", amount),
+	)
 	return nil
 }
 
@@ -169,6 +351,9 @@ func (s *PaymentService) RegeneratePaymentLink(ctx context.Context, orderID int)
 	if order.IsPaid {
 		return "", fmt.Errorf("order %s is already paid", order.OrderNumber)
 	}
+	if order.PaymentMethod == domain.PaymentMethodInvoice {
+		return "", fmt.Errorf("order %s is paid by bank-transfer invoice", order.OrderNumber)
+	}
 
 	// Cancel existing payment at provider if one exists.
 	if order.PaymentProviderID != nil && *order.PaymentProviderID != "" {
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// PriceListInput represents a price list created or replaced by an admin.
+type PriceListInput struct {
+	Name            string  `json:"name" binding:"required,min=1,max=100"`
+	Description     *string `json:"description"`
+	DiscountPercent float64 `json:"discountPercent" binding:"gte=0,lt=100"`
+	IsActive        *bool   `json:"isActive"`
+}
+
+// PriceListItemInput is the price of a product in a price list from MinQuantity items (1 by default).
+type PriceListItemInput struct {
+	ProductID   int     `json:"productId" binding:"required"`
+	MinQuantity int     `json:"minQuantity" binding:"omitempty,min=1"`
+	Price       float64 `json:"price" binding:"required,gt=0"`
+}
+
+// SetPriceListItemsInput replaces all product prices of a price list.
+type SetPriceListItemsInput struct {
+	Items []PriceListItemInput `json:"items" binding:"dive"`
+}
+
+// PriceTierInput is a quantity price break of a product.
+type PriceTierInput struct {
+	MinQuantity int     `json:"minQuantity" binding:"required,min=2"`
+	Price       float64 `json:"price" binding:"required,gt=0"`
+}
+
+// SetPriceTiersInput replaces all quantity price breaks of a product.
+type SetPriceTiersInput struct {
+	Tiers []PriceTierInput `json:"tiers" binding:"dive"`
+}
+
+// AssignPriceListInput assigns a price list; null removes the assignment.
+type AssignPriceListInput struct {
+	PriceListID *int `json:"priceListId"`
+}
+
+// PriceListService manages quantity price breaks and customer price lists,
+// and resolves the unit price a customer pays for a quantity of a product.
+type PriceListService struct {
+	repo        domain.PriceListRepository
+	productRepo domain.ProductRepository
+	log         *zap.Logger
+}
+
+// NewPriceListService creates a new price list service.
+func NewPriceListService(repo domain.PriceListRepository, productRepo domain.ProductRepository, log *zap.Logger) *PriceListService {
+	return &PriceListService{repo: repo, productRepo: productRepo, log: log}
+}
+
+// List returns all price lists without their items.
+func (s *PriceListService) List(ctx context.Context) ([]domain.PriceList, error) {
+	return s.repo.List(ctx)
+}
+
+// GetByID returns a price list with its items.
+func (s *PriceListService) GetByID(ctx context.Context, id int) (*domain.PriceList, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// Create creates a price list. New lists are active unless isActive is false.
+func (s *PriceListService) Create(ctx context.Context, input PriceListInput) (*domain.PriceList, error) {
+	list := &domain.PriceList{IsActive: true}
+	if err := s.apply(ctx, list, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("create price list: %w", err)
+	}
+
+	s.log.Info("price list created", zap.Int("id", list.ID), zap.String("name", list.Name))
+	return list, nil
+}
+
+// Update replaces the name, description, discount and status of a price list.
+func (s *PriceListService) Update(ctx context.Context, id int, input PriceListInput) (*domain.PriceList, error) {
+	list, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, list, input); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, list); err != nil {
+		return nil, fmt.Errorf("update price list: %w", err)
+	}
+
+	s.log.Info("price list updated", zap.Int("id", list.ID))
+	return list, nil
+}
+
+func (s *PriceListService) apply(ctx context.Context, list *domain.PriceList, input PriceListInput) error {
+	name := strings.TrimSpace(input.Name)
+	existing, err := s.repo.FindByName(ctx, name)
+	if err == nil && existing.ID != list.ID {
+		return domain.ErrPriceListNameExists
+	}
+	if err != nil && !errors.Is(err, domain.ErrPriceListNotFound) {
+		return fmt.Errorf("check price list name: %w", err)
+	}
+
+	list.Name = name
+	list.Description = input.Description
+	list.DiscountPercent = input.DiscountPercent
+	if input.IsActive != nil {
+		list.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+// Delete removes a price list. Users and companies it was assigned to pay retail prices again.
+func (s *PriceListService) Delete(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.log.Info("price list deleted", zap.Int("id", id))
+	return nil
+}
+
+// SetItems replaces all product prices of a price list.
+func (s *PriceListService) SetItems(ctx context.Context, id int, input SetPriceListItemsInput) (*domain.PriceList, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.PriceListItem, 0, len(input.Items))
+	seen := make(map[[2]int]bool, len(input.Items))
+	productIDs := make([]int, 0, len(input.Items))
+	for _, in := range input.Items {
+		minQty := max(in.MinQuantity, 1)
+		key := [2]int{in.ProductID, minQty}
+		if seen[key] {
+			return nil, domain.ErrInvalidPriceTiers
+		}
+		seen[key] = true
+		productIDs = append(productIDs, in.ProductID)
+		items = append(items, domain.PriceListItem{
+			ProductID:   in.ProductID,
+			MinQuantity: minQty,
+			Price:       math.Round(in.Price*100) / 100,
+		})
+	}
+	if err := s.checkProducts(ctx, productIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceItems(ctx, id, items); err != nil {
+		return nil, fmt.Errorf("save price list items: %w", err)
+	}
+
+	s.log.Info("price list items updated", zap.Int("id", id), zap.Int("items", len(items)))
+	return s.repo.FindByID(ctx, id)
+}
+
+// checkProducts returns ErrProductNotFound unless all products exist.
+func (s *PriceListService) checkProducts(ctx context.Context, productIDs []int) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	products, err := s.productRepo.FindByIDs(ctx, productIDs)
+	if err != nil {
+		return fmt.Errorf("load products: %w", err)
+	}
+	found := make(map[int]bool, len(products))
+	for _, p := range products {
+		found[p.ID] = true
+	}
+	for _, id := range productIDs {
+		if !found[id] {
+			return domain.ErrProductNotFound
+		}
+	}
+	return nil
+}
+
+// ProductTiers returns the quantity price breaks of a product.
+func (s *PriceListService) ProductTiers(ctx context.Context, productID int) ([]domain.PriceTier, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.TiersForProducts(ctx, []int{productID})
+}
+
+// SetProductTiers replaces the quantity price breaks of a product.
+func (s *PriceListService) SetProductTiers(ctx context.Context, productID int, input SetPriceTiersInput) ([]domain.PriceTier, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	tiers := make([]domain.PriceTier, 0, len(input.Tiers))
+	seen := make(map[int]bool, len(input.Tiers))
+	for _, in := range input.Tiers {
+		if seen[in.MinQuantity] {
+			return nil, domain.ErrInvalidPriceTiers
+		}
+		seen[in.MinQuantity] = true
+		tiers = append(tiers, domain.PriceTier{
+			MinQuantity: in.MinQuantity,
+			Price:       math.Round(in.Price*100) / 100,
+		})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinQuantity < tiers[j].MinQuantity })
+
+	if err := s.repo.ReplaceTiers(ctx, productID, tiers); err != nil {
+		return nil, fmt.Errorf("save price tiers: %w", err)
+	}
+
+	s.log.Info("product price tiers updated", zap.Int("productId", productID), zap.Int("tiers", len(tiers)))
+	return tiers, nil
+}
+
+// AssignToUser sets the user's price list; a nil listID removes it.
+func (s *PriceListService) AssignToUser(ctx context.Context, userID int, listID *int) error {
+	if err := s.checkList(ctx, listID); err != nil {
+		return err
+	}
+	if err := s.repo.AssignToUser(ctx, userID, listID); err != nil {
+		return err
+	}
+	s.log.Info("price list assigned to user", zap.Int("userId", userID), zap.Any("priceListId", listID))
+	return nil
+}
+
+// AssignToCompany sets the company's price list; a nil listID removes it.
+// It applies to the company's user unless the user has a price list of their own.
+func (s *PriceListService) AssignToCompany(ctx context.Context, companyID int, listID *int) error {
+	if err := s.checkList(ctx, listID); err != nil {
+		return err
+	}
+	if err := s.repo.AssignToCompany(ctx, companyID, listID); err != nil {
+		return err
+	}
+	s.log.Info("price list assigned to company", zap.Int("companyId", companyID), zap.Any("priceListId", listID))
+	return nil
+}
+
+func (s *PriceListService) checkList(ctx context.Context, listID *int) error {
+	if listID == nil {
+		return nil
+	}
+	_, err := s.repo.FindByID(ctx, *listID)
+	return err
+}
+
+// CustomerPrices resolves unit prices of a set of products for one customer.
+// A nil *CustomerPrices charges the retail price.
+type CustomerPrices struct {
+	tiers     map[int][]domain.PriceTier
+	list      *domain.PriceList
+	listTiers map[int][]domain.PriceTier
+}
+
+// Prepare loads the quantity price breaks of the products and the price list
+// of the customer (nil for guests). A nil service charges retail prices.
+func (s *PriceListService) Prepare(ctx context.Context, userID *int, productIDs []int) (*CustomerPrices, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	tiers, err := s.repo.TiersForProducts(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load price tiers: %w", err)
+	}
+	cp := &CustomerPrices{tiers: make(map[int][]domain.PriceTier)}
+	for _, t := range tiers {
+		cp.tiers[t.ProductID] = append(cp.tiers[t.ProductID], t)
+	}
+
+	if userID == nil {
+		return cp, nil
+	}
+	list, err := s.repo.FindForUser(ctx, *userID)
+	if errors.Is(err, domain.ErrPriceListNotFound) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load price list: %w", err)
+	}
+	items, err := s.repo.ItemsForProducts(ctx, list.ID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("load price list items: %w", err)
+	}
+	cp.list = list
+	cp.listTiers = make(map[int][]domain.PriceTier)
+	for _, item := range items {
+		cp.listTiers[item.ProductID] = append(cp.listTiers[item.ProductID], domain.PriceTier{
+			MinQuantity: item.MinQuantity,
+			Price:       item.Price,
+		})
+	}
+	return cp, nil
+}
+
+// UnitPrice returns the price of one item when qty items of p are ordered:
+// the lowest of the current price, its quantity price break and the
+// customer's price list.
+func (cp *CustomerPrices) UnitPrice(p *domain.Product, qty int) float64 {
+	price := p.Price
+	if cp == nil {
+		return price
+	}
+	if tier, ok := domain.TierPrice(cp.tiers[p.ID], qty); ok && tier < price {
+		price = tier
+	}
+	if cp.list != nil {
+		listPrice, ok := domain.TierPrice(cp.listTiers[p.ID], qty)
+		if !ok && cp.list.DiscountPercent > 0 {
+			listPrice, ok = math.Round(p.Price*(100-cp.list.DiscountPercent))/100, true
+		}
+		if ok && listPrice < price {
+			price = listPrice
+		}
+	}
+	return price
+}
+
+// Tiers returns the quantities from which the customer pays less for p, with
+// the unit price from each of them.
+func (cp *CustomerPrices) Tiers(p *domain.Product) []domain.PriceTier {
+	if cp == nil {
+		return nil
+	}
+	var quantities []int
+	for _, t := range cp.tiers[p.ID] {
+		quantities = append(quantities, t.MinQuantity)
+	}
+	for _, t := range cp.listTiers[p.ID] {
+		quantities = append(quantities, t.MinQuantity)
+	}
+	sort.Ints(quantities)
+
+	var tiers []domain.PriceTier
+	last := cp.UnitPrice(p, 1)
+	for _, qty := range quantities {
+		if qty <= 1 {
+			continue
+		}
+		if price := cp.UnitPrice(p, qty); price < last {
+			tiers = append(tiers, domain.PriceTier{MinQuantity: qty, Price: price})
+			last = price
+		}
+	}
+	return tiers
+}
+
+// ApplyToProducts shows catalog products at the customer's price for a single
+// item, with the quantity price breaks. Failures are logged and leave retail prices.
+func (s *PriceListService) ApplyToProducts(ctx context.Context, userID *int, products []domain.Product) {
+	if s == nil || len(products) == 0 {
+		return
+	}
+	ids := make([]int, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	prices, err := s.Prepare(ctx, userID, ids)
+	if err != nil {
+		s.log.Warn("failed to resolve customer prices", zap.Error(err))
+		return
+	}
+	for i := range products {
+		p := &products[i]
+		p.PriceTiers = prices.Tiers(p)
+		p.Price = prices.UnitPrice(p, 1)
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// PricingEngine computes order totals. CreateOrder charges exactly what a
+// quote for the same input shows, so the checkout preview and the order agree.
+type PricingEngine struct {
+	productRepo     domain.ProductRepository
+	promoService    *PromoService
+	deliveryService *DeliveryService
+	production      *ProductionService
+	prices          *PriceListService
+	log             *zap.Logger
+}
+
+// NewPricingEngine creates a pricing engine. Delivery, production and price lists are optional.
+func NewPricingEngine(productRepo domain.ProductRepository, promoService *PromoService, log *zap.Logger) *PricingEngine {
+	return &PricingEngine{productRepo: productRepo, promoService: promoService, log: log}
+}
+
+// PricingInput is everything the order total depends on, apart from bonuses.
+type PricingInput struct {
+	Items          []OrderItemInput
+	DeliveryMethod string
+	City           *string
+	PromoCode      *string
+	// UserID selects the customer's price list; nil charges retail prices.
+	UserID *int
+
+	// PromoApplied marks PromoCode as already used by the order being edited,
+	// see PromoService.Reapply.
+	PromoApplied bool
+	// Reserved is the stock already taken by the order being edited, per
+	// product. It counts as available for that order.
+	Reserved map[int]int
+}
+
+// QuoteLine is a priced order line. Err is set when the line cannot be ordered.
+type QuoteLine struct {
+	ProductID  int             `json:"productId"`
+	Name       string          `json:"name,omitempty"`
+	Quantity   int             `json:"quantity"`
+	UnitPrice  float64         `json:"unitPrice"`
+	TotalPrice float64         `json:"totalPrice"`
+	FromStock  int             `json:"fromStock"`
+	ToProduce  int             `json:"toProduce"`
+	Product    *domain.Product `json:"-"`
+	Err        error           `json:"-"`
+}
+
+// Quote is the price breakdown of an order. Lines with errors do not count
+// towards the totals.
+type Quote struct {
+	Lines             []QuoteLine `json:"lines"`
+	Subtotal          float64     `json:"subtotal"`
+	PromoCode         *string     `json:"promoCode,omitempty"`
+	DiscountAmount    float64     `json:"discountAmount"`
+	DeliveryMethod    string      `json:"deliveryMethod"`
+	DeliveryCost      float64     `json:"deliveryCost"`
+	DeliveryProvider  *string     `json:"deliveryProvider,omitempty"`
+	EstimatedDelivery *string     `json:"estimatedDelivery,omitempty"`
+	MaxBonus          float64     `json:"maxBonus"`
+	BonusDiscount     float64     `json:"bonusDiscount"`
+	TotalPrice        float64     `json:"totalPrice"`
+
+	// DeliveryErr and PromoErr are order-level problems.
+	DeliveryErr error `json:"-"`
+	PromoErr    error `json:"-"`
+
+	needsProduction   bool
+	productionMinutes int
+}
+
+// Err returns the first problem that prevents placing the order, in the order
+// CreateOrder has always checked them: lines, delivery method, promo code.
+func (q *Quote) Err() error {
+	for _, l := range q.Lines {
+		if l.Err != nil {
+			return l.Err
+		}
+	}
+	if q.DeliveryErr != nil {
+		return q.DeliveryErr
+	}
+	return q.PromoErr
+}
+
+// Quote prices the items, promo code and delivery. Only loading products can
+// fail; validation problems are reported on the quote.
+func (e *PricingEngine) Quote(ctx context.Context, input PricingInput) (*Quote, error) {
+	productIDs := make([]int, len(input.Items))
+	for i, item := range input.Items {
+		productIDs[i] = item.ProductID
+	}
+	products, err := e.productRepo.FindByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[int]*domain.Product)
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+	}
+	prices, err := e.prices.Prepare(ctx, input.UserID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Lines: products exist, are active, and have stock (or can be printed)
+	q := &Quote{Lines: make([]QuoteLine, 0, len(input.Items))}
+	digitalOnly := true
+	for _, item := range input.Items {
+		line := QuoteLine{ProductID: item.ProductID, Quantity: item.Quantity}
+		p, ok := productMap[item.ProductID]
+		switch {
+		case !ok:
+			line.Err = domain.ErrProductNotFound
+		case !p.IsActive:
+			line.Err = domain.ErrProductInactive
+		}
+		if line.Err != nil {
+			q.Lines = append(q.Lines, line)
+			continue
+		}
+
+		if r := input.Reserved[p.ID]; r > 0 {
+			withReserved := *p
+			withReserved.StockQuantity += r
+			p = &withReserved
+		}
+
+		line.Product = p
+		line.Name = p.Name
+		line.UnitPrice = prices.UnitPrice(p, item.Quantity)
+		if !p.IsDigital {
+			digitalOnly = false
+		}
+		fromStock, toProduce, ok := p.StockSplit(item.Quantity)
+		if !ok {
+			line.Err = domain.ErrInsufficientStock
+			q.Lines = append(q.Lines, line)
+			continue
+		}
+		line.FromStock, line.ToProduce = fromStock, toProduce
+		if toProduce > 0 {
+			q.needsProduction = true
+			q.productionMinutes += p.ProductionMinutes(toProduce)
+		}
+
+		line.TotalPrice = math.Round(line.UnitPrice*float64(item.Quantity)*100) / 100
+		q.Subtotal += line.TotalPrice
+		q.Lines = append(q.Lines, line)
+	}
+	q.Subtotal = math.Round(q.Subtotal*100) / 100
+
+	// Digital-only orders are delivered by download links.
+	q.DeliveryMethod = input.DeliveryMethod
+	if digitalOnly {
+		q.DeliveryMethod = domain.DeliveryMethodDigital
+	} else if q.DeliveryMethod == "" {
+		q.DeliveryErr = domain.ErrDeliveryMethodRequired
+	}
+
+	// 2. Promo code
+	if input.PromoCode != nil && *input.PromoCode != "" {
+		var result *domain.PromoValidationResult
+		if input.PromoApplied {
+			result, err = e.promoService.Reapply(ctx, *input.PromoCode, q.Subtotal)
+		} else {
+			result, err = e.promoService.Validate(ctx, ValidatePromoInput{
+				Code:       *input.PromoCode,
+				OrderTotal: q.Subtotal,
+			})
+		}
+		if err != nil {
+			q.PromoErr = err
+		} else {
+			q.DiscountAmount = result.DiscountAmount
+			q.PromoCode = input.PromoCode
+		}
+	}
+
+	// 3. Delivery cost
+	if q.DeliveryMethod == "courier" && input.City != nil && *input.City != "" && e.deliveryService != nil {
+		cost, estimated, err := e.deliveryService.CalculateCourierCost(ctx, *input.City, q.Subtotal-q.DiscountAmount, 0)
+		if err == nil {
+			q.DeliveryCost = cost
+			prov := e.deliveryService.provider.Name()
+			q.DeliveryProvider = &prov
+			q.EstimatedDelivery = &estimated
+		}
+	}
+
+	// Made-to-order items ship after printing: prepend the ship date to the delivery estimate.
+	if q.needsProduction && e.production != nil {
+		shipDate, err := e.production.EstimateShipDate(ctx, q.productionMinutes)
+		if err != nil {
+			e.log.Warn("failed to estimate ship date", zap.Error(err))
+		} else {
+			estimate := formatShipEstimate(shipDate, q.EstimatedDelivery)
+			q.EstimatedDelivery = &estimate
+		}
+	}
+
+	// 4. Total before bonuses
+	q.MaxBonus = math.Round((q.Subtotal-q.DiscountAmount)*100) / 100
+	q.TotalPrice = math.Round((q.Subtotal-q.DiscountAmount+q.DeliveryCost)*100) / 100
+	if q.TotalPrice < 0 {
+		q.TotalPrice = 0
+	}
+	return q, nil
+}
+
+// ApplyBonuses pays part of the order with bonuses. Bonuses cover goods only,
+// not delivery, and never more than the customer's balance.
+func (q *Quote) ApplyBonuses(requested, balance float64) {
+	q.MaxBonus = math.Round(max(min(q.MaxBonus, balance), 0)*100) / 100
+	if requested <= 0 {
+		return
+	}
+	q.BonusDiscount = math.Round(min(requested, q.MaxBonus)*100) / 100
+	q.TotalPrice = math.Round((q.TotalPrice-q.BonusDiscount)*100) / 100
+	if q.TotalPrice < 0 {
+		q.TotalPrice = 0
+	}
+}
+
+// CheckoutPreviewInput is the part of CreateOrderInput that affects the price.
+type CheckoutPreviewInput struct {
+	Items          []OrderItemInput `json:"items" binding:"required,min=1,dive"`
+	DeliveryMethod string           `json:"deliveryMethod" binding:"omitempty,oneof=pickup courier pickup_point"`
+	City           *string          `json:"city"`
+	PromoCode      *string          `json:"promoCode"`
+	BonusAmount    float64          `json:"bonusAmount"`
+	TelegramID     *int64           `json:"telegramId"`
+	AddressID      *int             `json:"addressId"`
+	UserID         *int             `json:"-"`
+}
+
+// CheckoutPreview is the order summary shown before placing the order.
+type CheckoutPreview struct {
+	*Quote
+	BonusBalance    float64                    `json:"bonusBalance"`
+	DeliveryOptions *DeliveryCalculationResult `json:"deliveryOptions,omitempty"`
+}
+
+// PreviewCheckout prices an order the way CreateOrder would without creating it.
+// Problems are reported on the quote instead of failing the request.
+func (s *OrderService) PreviewCheckout(ctx context.Context, input CheckoutPreviewInput) (*CheckoutPreview, error) {
+	if input.AddressID != nil {
+		// An unknown address is ignored here; CreateOrder reports it.
+		if address, err := s.findAddress(ctx, input.UserID, *input.AddressID); err == nil {
+			order := CreateOrderInput{DeliveryMethod: input.DeliveryMethod, City: input.City}
+			applyAddress(&order, address)
+			input.DeliveryMethod, input.City = order.DeliveryMethod, order.City
+		}
+	}
+
+	quote, err := s.pricing.Quote(ctx, PricingInput{
+		Items:          mergeOrderItems(input.Items),
+		DeliveryMethod: input.DeliveryMethod,
+		City:           input.City,
+		PromoCode:      input.PromoCode,
+		UserID:         input.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	preview := &CheckoutPreview{Quote: quote}
+
+	// Bonuses belong to the user CreateOrder will link the order to;
+	// a customer who is not registered yet has no balance.
+	var balance float64
+	if input.UserID != nil {
+		if user, err := s.userRepo.FindByID(ctx, *input.UserID); err == nil {
+			balance = user.BonusBalance
+		}
+	} else if input.TelegramID != nil && *input.TelegramID != 0 {
+		if user, err := s.userRepo.FindByTelegramID(ctx, *input.TelegramID); err == nil {
+			balance = user.BonusBalance
+		}
+	}
+	quote.ApplyBonuses(input.BonusAmount, balance)
+	preview.BonusBalance = balance
+
+	if input.City != nil && *input.City != "" && s.deliveryService != nil && quote.DeliveryMethod != domain.DeliveryMethodDigital {
+		options, err := s.deliveryService.Calculate(ctx, CalculateDeliveryInput{
+			City:       *input.City,
+			OrderTotal: quote.Subtotal - quote.DiscountAmount,
+		})
+		if err == nil {
+			preview.DeliveryOptions = options
+		}
+	}
+	return preview, nil
+}
@@ -19,10 +19,16 @@ const productCacheTTL = 5 * time.Minute
 
 // ProductService handles product business logic.
 type ProductService struct {
-	repo    domain.ProductRepository
-	catRepo domain.CategoryRepository
-	cache   *cache.Store
-	log     *zap.Logger
+	repo         domain.ProductRepository
+	catRepo      domain.CategoryRepository
+	priceHistory domain.PriceHistoryRepository
+	production   *ProductionService
+	stock        *StockService
+	slugHistory  *SlugRedirectService
+	revisions    domain.ProductRevisionRepository
+	translations *TranslationService
+	cache        *cache.Store
+	log          *zap.Logger
 }
 
 // NewProductService creates a new product service.
@@ -30,6 +36,31 @@ func NewProductService(repo domain.ProductRepository, catRepo domain.CategoryRep
 	return &ProductService{repo: repo, catRepo: catRepo, cache: cache, log: log}
 }
 
+// SetProductionService enables lead time estimates on the product page.
+func (s *ProductService) SetProductionService(ps *ProductionService) {
+	s.production = ps
+}
+
+// SetPriceHistoryRepo enables recording of manual price changes.
+func (s *ProductService) SetPriceHistoryRepo(repo domain.PriceHistoryRepository) {
+	s.priceHistory = repo
+}
+
+// SetStockService books the stock entered in the product form in the stock ledger.
+func (s *ProductService) SetStockService(ss *StockService) {
+	s.stock = ss
+}
+
+// SetSlugRedirectService enables slug history: old product links redirect to the new slug.
+func (s *ProductService) SetSlugRedirectService(sr *SlugRedirectService) {
+	s.slugHistory = sr
+}
+
+// SetRevisionRepo enables the product edit history.
+func (s *ProductService) SetRevisionRepo(repo domain.ProductRevisionRepository) {
+	s.revisions = repo
+}
+
 // CreateProductInput represents the input for creating a product.
 type CreateProductInput struct {
 	Name             string             `json:"name" binding:"required,min=1,max=255"`
@@ -44,8 +75,12 @@ type CreateProductInput struct {
 	Dimensions       *domain.Dimensions `json:"dimensions"`
 	Material         *string            `json:"material"`
 	PrintTime        *int               `json:"printTime"`
+	FulfillmentMode  *string            `json:"fulfillmentMode" binding:"omitempty,oneof=in_stock made_to_order hybrid"`
+	IsDigital        *bool              `json:"isDigital"`
 	CategoryID       *int               `json:"categoryId"`
 	IsFeatured       *bool              `json:"isFeatured"`
+	// CreatedBy is set by the handler from the JWT context, not from JSON.
+	CreatedBy *int `json:"-"`
 }
 
 // UpdateProductInput represents the input for updating a product.
@@ -62,9 +97,13 @@ type UpdateProductInput struct {
 	Dimensions       *domain.Dimensions `json:"dimensions"`
 	Material         *string            `json:"material"`
 	PrintTime        *int               `json:"printTime"`
+	FulfillmentMode  *string            `json:"fulfillmentMode" binding:"omitempty,oneof=in_stock made_to_order hybrid"`
+	IsDigital        *bool              `json:"isDigital"`
 	CategoryID       *int               `json:"categoryId"`
 	IsActive         *bool              `json:"isActive"`
 	IsFeatured       *bool              `json:"isFeatured"`
+	// ChangedBy is set by the handler from the JWT context, not from JSON.
+	ChangedBy *int `json:"-"`
 }
 
 // Create creates a new product, auto-generating slug from name.
@@ -105,16 +144,23 @@ func (s *ProductService) Create(ctx context.Context, input CreateProductInput) (
 		ShortDescription: input.ShortDescription,
 		Price:            input.Price,
 		OldPrice:         input.OldPrice,
-		StockQuantity:    stockQty,
 		SKU:              input.SKU,
 		Weight:           input.Weight,
 		Dimensions:       input.Dimensions,
 		Material:         input.Material,
 		PrintTime:        input.PrintTime,
 		CategoryID:       input.CategoryID,
+		FulfillmentMode:  domain.FulfillmentInStock,
 		IsActive:         true,
 	}
 
+	if input.FulfillmentMode != nil {
+		product.FulfillmentMode = *input.FulfillmentMode
+	}
+	if input.IsDigital != nil {
+		product.IsDigital = *input.IsDigital
+	}
+
 	if input.IsFeatured != nil {
 		product.IsFeatured = *input.IsFeatured
 	}
@@ -123,14 +169,49 @@ func (s *ProductService) Create(ctx context.Context, input CreateProductInput) (
 		return nil, fmt.Errorf("create product: %w", err)
 	}
 
+	// Initial stock is the product's first movement in the stock ledger.
+	if stockQty > 0 && s.stock != nil {
+		m, err := s.stock.Receive(ctx, product.ID, StockReceiptInput{
+			Type:      domain.StockMovementInitial,
+			Quantity:  stockQty,
+			CreatedBy: input.CreatedBy,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("record opening stock: %w", err)
+		}
+		product.StockQuantity = m.Balance
+	}
+
+	s.recordRevision(ctx, product, nil, revisionMeta{action: domain.RevisionActionCreate, userID: input.CreatedBy})
 	s.invalidateProductCache(ctx)
 	s.log.Info("product created", zap.Int("id", product.ID), zap.String("slug", product.Slug))
 	return product, nil
 }
 
-// GetBySlug returns a product by slug with category info.
-func (s *ProductService) GetBySlug(ctx context.Context, slug string) (*domain.Product, error) {
-	return s.repo.FindBySlug(ctx, slug)
+// SetTranslationService enables translated product text on the storefront.
+func (s *ProductService) SetTranslationService(ts *TranslationService) {
+	s.translations = ts
+}
+
+// GetBySlug returns a product by slug with category info and, for printed-on-demand
+// items, the current lead time. Text is translated to locale where available.
+func (s *ProductService) GetBySlug(ctx context.Context, slug, locale string) (*domain.Product, error) {
+	product, err := s.repo.FindBySlug(ctx, slug)
+	if errors.Is(err, domain.ErrProductNotFound) && s.slugHistory != nil {
+		if newSlug, rerr := s.slugHistory.Resolve(ctx, domain.SlugEntityProduct, slug); rerr == nil {
+			return nil, &domain.SlugMovedError{NewSlug: newSlug}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.production != nil {
+		s.production.FillLeadTime(ctx, product)
+	}
+	if s.translations != nil {
+		s.translations.TranslateProduct(ctx, product, locale)
+	}
+	return product, nil
 }
 
 // GetByID returns a product by ID.
@@ -144,6 +225,7 @@ func (s *ProductService) Update(ctx context.Context, id int, input UpdateProduct
 	if err != nil {
 		return nil, err
 	}
+	before := domain.SnapshotProduct(product)
 
 	if input.Name != nil {
 		product.Name = *input.Name
@@ -169,9 +251,6 @@ func (s *ProductService) Update(ctx context.Context, id int, input UpdateProduct
 	if input.OldPrice != nil {
 		product.OldPrice = input.OldPrice
 	}
-	if input.StockQuantity != nil {
-		product.StockQuantity = *input.StockQuantity
-	}
 	if input.SKU != nil {
 		product.SKU = input.SKU
 	}
@@ -187,6 +266,12 @@ func (s *ProductService) Update(ctx context.Context, id int, input UpdateProduct
 	if input.PrintTime != nil {
 		product.PrintTime = input.PrintTime
 	}
+	if input.FulfillmentMode != nil {
+		product.FulfillmentMode = *input.FulfillmentMode
+	}
+	if input.IsDigital != nil {
+		product.IsDigital = *input.IsDigital
+	}
 	if input.CategoryID != nil {
 		if *input.CategoryID == 0 {
 			product.CategoryID = nil
@@ -201,15 +286,82 @@ func (s *ProductService) Update(ctx context.Context, id int, input UpdateProduct
 		product.IsFeatured = *input.IsFeatured
 	}
 
+	// Stock entered in the form is the counted quantity: the difference is
+	// booked as an adjustment once the rest of the edit is saved, so a failed
+	// save leaves the stock untouched and a retry books it again.
+	adjustStock := input.StockQuantity != nil && s.stock != nil
+	if adjustStock {
+		product.StockQuantity = *input.StockQuantity
+	}
+
+	if err := s.save(ctx, product, before, revisionMeta{action: domain.RevisionActionUpdate, userID: input.ChangedBy}); err != nil {
+		return nil, err
+	}
+
+	if adjustStock {
+		m, err := s.stock.Adjust(ctx, id, StockAdjustmentInput{
+			Actual:    input.StockQuantity,
+			Comment:   "Остаток изменён в карточке товара",
+			CreatedBy: input.ChangedBy,
+		})
+		switch {
+		case err == nil:
+			product.StockQuantity = m.Balance
+		case !errors.Is(err, domain.ErrInvalidStockMovement):
+			return nil, err
+		}
+	}
+
+	s.log.Info("product updated", zap.Int("id", product.ID))
+	return product, nil
+}
+
+// save persists an edited product and runs the side effects of an edit: price
+// history, slug redirects and the revision log. Stock is not saved here, it
+// only changes through the stock ledger.
+func (s *ProductService) save(ctx context.Context, product *domain.Product, before domain.ProductSnapshot, meta revisionMeta) error {
 	product.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(ctx, product); err != nil {
-		return nil, fmt.Errorf("update product: %w", err)
+		return fmt.Errorf("update product: %w", err)
+	}
+
+	if product.Price != before.Price || !sameOptionalPrice(product.OldPrice, before.OldPrice) {
+		s.recordPriceChange(ctx, product, before.Price, before.OldPrice, meta.userID)
 	}
 
+	if s.slugHistory != nil && product.Slug != before.Slug {
+		s.slugHistory.Record(ctx, domain.SlugEntityProduct, product.ID, before.Slug, product.Slug)
+	}
+
+	s.recordRevision(ctx, product, &before, meta)
 	s.invalidateProductCache(ctx)
-	s.log.Info("product updated", zap.Int("id", product.ID))
-	return product, nil
+	return nil
+}
+
+func (s *ProductService) recordPriceChange(ctx context.Context, product *domain.Product, prevPrice float64, prevOldPrice *float64, changedBy *int) {
+	if s.priceHistory == nil {
+		return
+	}
+	entry := &domain.PriceHistory{
+		ProductID:   product.ID,
+		OldPrice:    &prevPrice,
+		NewPrice:    product.Price,
+		OldOldPrice: prevOldPrice,
+		NewOldPrice: product.OldPrice,
+		Reason:      domain.PriceChangeManual,
+		ChangedBy:   changedBy,
+	}
+	if err := s.priceHistory.Create(ctx, entry); err != nil {
+		s.log.Warn("failed to record price history", zap.Int("productId", product.ID), zap.Error(err))
+	}
+}
+
+func sameOptionalPrice(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
 
 // List returns a paginated, filtered list of products (cached for 5 min).
@@ -226,6 +378,9 @@ func (s *ProductService) List(ctx context.Context, filter domain.ProductFilter)
 	if err != nil {
 		return nil, err
 	}
+	if s.translations != nil {
+		s.translations.TranslateProducts(ctx, res.Products, filter.Locale)
+	}
 
 	if err := s.cache.Set(ctx, cacheKey, res, productCacheTTL); err != nil {
 		s.log.Warn("failed to cache product list", zap.Error(err))
@@ -235,9 +390,9 @@ func (s *ProductService) List(ctx context.Context, filter domain.ProductFilter)
 }
 
 func (s *ProductService) productListCacheKey(filter domain.ProductFilter) string {
-	raw := fmt.Sprintf("%s|%v|%v|%v|%s|%s|%d|%d|%v",
+	raw := fmt.Sprintf("%s|%v|%v|%v|%s|%s|%d|%d|%v|%s",
 		filter.CategorySlug, filter.MinPrice, filter.MaxPrice, filter.Materials,
-		filter.Search, filter.Sort, filter.Page, filter.Limit, filter.IncludeInactive)
+		filter.Search, filter.Sort, filter.Page, filter.Limit, filter.IncludeInactive, filter.Locale)
 	h := sha256.Sum256([]byte(raw))
 	return productCachePrefix + hex.EncodeToString(h[:8])
 }
@@ -246,6 +401,7 @@ func (s *ProductService) invalidateProductCache(ctx context.Context) {
 	if err := s.cache.DeleteByPrefix(ctx, productCachePrefix); err != nil {
 		s.log.Warn("failed to invalidate product cache", zap.Error(err))
 	}
+	invalidateSEOCache(ctx, s.cache, s.log)
 }
 
 // SearchSuggestions returns product name suggestions for autocomplete.
@@ -253,16 +409,21 @@ func (s *ProductService) SearchSuggestions(ctx context.Context, query string, li
 	return s.repo.SearchSuggestions(ctx, query, limit)
 }
 
-// Delete soft-deletes a product (sets is_active=false).
-func (s *ProductService) Delete(ctx context.Context, id int) error {
-	if _, err := s.repo.FindByID(ctx, id); err != nil {
+// Delete soft-deletes a product (sets is_active=false); it stays in the trash
+// until restored.
+func (s *ProductService) Delete(ctx context.Context, id int, deletedBy *int) error {
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
 		return err
 	}
+	before := domain.SnapshotProduct(product)
 
 	if err := s.repo.SoftDelete(ctx, id); err != nil {
 		return fmt.Errorf("soft delete product: %w", err)
 	}
 
+	product.IsActive = false
+	s.recordRevision(ctx, product, &before, revisionMeta{action: domain.RevisionActionDelete, userID: deletedBy})
 	s.invalidateProductCache(ctx)
 	s.log.Info("product soft-deleted", zap.Int("id", id))
 	return nil
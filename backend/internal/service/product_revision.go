@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+var errRevisionsDisabled = errors.New("product revisions are not enabled")
+
+// revisionMeta describes why a product changed.
+type revisionMeta struct {
+	action       string
+	userID       *int
+	restoredFrom *int
+}
+
+// recordRevision stores the product state after a change. before is nil for a
+// newly created product. Edits that change nothing are not recorded.
+func (s *ProductService) recordRevision(ctx context.Context, product *domain.Product, before *domain.ProductSnapshot, meta revisionMeta) {
+	if s.revisions == nil {
+		return
+	}
+
+	after := domain.SnapshotProduct(product)
+	var prev domain.ProductSnapshot
+	if before != nil {
+		prev = *before
+	}
+	changes := domain.DiffSnapshots(prev, after)
+	if meta.action == domain.RevisionActionUpdate && len(changes) == 0 {
+		return
+	}
+
+	rev := &domain.ProductRevision{
+		ProductID:    product.ID,
+		Action:       meta.action,
+		Changes:      changes,
+		Snapshot:     after,
+		RestoredFrom: meta.restoredFrom,
+		UserID:       meta.userID,
+	}
+	if err := s.revisions.Create(ctx, rev); err != nil {
+		s.log.Warn("failed to record product revision",
+			zap.Int("productId", product.ID), zap.String("action", meta.action), zap.Error(err))
+	}
+}
+
+// ListRevisions returns the edit history of a product, newest first.
+func (s *ProductService) ListRevisions(ctx context.Context, productID, page, limit int) ([]domain.ProductRevision, int64, error) {
+	if s.revisions == nil {
+		return nil, 0, errRevisionsDisabled
+	}
+	if _, err := s.repo.FindByID(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+	return s.revisions.ListByProductID(ctx, productID, page, limit)
+}
+
+// RevisionDiff is the difference between two states of a product.
+// To is 0 when the comparison is against the current product.
+type RevisionDiff struct {
+	ProductID int                 `json:"productId"`
+	From      int                 `json:"from"`
+	To        int                 `json:"to"`
+	Changes   domain.FieldChanges `json:"changes"`
+}
+
+// DiffRevisions compares two revisions of a product. With to == 0 the
+// revision is compared against the current product.
+func (s *ProductService) DiffRevisions(ctx context.Context, productID, from, to int) (*RevisionDiff, error) {
+	if s.revisions == nil {
+		return nil, errRevisionsDisabled
+	}
+
+	fromRev, err := s.revisions.FindByRevision(ctx, productID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var target domain.ProductSnapshot
+	if to == 0 {
+		product, err := s.repo.FindByID(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		target = domain.SnapshotProduct(product)
+	} else {
+		toRev, err := s.revisions.FindByRevision(ctx, productID, to)
+		if err != nil {
+			return nil, err
+		}
+		target = toRev.Snapshot
+	}
+
+	return &RevisionDiff{
+		ProductID: productID,
+		From:      from,
+		To:        to,
+		Changes:   domain.DiffSnapshots(fromRev.Snapshot, target),
+	}, nil
+}
+
+// RestoreRevision brings the product fields back to the state of a revision.
+// Stock is not restored. The restore itself becomes a new revision.
+func (s *ProductService) RestoreRevision(ctx context.Context, productID, revision int, restoredBy *int) (*domain.Product, error) {
+	if s.revisions == nil {
+		return nil, errRevisionsDisabled
+	}
+
+	rev, err := s.revisions.FindByRevision(ctx, productID, revision)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.repo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	before := domain.SnapshotProduct(product)
+
+	if rev.Snapshot.Slug != product.Slug {
+		if existing, err := s.repo.FindBySlug(ctx, rev.Snapshot.Slug); err == nil && existing.ID != productID {
+			return nil, domain.ErrProductSlugExists
+		}
+	}
+
+	rev.Snapshot.ApplyTo(product)
+	// A category deleted since then cannot be restored; keep the current one.
+	if product.CategoryID != nil {
+		if _, err := s.catRepo.FindByID(ctx, *product.CategoryID); errors.Is(err, domain.ErrCategoryNotFound) {
+			product.CategoryID = before.CategoryID
+		}
+	}
+
+	meta := revisionMeta{action: domain.RevisionActionRestore, userID: restoredBy, restoredFrom: &revision}
+	if err := s.save(ctx, product, before, meta); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("product revision restored", zap.Int("id", productID), zap.Int("revision", revision))
+	return product, nil
+}
+
+// ListTrash returns deactivated products, most recently deleted first.
+func (s *ProductService) ListTrash(ctx context.Context, search string, page, limit int) (*domain.ProductListResult, error) {
+	result, err := s.repo.List(ctx, domain.ProductFilter{
+		Search:       search,
+		Sort:         "updated",
+		Page:         page,
+		Limit:        limit,
+		OnlyInactive: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	return result, nil
+}
+
+// RestoreFromTrash reactivates a deleted product.
+func (s *ProductService) RestoreFromTrash(ctx context.Context, id int, restoredBy *int) (*domain.Product, error) {
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsActive {
+		return product, nil
+	}
+	before := domain.SnapshotProduct(product)
+
+	product.IsActive = true
+	if err := s.save(ctx, product, before, revisionMeta{action: domain.RevisionActionRestore, userID: restoredBy}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("product restored from trash", zap.Int("id", id))
+	return product, nil
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+const (
+	viewsPendingKey    = "views:pending"
+	viewsFlushingKey   = "views:flushing"
+	viewsFlushLockKey  = "views:flush-lock"
+	viewSeenPrefix     = "views:seen:"
+	recentViewedPrefix = "recent:"
+
+	// viewDedupWindow — повторный просмотр той же сессией в этом окне не считается.
+	viewDedupWindow    = 30 * time.Minute
+	viewsFlushInterval = 1 * time.Minute
+	recentViewedMax    = 20
+	recentViewedTTL    = 30 * 24 * time.Hour
+
+	// viewsFlushLockTTL — срок блокировки сброса, если экземпляр упал посреди него.
+	viewsFlushLockTTL = 5 * time.Minute
+)
+
+// releaseLockScript deletes a lock only if it is still held by the caller.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+var botUserAgent = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|facebookexternalhit|preview|headless|curl|wget|python-requests|go-http-client|httpclient|scrapy`)
+
+// ViewerInfo identifies who is viewing a product page.
+type ViewerInfo struct {
+	UserID    *int
+	SessionID string // X-Session-ID header for anonymous visitors
+	IP        string
+	UserAgent string
+}
+
+// key returns a stable identifier for de-duplication and the recently viewed list.
+func (v ViewerInfo) key() string {
+	if v.UserID != nil {
+		return "user:" + strconv.Itoa(*v.UserID)
+	}
+	if v.SessionID != "" {
+		return "session:" + v.SessionID
+	}
+	h := sha256.Sum256([]byte(v.IP + "|" + v.UserAgent))
+	return "anon:" + hex.EncodeToString(h[:8])
+}
+
+// ProductStatsService maintains product view and sales counters and the
+// per-visitor recently viewed list.
+type ProductStatsService struct {
+	redis       *redis.Client
+	db          *gorm.DB
+	productRepo domain.ProductRepository
+	log         *zap.Logger
+}
+
+// NewProductStatsService creates a new product stats service.
+func NewProductStatsService(redisClient *redis.Client, db *gorm.DB, productRepo domain.ProductRepository, log *zap.Logger) *ProductStatsService {
+	return &ProductStatsService{
+		redis:       redisClient,
+		db:          db,
+		productRepo: productRepo,
+		log:         log,
+	}
+}
+
+// RecordView buffers a product view in Redis. Bots are ignored, and repeated views
+// by the same visitor within viewDedupWindow count once. Errors are logged only:
+// a lost view must never break the product page.
+func (s *ProductStatsService) RecordView(ctx context.Context, productID int, viewer ViewerInfo) {
+	if viewer.UserAgent == "" || botUserAgent.MatchString(viewer.UserAgent) {
+		return
+	}
+
+	vk := viewer.key()
+	s.pushRecentlyViewed(ctx, vk, productID)
+
+	seenKey := fmt.Sprintf("%s%d:%s", viewSeenPrefix, productID, vk)
+	first, err := s.redis.SetNX(ctx, seenKey, 1, viewDedupWindow).Result()
+	if err != nil {
+		s.log.Warn("failed to check view dedup", zap.Error(err))
+		return
+	}
+	if !first {
+		return
+	}
+
+	if err := s.redis.HIncrBy(ctx, viewsPendingKey, strconv.Itoa(productID), 1).Err(); err != nil {
+		s.log.Warn("failed to buffer product view", zap.Error(err))
+	}
+}
+
+// FlushViews moves buffered view counts from Redis into products.views_count.
+// Only one instance flushes at a time: the others skip the tick while the lock
+// is held, so the flushing hash is never added to the counters twice.
+func (s *ProductStatsService) FlushViews(ctx context.Context) error {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("generate flush lock token: %w", err)
+	}
+	lockToken := hex.EncodeToString(b)
+	locked, err := s.redis.SetNX(ctx, viewsFlushLockKey, lockToken, viewsFlushLockTTL).Result()
+	if err != nil {
+		return fmt.Errorf("take flush lock: %w", err)
+	}
+	if !locked {
+		return nil
+	}
+	defer func() {
+		if err := releaseLockScript.Run(context.WithoutCancel(ctx), s.redis, []string{viewsFlushLockKey}, lockToken).Err(); err != nil {
+			s.log.Warn("failed to release view flush lock", zap.Error(err))
+		}
+	}()
+
+	// A leftover flushing hash means the previous flush failed mid-way: retry it first.
+	exists, err := s.redis.Exists(ctx, viewsFlushingKey).Result()
+	if err != nil {
+		return fmt.Errorf("check flushing key: %w", err)
+	}
+	if exists == 0 {
+		if err := s.redis.Rename(ctx, viewsPendingKey, viewsFlushingKey).Err(); err != nil {
+			if strings.Contains(err.Error(), "no such key") {
+				return nil
+			}
+			return fmt.Errorf("rename pending views: %w", err)
+		}
+	}
+
+	counts, err := s.redis.HGetAll(ctx, viewsFlushingKey).Result()
+	if err != nil {
+		return fmt.Errorf("read pending views: %w", err)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for idStr, nStr := range counts {
+			id, err1 := strconv.Atoi(idStr)
+			n, err2 := strconv.Atoi(nStr)
+			if err1 != nil || err2 != nil || n <= 0 {
+				continue
+			}
+			if err := tx.Model(&domain.Product{}).Where("id = ?", id).
+				UpdateColumn("views_count", gorm.Expr("views_count + ?", n)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("flush views to db: %w", err)
+	}
+
+	if err := s.redis.Del(ctx, viewsFlushingKey).Err(); err != nil {
+		return fmt.Errorf("clear flushed views: %w", err)
+	}
+
+	if len(counts) > 0 {
+		s.log.Debug("product views flushed", zap.Int("products", len(counts)))
+	}
+	return nil
+}
+
+// StartViewFlusher flushes buffered views every minute and once more on shutdown.
+func (s *ProductStatsService) StartViewFlusher(ctx context.Context) {
+	s.log.Info("starting product view flusher")
+
+	ticker := time.NewTicker(viewsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is already cancelled, give the final flush its own deadline.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.FlushViews(flushCtx); err != nil {
+				s.log.Error("final view flush failed", zap.Error(err))
+			}
+			cancel()
+			s.log.Info("stopping product view flusher")
+			return
+		case <-ticker.C:
+			if err := s.FlushViews(ctx); err != nil {
+				s.log.Error("periodic view flush failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RecentlyViewed returns active products the visitor viewed, most recent first.
+func (s *ProductStatsService) RecentlyViewed(ctx context.Context, viewer ViewerInfo, limit int) ([]domain.Product, error) {
+	if limit <= 0 || limit > recentViewedMax {
+		limit = recentViewedMax
+	}
+
+	vals, err := s.redis.LRange(ctx, recentViewedPrefix+viewer.key(), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read recently viewed: %w", err)
+	}
+	if len(vals) == 0 {
+		return []domain.Product{}, nil
+	}
+
+	ids := make([]int, 0, len(vals))
+	for _, v := range vals {
+		if id, err := strconv.Atoi(v); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	products, err := s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	result := make([]domain.Product, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok && p.IsActive {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (s *ProductStatsService) pushRecentlyViewed(ctx context.Context, viewerKey string, productID int) {
+	key := recentViewedPrefix + viewerKey
+	id := strconv.Itoa(productID)
+
+	pipe := s.redis.TxPipeline()
+	pipe.LRem(ctx, key, 0, id)
+	pipe.LPush(ctx, key, id)
+	pipe.LTrim(ctx, key, 0, recentViewedMax-1)
+	pipe.Expire(ctx, key, recentViewedTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.log.Warn("failed to update recently viewed", zap.Error(err))
+	}
+}
+
+// CountSale adds the order's quantities to products.sales_count. Called when an order
+// is paid or delivered; the sales_counted flag makes the second call a no-op.
+func (s *ProductStatsService) CountSale(ctx context.Context, orderID int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Order{}).
+			Where("id = ? AND sales_counted = false AND status <> ?", orderID, "cancelled").
+			UpdateColumn("sales_counted", true)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Exec(`
+			UPDATE products p SET sales_count = p.sales_count + oi.qty
+			FROM (
+				SELECT product_id, SUM(quantity) AS qty FROM order_items
+				WHERE order_id = ? AND product_id IS NOT NULL
+				GROUP BY product_id
+			) oi
+			WHERE p.id = oi.product_id`, orderID).Error
+	})
+}
+
+// UncountSale reverts CountSale for a cancelled order.
+func (s *ProductStatsService) UncountSale(ctx context.Context, orderID int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Order{}).
+			Where("id = ? AND sales_counted = true", orderID).
+			UpdateColumn("sales_counted", false)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Exec(`
+			UPDATE products p SET sales_count = GREATEST(p.sales_count - oi.qty, 0)
+			FROM (
+				SELECT product_id, SUM(quantity) AS qty FROM order_items
+				WHERE order_id = ? AND product_id IS NOT NULL
+				GROUP BY product_id
+			) oi
+			WHERE p.id = oi.product_id`, orderID).Error
+	})
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// openProductionStatuses are order statuses whose printed items are still in the queue.
+var openProductionStatuses = []string{"new", "confirmed", "processing"}
+
+// ProductionService estimates lead times for made-to-order items from the print queue.
+type ProductionService struct {
+	db  *gorm.DB
+	cfg config.ProductionConfig
+	log *zap.Logger
+}
+
+// NewProductionService creates a new production service.
+func NewProductionService(db *gorm.DB, cfg config.ProductionConfig, log *zap.Logger) *ProductionService {
+	return &ProductionService{db: db, cfg: cfg, log: log}
+}
+
+// QueueMinutes returns the total print time of items waiting for production in open orders.
+func (s *ProductionService) QueueMinutes(ctx context.Context) (int, error) {
+	var minutes int
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT COALESCE(SUM(oi.production_quantity * COALESCE(p.print_time, 0)), 0)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN products p ON p.id = oi.product_id
+		WHERE oi.production_quantity > 0 AND o.status IN ?`,
+		openProductionStatuses,
+	).Scan(&minutes).Error
+	if err != nil {
+		return 0, fmt.Errorf("sum production queue: %w", err)
+	}
+	return minutes, nil
+}
+
+// LeadTimeDays returns the number of days until extraMinutes of printing
+// (queued behind the current load) is finished and packed.
+func (s *ProductionService) LeadTimeDays(ctx context.Context, extraMinutes int) (int, error) {
+	queue, err := s.QueueMinutes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.leadTimeDays(queue + extraMinutes), nil
+}
+
+// EstimateShipDate returns the date an order needing extraMinutes of printing can ship.
+func (s *ProductionService) EstimateShipDate(ctx context.Context, extraMinutes int) (time.Time, error) {
+	days, err := s.LeadTimeDays(ctx, extraMinutes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().AddDate(0, 0, days), nil
+}
+
+// FillLeadTime sets LeadTimeDays on a product that may need printing.
+func (s *ProductionService) FillLeadTime(ctx context.Context, p *domain.Product) {
+	if p.FulfillmentMode != domain.FulfillmentMadeToOrder &&
+		!(p.FulfillmentMode == domain.FulfillmentHybrid && p.StockQuantity <= 0) {
+		return
+	}
+	days, err := s.LeadTimeDays(ctx, p.ProductionMinutes(1))
+	if err != nil {
+		s.log.Warn("failed to estimate lead time", zap.Int("productId", p.ID), zap.Error(err))
+		return
+	}
+	p.LeadTimeDays = &days
+}
+
+func (s *ProductionService) leadTimeDays(totalMinutes int) int {
+	capacity := s.cfg.CapacityHoursPerDay * 60
+	if capacity <= 0 {
+		capacity = 24 * 60
+	}
+	days := s.cfg.BaseDays
+	if totalMinutes > 0 {
+		days += (totalMinutes + capacity - 1) / capacity
+	}
+	return days
+}
+
+// formatShipEstimate combines the ship date with the delivery estimate, e.g.
+// "Отправка до 25.10.2026, доставка 1-3 дн.".
+func formatShipEstimate(shipDate time.Time, delivery *string) string {
+	text := "Отправка до " + shipDate.Format("02.01.2006")
+	if delivery != nil && *delivery != "" {
+		text += ", доставка " + *delivery
+	}
+	return text
+}
@@ -77,20 +77,35 @@ func (s *PromoService) Validate(ctx context.Context, input ValidatePromoInput) (
 		return nil, domain.ErrPromoUsedUp
 	}
 
-	if promo.MinOrderAmount > 0 && input.OrderTotal < promo.MinOrderAmount {
+	return promoDiscount(promo, input.OrderTotal)
+}
+
+// Reapply recomputes the discount of a promo code already used by an order
+// that is being edited. Only the minimum order amount is checked again: the
+// order keeps its code even if it has since expired or run out of uses.
+func (s *PromoService) Reapply(ctx context.Context, code string, orderTotal float64) (*domain.PromoValidationResult, error) {
+	promo, err := s.promoRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return promoDiscount(promo, orderTotal)
+}
+
+func promoDiscount(promo *domain.PromoCode, orderTotal float64) (*domain.PromoValidationResult, error) {
+	if promo.MinOrderAmount > 0 && orderTotal < promo.MinOrderAmount {
 		return nil, domain.ErrPromoMinAmount
 	}
 
 	var discountAmount float64
 	switch promo.DiscountType {
 	case "percent":
-		discountAmount = input.OrderTotal * promo.DiscountValue / 100
+		discountAmount = orderTotal * promo.DiscountValue / 100
 	case "fixed":
 		discountAmount = promo.DiscountValue
 	}
 	// Don't exceed order total
-	if discountAmount > input.OrderTotal {
-		discountAmount = input.OrderTotal
+	if discountAmount > orderTotal {
+		discountAmount = orderTotal
 	}
 	discountAmount = math.Round(discountAmount*100) / 100
 
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// Reasons a past order item was not added back to the cart.
+const (
+	ReorderSkipUnavailable = "unavailable"
+	ReorderSkipInactive    = "inactive"
+	ReorderSkipOutOfStock  = "out_of_stock"
+)
+
+// ReorderSkippedItem is an order item that could not be added to the cart.
+type ReorderSkippedItem struct {
+	ProductID   *int   `json:"productId,omitempty"`
+	ProductName string `json:"productName"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+// ReorderPriceChange is an item whose price differs from the one paid in the order.
+type ReorderPriceChange struct {
+	ProductID   int     `json:"productId"`
+	ProductName string  `json:"productName"`
+	OldPrice    float64 `json:"oldPrice"`
+	NewPrice    float64 `json:"newPrice"`
+}
+
+// ReorderResult is the cart after a reorder together with what changed.
+type ReorderResult struct {
+	Cart         *domain.Cart         `json:"cart"`
+	Skipped      []ReorderSkippedItem `json:"skipped"`
+	PriceChanges []ReorderPriceChange `json:"priceChanges"`
+}
+
+// SetCartService sets the cart service used to reorder past orders.
+func (s *OrderService) SetCartService(cs *CartService) {
+	s.cartService = cs
+}
+
+// Reorder adds the items of one of the user's orders to their cart, on top of
+// what is already there. Products that are gone, disabled or out of stock are
+// skipped and reported, as are prices that changed since the order.
+func (s *OrderService) Reorder(ctx context.Context, userID int, orderNumber string) (*ReorderResult, error) {
+	order, err := s.orderRepo.FindByOrderNumber(ctx, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+	// Someone else's order is reported as missing so order numbers cannot be probed.
+	if order.UserID == nil || *order.UserID != userID {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	result := &ReorderResult{
+		Skipped:      []ReorderSkippedItem{},
+		PriceChanges: []ReorderPriceChange{},
+	}
+	var added []domain.OrderItem
+	for _, item := range order.Items {
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, ReorderSkippedItem{
+				ProductID:   item.ProductID,
+				ProductName: orderItemName(item),
+				Quantity:    item.Quantity,
+				Reason:      reason,
+			})
+		}
+
+		if item.ProductID == nil {
+			skip(ReorderSkipUnavailable)
+			continue
+		}
+		product, err := s.productRepo.FindByID(ctx, *item.ProductID)
+		if errors.Is(err, domain.ErrProductNotFound) {
+			skip(ReorderSkipUnavailable)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load product %d: %w", *item.ProductID, err)
+		}
+		if !product.IsActive {
+			skip(ReorderSkipInactive)
+			continue
+		}
+
+		_, err = s.cartService.AddItem(ctx, userID, AddToCartInput{ProductID: product.ID, Quantity: item.Quantity})
+		switch {
+		case errors.Is(err, domain.ErrInsufficientStock):
+			skip(ReorderSkipOutOfStock)
+			continue
+		case errors.Is(err, domain.ErrProductInactive):
+			skip(ReorderSkipInactive)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("add product %d to cart: %w", product.ID, err)
+		}
+
+		added = append(added, item)
+	}
+
+	result.Cart, err = s.cartService.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare with the price the cart charges now, which depends on the
+	// customer's price list and the quantity in the cart.
+	cartItems := make(map[int]domain.CartItem, len(result.Cart.Items))
+	for _, ci := range result.Cart.Items {
+		cartItems[ci.ProductID] = ci
+	}
+	for _, item := range added {
+		ci, ok := cartItems[*item.ProductID]
+		if !ok || ci.UnitPrice == item.UnitPrice {
+			continue
+		}
+		result.PriceChanges = append(result.PriceChanges, ReorderPriceChange{
+			ProductID:   ci.ProductID,
+			ProductName: ci.Product.Name,
+			OldPrice:    item.UnitPrice,
+			NewPrice:    ci.UnitPrice,
+		})
+	}
+
+	s.log.Info("order reordered",
+		zap.String("orderNumber", order.OrderNumber),
+		zap.Int("userId", userID),
+		zap.Int("skipped", len(result.Skipped)),
+	)
+	return result, nil
+}
+
+func orderItemName(item domain.OrderItem) string {
+	if item.Product != nil {
+		return item.Product.Name
+	}
+	if item.CustomItemName != nil {
+		return *item.CustomItemName
+	}
+	return ""
+}
@@ -0,0 +1,521 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+	"github.com/brown/3d-print-shop/internal/storage"
+)
+
+const (
+	maxReturnPhotos    = 5
+	maxReturnPhotoSize = 10 << 20 // 10 MB
+)
+
+var ErrPhotoTooLarge = errors.New("фото слишком большое (максимум 10 MB)")
+var ErrUnsupportedPhoto = errors.New("неподдерживаемый формат фото (JPG, PNG, WEBP)")
+
+// CreateReturnInput is a customer's return request for a delivered order.
+type CreateReturnInput struct {
+	OrderNumber string            `json:"orderNumber" binding:"required"`
+	Items       []ReturnItemInput `json:"items" binding:"required,min=1,dive"`
+	Reason      string            `json:"reason" binding:"required,oneof=defect damaged wrong_item not_as_described changed_mind other"`
+	Comment     *string           `json:"comment"`
+}
+
+type ReturnItemInput struct {
+	OrderItemID int `json:"orderItemId" binding:"required"`
+	Quantity    int `json:"quantity" binding:"required,gt=0"`
+}
+
+// ShipReturnInput is the tracking number of the parcel the customer sent back.
+type ShipReturnInput struct {
+	TrackingNumber string `json:"trackingNumber" binding:"required,max=100"`
+}
+
+// InspectReturnInput is the inspection result of every returned item.
+type InspectReturnInput struct {
+	Items []InspectReturnItemInput `json:"items" binding:"required,min=1,dive"`
+	Notes *string                  `json:"notes"`
+	// ReceivedBy is the admin inspecting the parcel, set by the handler.
+	ReceivedBy *int `json:"-"`
+}
+
+type InspectReturnItemInput struct {
+	ItemID    int    `json:"itemId" binding:"required"`
+	Condition string `json:"condition" binding:"required,oneof=resellable damaged defective"`
+}
+
+// RefundReturnInput chooses how the customer gets the money back. Amount
+// defaults to the value of the returned items.
+type RefundReturnInput struct {
+	Method string   `json:"method" binding:"required,oneof=payment bonuses"`
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// ReturnService handles return requests: the customer submits one, the admin
+// approves or rejects it, receives and inspects the parcel and refunds it.
+type ReturnService struct {
+	repo           domain.ReturnRepository
+	orderRepo      domain.OrderRepository
+	paymentService *PaymentService
+	loyaltyService *LoyaltyService
+	stock          *StockService
+	s3             *storage.S3Client
+	db             *gorm.DB
+	log            *zap.Logger
+}
+
+// NewReturnService creates a new return service.
+func NewReturnService(repo domain.ReturnRepository, orderRepo domain.OrderRepository, db *gorm.DB, log *zap.Logger) *ReturnService {
+	return &ReturnService{repo: repo, orderRepo: orderRepo, db: db, log: log}
+}
+
+// SetPaymentService enables refunds through the payment provider.
+func (s *ReturnService) SetPaymentService(ps *PaymentService) {
+	s.paymentService = ps
+}
+
+// SetLoyaltyService enables refunds as store credit in bonuses.
+func (s *ReturnService) SetLoyaltyService(ls *LoyaltyService) {
+	s.loyaltyService = ls
+}
+
+// SetStockService enables back-in-stock alerts for restocked items.
+func (s *ReturnService) SetStockService(ss *StockService) {
+	s.stock = ss
+}
+
+// SetS3Client enables photo uploads.
+func (s *ReturnService) SetS3Client(s3 *storage.S3Client) {
+	s.s3 = s3
+}
+
+// Create submits a return request for items of one of the user's delivered orders.
+func (s *ReturnService) Create(ctx context.Context, userID int, input CreateReturnInput) (*domain.ReturnRequest, error) {
+	order, err := s.orderRepo.FindByOrderNumber(ctx, input.OrderNumber)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID == nil || *order.UserID != userID {
+		return nil, domain.ErrOrderNotFound
+	}
+	if order.Status != "delivered" {
+		return nil, domain.ErrReturnNotAllowed
+	}
+
+	returned, err := s.repo.ReturnedQuantities(ctx, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load returned quantities: %w", err)
+	}
+	orderItems := make(map[int]domain.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		orderItems[item.ID] = item
+	}
+
+	requested := make(map[int]int)
+	items := make([]domain.ReturnItem, 0, len(input.Items))
+	for _, in := range input.Items {
+		item, ok := orderItems[in.OrderItemID]
+		if !ok || (item.Product != nil && item.Product.IsDigital) {
+			return nil, domain.ErrReturnInvalidItems
+		}
+		requested[item.ID] += in.Quantity
+		if returned[item.ID]+requested[item.ID] > item.Quantity {
+			return nil, domain.ErrReturnInvalidItems
+		}
+		items = append(items, domain.ReturnItem{OrderItemID: item.ID, Quantity: in.Quantity})
+	}
+
+	req := &domain.ReturnRequest{
+		OrderID:   order.ID,
+		UserID:    userID,
+		Status:    domain.ReturnStatusRequested,
+		Reason:    input.Reason,
+		Comment:   input.Comment,
+		PhotoURLs: json.RawMessage("[]"),
+		Items:     items,
+	}
+	if err := s.repo.Create(ctx, req); err != nil {
+		return nil, fmt.Errorf("create return request: %w", err)
+	}
+
+	s.log.Info("return requested",
+		zap.Int("id", req.ID),
+		zap.String("orderNumber", order.OrderNumber),
+		zap.String("reason", input.Reason),
+	)
+	return s.repo.FindByID(ctx, req.ID)
+}
+
+// GetMine returns one of the user's return requests.
+func (s *ReturnService) GetMine(ctx context.Context, userID, id int) (*domain.ReturnRequest, error) {
+	req, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != userID {
+		return nil, domain.ErrReturnNotFound
+	}
+	return req, nil
+}
+
+// ListMine returns the user's return requests, newest first.
+func (s *ReturnService) ListMine(ctx context.Context, userID int) ([]domain.ReturnRequest, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// UploadPhoto attaches a photo to a request that has not been reviewed yet.
+func (s *ReturnService) UploadPhoto(ctx context.Context, userID, id int, file io.Reader, fileSize int64) (string, error) {
+	if s.s3 == nil {
+		return "", fmt.Errorf("file storage not configured")
+	}
+	if fileSize > maxReturnPhotoSize {
+		return "", ErrPhotoTooLarge
+	}
+
+	req, err := s.GetMine(ctx, userID, id)
+	if err != nil {
+		return "", err
+	}
+	if req.Status != domain.ReturnStatusRequested {
+		return "", domain.ErrReturnInvalidStatus
+	}
+
+	var urls []string
+	if err := json.Unmarshal(req.PhotoURLs, &urls); err != nil {
+		urls = []string{}
+	}
+	if len(urls) >= maxReturnPhotos {
+		return "", ErrTooManyFiles
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxReturnPhotoSize+1))
+	if err != nil {
+		return "", fmt.Errorf("read photo: %w", err)
+	}
+	if len(data) > maxReturnPhotoSize {
+		return "", ErrPhotoTooLarge
+	}
+	// The type is taken from the content, not from the client.
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedContentTypes[contentType]
+	if !ok {
+		return "", ErrUnsupportedPhoto
+	}
+
+	key := fmt.Sprintf("returns/%d/%s%s", req.ID, uuid.New().String(), ext)
+	publicURL, err := s.s3.UploadFromReader(ctx, key, bytes.NewReader(data), contentType)
+	if err != nil {
+		return "", fmt.Errorf("upload to s3: %w", err)
+	}
+
+	urls = append(urls, publicURL)
+	photoURLs, _ := json.Marshal(urls)
+	req.PhotoURLs = json.RawMessage(photoURLs)
+	if err := s.repo.Update(ctx, req); err != nil {
+		_ = s.s3.Delete(ctx, key)
+		return "", fmt.Errorf("save photo url: %w", err)
+	}
+	return publicURL, nil
+}
+
+// Ship records the tracking number of the parcel sent back by the customer.
+func (s *ReturnService) Ship(ctx context.Context, userID, id int, input ShipReturnInput) (*domain.ReturnRequest, error) {
+	req, err := s.GetMine(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != domain.ReturnStatusApproved && req.Status != domain.ReturnStatusShipped {
+		return nil, domain.ErrReturnInvalidStatus
+	}
+
+	now := time.Now()
+	req.Status = domain.ReturnStatusShipped
+	req.ReturnTrackingNumber = &input.TrackingNumber
+	req.ShippedAt = &now
+	if err := s.repo.Update(ctx, req); err != nil {
+		return nil, fmt.Errorf("update return request: %w", err)
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+// GetByID returns a return request for the admin panel.
+func (s *ReturnService) GetByID(ctx context.Context, id int) (*domain.ReturnRequest, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// List returns return requests for the admin panel.
+func (s *ReturnService) List(ctx context.Context, filter domain.ReturnFilter) ([]domain.ReturnRequest, int64, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Review approves or rejects a new request. The comment is shown to the
+// customer: return instructions or the reason for rejection.
+func (s *ReturnService) Review(ctx context.Context, id int, approve bool, comment *string) (*domain.ReturnRequest, error) {
+	req, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != domain.ReturnStatusRequested {
+		return nil, domain.ErrReturnInvalidStatus
+	}
+
+	req.Status = domain.ReturnStatusRejected
+	if approve {
+		req.Status = domain.ReturnStatusApproved
+	}
+	req.AdminComment = comment
+	if err := s.repo.Update(ctx, req); err != nil {
+		return nil, fmt.Errorf("update return request: %w", err)
+	}
+
+	s.log.Info("return reviewed", zap.Int("id", id), zap.String("status", req.Status))
+	return s.repo.FindByID(ctx, id)
+}
+
+// Receive records the inspection of the returned parcel. Resellable items
+// of catalog products go back to stock.
+func (s *ReturnService) Receive(ctx context.Context, id int, input InspectReturnInput) (*domain.ReturnRequest, error) {
+	req, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != domain.ReturnStatusApproved && req.Status != domain.ReturnStatusShipped {
+		return nil, domain.ErrReturnInvalidStatus
+	}
+
+	conditions := make(map[int]string, len(input.Items))
+	for _, in := range input.Items {
+		conditions[in.ItemID] = in.Condition
+	}
+	for _, item := range req.Items {
+		if _, ok := conditions[item.ID]; !ok {
+			return nil, domain.ErrReturnInvalidItems
+		}
+	}
+	if len(conditions) != len(req.Items) {
+		return nil, domain.ErrReturnInvalidItems
+	}
+
+	var restocked []*domain.StockMovement
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.ReturnRequest{}).
+			Where("id = ? AND status IN ?", id, []string{domain.ReturnStatusApproved, domain.ReturnStatusShipped}).
+			Updates(map[string]interface{}{
+				"status":           domain.ReturnStatusReceived,
+				"inspection_notes": input.Notes,
+				"received_at":      now,
+				"updated_at":       now,
+			})
+		if res.Error != nil {
+			return fmt.Errorf("update return request: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrReturnInvalidStatus
+		}
+
+		for _, item := range req.Items {
+			condition := conditions[item.ID]
+			toStock := condition == domain.ReturnConditionResellable &&
+				item.OrderItem != nil && item.OrderItem.ProductID != nil
+			if err := tx.Model(&domain.ReturnItem{}).
+				Where("id = ?", item.ID).
+				Updates(map[string]interface{}{"condition": condition, "restocked": toStock}).Error; err != nil {
+				return fmt.Errorf("update return item: %w", err)
+			}
+			if !toStock {
+				continue
+			}
+
+			m := &domain.StockMovement{
+				ProductID: *item.OrderItem.ProductID,
+				Type:      domain.StockMovementReturn,
+				Quantity:  item.Quantity,
+				OrderID:   &req.OrderID,
+				ReturnID:  &req.ID,
+				CreatedBy: input.ReceivedBy,
+			}
+			if err := moveStock(tx, m); err != nil {
+				return fmt.Errorf("restock product: %w", err)
+			}
+			restocked = append(restocked, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("return received", zap.Int("id", id), zap.Int("restocked", len(restocked)))
+
+	s.stock.Changed(restocked...)
+	return s.repo.FindByID(ctx, id)
+}
+
+// RefundLimit is the most that can be refunded for a return: the price paid
+// for the returned items after the order's promo discount.
+func refundLimit(req *domain.ReturnRequest, order *domain.Order) float64 {
+	var value float64
+	for _, item := range req.Items {
+		if item.OrderItem != nil {
+			value += item.OrderItem.UnitPrice * float64(item.Quantity)
+		}
+	}
+	if order.Subtotal > 0 {
+		value *= (order.Subtotal - order.DiscountAmount) / order.Subtotal
+	}
+	return math.Round(value*100) / 100
+}
+
+// Refund pays the customer back for a received return, to the card through
+// the payment provider or as bonuses. Cash payments are handed back by staff
+// and only recorded here.
+func (s *ReturnService) Refund(ctx context.Context, id int, input RefundReturnInput) (*domain.ReturnRequest, error) {
+	req, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != domain.ReturnStatusReceived || req.Order == nil {
+		return nil, domain.ErrReturnInvalidStatus
+	}
+	order := req.Order
+
+	limit := refundLimit(req, order)
+	amount := limit
+	if input.Amount != nil {
+		amount = math.Round(*input.Amount*100) / 100
+	}
+	if amount <= 0 || amount > limit {
+		return nil, domain.ErrReturnRefundAmount
+	}
+
+	switch input.Method {
+	case domain.ReturnRefundPayment:
+		if amount > order.PaidAmount {
+			return nil, domain.ErrReturnRefundAmount
+		}
+		if order.PaymentMethod == "card" && s.paymentService == nil {
+			return nil, fmt.Errorf("payment service not configured")
+		}
+		if order.PaymentMethod == "card" {
+			if err := s.refundCard(ctx, req, amount); err != nil {
+				return nil, err
+			}
+			break
+		}
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := s.markRefunded(ctx, tx, req, domain.ReturnStatusReceived, domain.ReturnStatusRefunded, input.Method, amount); err != nil {
+				return err
+			}
+			return tx.Model(&domain.Order{}).
+				Where("id = ?", order.ID).
+				UpdateColumn("paid_amount", gorm.Expr("GREATEST(paid_amount - ?, 0)", amount)).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+	case domain.ReturnRefundBonuses:
+		if s.loyaltyService == nil {
+			return nil, fmt.Errorf("loyalty service not configured")
+		}
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := s.markRefunded(ctx, tx, req, domain.ReturnStatusReceived, domain.ReturnStatusRefunded, input.Method, amount); err != nil {
+				return err
+			}
+			return s.loyaltyService.CreditReturn(ctx, tx, req.UserID, amount, order.ID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.log.Info("return refunded",
+		zap.Int("id", id),
+		zap.String("method", input.Method),
+		zap.Float64("amount", amount),
+	)
+	return s.repo.FindByID(ctx, id)
+}
+
+// refundCard returns the money of a return to the card. The return is moved
+// to refunding and committed before the provider is called, so a repeated
+// click cannot refund twice. A declined refund puts the return back to
+// received; a refund the provider accepted but that could not be recorded
+// leaves the return refunding for staff to settle by hand.
+func (s *ReturnService) refundCard(ctx context.Context, req *domain.ReturnRequest, amount float64) error {
+	method := domain.ReturnRefundPayment
+	if err := s.markRefunded(ctx, s.db, req, domain.ReturnStatusReceived, domain.ReturnStatusRefunding, method, amount); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.paymentService.Refund(ctx, tx, req.Order, amount); err != nil {
+			return err
+		}
+		return s.markRefunded(ctx, tx, req, domain.ReturnStatusRefunding, domain.ReturnStatusRefunded, method, amount)
+	})
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, domain.ErrRefundDeclined) {
+		now := time.Now()
+		if revertErr := s.db.WithContext(ctx).Model(&domain.ReturnRequest{}).
+			Where("id = ? AND status = ?", req.ID, domain.ReturnStatusRefunding).
+			Updates(map[string]interface{}{
+				"status":        domain.ReturnStatusReceived,
+				"refund_method": nil,
+				"refund_amount": 0,
+				"refunded_at":   nil,
+				"updated_at":    now,
+			}).Error; revertErr != nil {
+			s.log.Error("failed to revert declined return refund",
+				zap.Int("id", req.ID),
+				zap.Error(revertErr),
+			)
+		}
+		return err
+	}
+
+	s.log.Error("return refunded by the provider but not recorded, settle manually",
+		zap.Int("id", req.ID),
+		zap.String("orderNumber", req.Order.OrderNumber),
+		zap.Float64("amount", amount),
+		zap.Error(err),
+	)
+	return err
+}
+
+func (s *ReturnService) markRefunded(ctx context.Context, db *gorm.DB, req *domain.ReturnRequest, from, to, method string, amount float64) error {
+	now := time.Now()
+	res := db.WithContext(ctx).Model(&domain.ReturnRequest{}).
+		Where("id = ? AND status = ?", req.ID, from).
+		Updates(map[string]interface{}{
+			"status":        to,
+			"refund_method": method,
+			"refund_amount": amount,
+			"refunded_at":   now,
+			"updated_at":    now,
+		})
+	if res.Error != nil {
+		return fmt.Errorf("update return request: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrReturnInvalidStatus
+	}
+	return nil
+}
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+const saleCampaignCheckInterval = 1 * time.Minute
+
+// CreateSaleCampaignInput represents the input for creating a sale campaign.
+type CreateSaleCampaignInput struct {
+	Name          string  `json:"name" binding:"required,min=1,max=255"`
+	Description   *string `json:"description"`
+	DiscountType  string  `json:"discountType" binding:"required,oneof=percent fixed"`
+	DiscountValue float64 `json:"discountValue" binding:"required,gt=0"`
+	ProductIDs    []int   `json:"productIds"`
+	CategoryIDs   []int   `json:"categoryIds"`
+	StartsAt      string  `json:"startsAt" binding:"required"`
+	EndsAt        *string `json:"endsAt"`
+}
+
+// UpdateSaleCampaignInput represents the input for updating a scheduled sale campaign.
+type UpdateSaleCampaignInput struct {
+	Name          *string  `json:"name" binding:"omitempty,min=1,max=255"`
+	Description   *string  `json:"description"`
+	DiscountType  *string  `json:"discountType" binding:"omitempty,oneof=percent fixed"`
+	DiscountValue *float64 `json:"discountValue" binding:"omitempty,gt=0"`
+	ProductIDs    *[]int   `json:"productIds"`
+	CategoryIDs   *[]int   `json:"categoryIds"`
+	StartsAt      *string  `json:"startsAt"`
+	EndsAt        *string  `json:"endsAt"`
+}
+
+// SaleCampaignService schedules sale campaigns and keeps product price history.
+type SaleCampaignService struct {
+	repo        domain.SaleCampaignRepository
+	historyRepo domain.PriceHistoryRepository
+	db          *gorm.DB
+	cache       *cache.Store
+	log         *zap.Logger
+}
+
+// NewSaleCampaignService creates a new sale campaign service.
+func NewSaleCampaignService(
+	repo domain.SaleCampaignRepository,
+	historyRepo domain.PriceHistoryRepository,
+	db *gorm.DB,
+	cache *cache.Store,
+	log *zap.Logger,
+) *SaleCampaignService {
+	return &SaleCampaignService{
+		repo:        repo,
+		historyRepo: historyRepo,
+		db:          db,
+		cache:       cache,
+		log:         log,
+	}
+}
+
+// List returns campaigns, optionally filtered by status.
+func (s *SaleCampaignService) List(ctx context.Context, status string) ([]domain.SaleCampaign, error) {
+	return s.repo.List(ctx, status)
+}
+
+// GetByID returns a campaign with the products it has been applied to.
+func (s *SaleCampaignService) GetByID(ctx context.Context, id int) (*domain.SaleCampaign, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// Create schedules a new campaign. It is applied by the scheduler once StartsAt is reached.
+func (s *SaleCampaignService) Create(ctx context.Context, input CreateSaleCampaignInput) (*domain.SaleCampaign, error) {
+	campaign := &domain.SaleCampaign{
+		Name:          input.Name,
+		Description:   input.Description,
+		DiscountType:  input.DiscountType,
+		DiscountValue: input.DiscountValue,
+		ProductIDs:    domain.IntList(input.ProductIDs),
+		CategoryIDs:   domain.IntList(input.CategoryIDs),
+		Status:        domain.SaleCampaignScheduled,
+	}
+
+	startsAt, err := time.Parse(time.RFC3339, input.StartsAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid startsAt format", domain.ErrSaleCampaignInvalidPeriod)
+	}
+	campaign.StartsAt = startsAt
+
+	if input.EndsAt != nil {
+		t, err := time.Parse(time.RFC3339, *input.EndsAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid endsAt format", domain.ErrSaleCampaignInvalidPeriod)
+		}
+		campaign.EndsAt = &t
+	}
+
+	if err := validateSaleCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("create sale campaign: %w", err)
+	}
+
+	s.log.Info("sale campaign created",
+		zap.Int("id", campaign.ID),
+		zap.Time("startsAt", campaign.StartsAt),
+	)
+	return campaign, nil
+}
+
+// Update changes a campaign that has not started yet.
+func (s *SaleCampaignService) Update(ctx context.Context, id int, input UpdateSaleCampaignInput) (*domain.SaleCampaign, error) {
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != domain.SaleCampaignScheduled {
+		return nil, domain.ErrSaleCampaignNotEditable
+	}
+
+	if input.Name != nil {
+		campaign.Name = *input.Name
+	}
+	if input.Description != nil {
+		campaign.Description = input.Description
+	}
+	if input.DiscountType != nil {
+		campaign.DiscountType = *input.DiscountType
+	}
+	if input.DiscountValue != nil {
+		campaign.DiscountValue = *input.DiscountValue
+	}
+	if input.ProductIDs != nil {
+		campaign.ProductIDs = domain.IntList(*input.ProductIDs)
+	}
+	if input.CategoryIDs != nil {
+		campaign.CategoryIDs = domain.IntList(*input.CategoryIDs)
+	}
+	if input.StartsAt != nil {
+		t, err := time.Parse(time.RFC3339, *input.StartsAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid startsAt format", domain.ErrSaleCampaignInvalidPeriod)
+		}
+		campaign.StartsAt = t
+	}
+	if input.EndsAt != nil {
+		if *input.EndsAt == "" {
+			campaign.EndsAt = nil
+		} else {
+			t, err := time.Parse(time.RFC3339, *input.EndsAt)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid endsAt format", domain.ErrSaleCampaignInvalidPeriod)
+			}
+			campaign.EndsAt = &t
+		}
+	}
+
+	if err := validateSaleCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	campaign.Items = nil
+	if err := s.repo.Update(ctx, campaign); err != nil {
+		return nil, fmt.Errorf("update sale campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// Delete removes a campaign. Active campaigns must be stopped first so prices get restored.
+func (s *SaleCampaignService) Delete(ctx context.Context, id int) error {
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if campaign.Status == domain.SaleCampaignActive {
+		return domain.ErrSaleCampaignActive
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// StartNow applies a scheduled campaign immediately, ignoring StartsAt.
+func (s *SaleCampaignService) StartNow(ctx context.Context, id int) (*domain.SaleCampaign, error) {
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != domain.SaleCampaignScheduled {
+		return nil, domain.ErrSaleCampaignNotEditable
+	}
+	if err := s.activate(ctx, campaign); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(ctx, id)
+}
+
+// Stop ends an active campaign (restoring prices) or cancels a scheduled one.
+func (s *SaleCampaignService) Stop(ctx context.Context, id int) (*domain.SaleCampaign, error) {
+	campaign, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch campaign.Status {
+	case domain.SaleCampaignActive:
+		if err := s.finish(ctx, campaign, domain.SaleCampaignCancelled); err != nil {
+			return nil, err
+		}
+	case domain.SaleCampaignScheduled:
+		now := time.Now()
+		campaign.Status = domain.SaleCampaignCancelled
+		campaign.FinishedAt = &now
+		campaign.Items = nil
+		if err := s.repo.Update(ctx, campaign); err != nil {
+			return nil, fmt.Errorf("cancel sale campaign: %w", err)
+		}
+	default:
+		return nil, domain.ErrSaleCampaignNotEditable
+	}
+
+	return s.repo.FindByID(ctx, id)
+}
+
+// PriceHistory returns the latest price changes of a product.
+func (s *SaleCampaignService) PriceHistory(ctx context.Context, productID int, limit int) ([]domain.PriceHistory, error) {
+	return s.historyRepo.ListByProductID(ctx, productID, limit)
+}
+
+// ProcessDue starts campaigns whose StartsAt has passed and finishes those whose EndsAt has passed.
+func (s *SaleCampaignService) ProcessDue(ctx context.Context) error {
+	now := time.Now()
+
+	toEnd, err := s.repo.FindDueToEnd(ctx, now)
+	if err != nil {
+		return fmt.Errorf("find campaigns to end: %w", err)
+	}
+	for i := range toEnd {
+		if err := s.finish(ctx, &toEnd[i], domain.SaleCampaignFinished); err != nil {
+			s.log.Error("failed to finish sale campaign", zap.Int("id", toEnd[i].ID), zap.Error(err))
+		}
+	}
+
+	toStart, err := s.repo.FindDueToStart(ctx, now)
+	if err != nil {
+		return fmt.Errorf("find campaigns to start: %w", err)
+	}
+	for i := range toStart {
+		c := &toStart[i]
+		// Кампания целиком в прошлом (например, сервер был выключен) — цены не трогаем.
+		if c.EndsAt != nil && !c.EndsAt.After(now) {
+			c.Status = domain.SaleCampaignFinished
+			c.FinishedAt = &now
+			if err := s.repo.Update(ctx, c); err != nil {
+				s.log.Error("failed to close expired sale campaign", zap.Int("id", c.ID), zap.Error(err))
+			}
+			continue
+		}
+		if err := s.activate(ctx, c); err != nil {
+			s.log.Error("failed to start sale campaign", zap.Int("id", c.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// StartScheduler checks for due campaigns immediately and then every minute.
+func (s *SaleCampaignService) StartScheduler(ctx context.Context) {
+	s.log.Info("starting sale campaign scheduler")
+
+	if err := s.ProcessDue(ctx); err != nil {
+		s.log.Error("initial sale campaign check failed", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(saleCampaignCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("stopping sale campaign scheduler")
+			return
+		case <-ticker.C:
+			if err := s.ProcessDue(ctx); err != nil {
+				s.log.Error("periodic sale campaign check failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+type campaignTargetProduct struct {
+	ID       int
+	Price    float64
+	OldPrice *float64
+}
+
+// activate applies the discount to all target products that are not already on sale
+// and remembers their original prices.
+func (s *SaleCampaignService) activate(ctx context.Context, campaign *domain.SaleCampaign) error {
+	applied := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Guard against a concurrent scheduler run on another instance.
+		res := tx.Model(&domain.SaleCampaign{}).
+			Where("id = ? AND status = ?", campaign.ID, domain.SaleCampaignScheduled).
+			Updates(map[string]interface{}{
+				"status":       domain.SaleCampaignActive,
+				"activated_at": now,
+				"updated_at":   now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		var products []campaignTargetProduct
+		err := tx.Raw(`
+			SELECT p.id, p.price, p.old_price
+			FROM products p
+			WHERE p.is_active = true
+			  AND (p.id IN ? OR p.category_id IN (
+				WITH RECURSIVE tree AS (
+					SELECT id FROM categories WHERE id IN ?
+					UNION
+					SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id
+				)
+				SELECT id FROM tree
+			  ))
+			  AND NOT EXISTS (
+				SELECT 1 FROM sale_campaign_items i
+				JOIN sale_campaigns sc ON sc.id = i.campaign_id
+				WHERE i.product_id = p.id AND i.restored_at IS NULL AND sc.status = ?
+			  )
+			FOR UPDATE OF p`,
+			idsOrZero(campaign.ProductIDs), idsOrZero(campaign.CategoryIDs), domain.SaleCampaignActive,
+		).Scan(&products).Error
+		if err != nil {
+			return fmt.Errorf("resolve campaign products: %w", err)
+		}
+
+		for _, p := range products {
+			salePrice, ok := campaign.SalePrice(p.Price)
+			if !ok {
+				s.log.Warn("sale campaign discount not applicable",
+					zap.Int("campaignId", campaign.ID),
+					zap.Int("productId", p.ID),
+					zap.Float64("price", p.Price),
+				)
+				continue
+			}
+			salePrice = math.Round(salePrice*100) / 100
+			oldPrice := p.Price
+
+			if err := tx.Create(&domain.SaleCampaignItem{
+				CampaignID:       campaign.ID,
+				ProductID:        p.ID,
+				OriginalPrice:    p.Price,
+				OriginalOldPrice: p.OldPrice,
+				SalePrice:        salePrice,
+			}).Error; err != nil {
+				return fmt.Errorf("save campaign item: %w", err)
+			}
+
+			if err := tx.Model(&domain.Product{}).Where("id = ?", p.ID).
+				Updates(map[string]interface{}{
+					"price":      salePrice,
+					"old_price":  oldPrice,
+					"updated_at": now,
+				}).Error; err != nil {
+				return fmt.Errorf("apply sale price: %w", err)
+			}
+
+			if err := tx.Create(&domain.PriceHistory{
+				ProductID:   p.ID,
+				OldPrice:    &p.Price,
+				NewPrice:    salePrice,
+				OldOldPrice: p.OldPrice,
+				NewOldPrice: &oldPrice,
+				Reason:      domain.PriceChangeCampaignStart,
+				CampaignID:  &campaign.ID,
+			}).Error; err != nil {
+				return fmt.Errorf("record price history: %w", err)
+			}
+			applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx)
+	s.log.Info("sale campaign started", zap.Int("id", campaign.ID), zap.Int("products", applied))
+	return nil
+}
+
+// finish restores the original prices of all products touched by the campaign.
+// A product whose price was changed by hand during the campaign is left as is.
+func (s *SaleCampaignService) finish(ctx context.Context, campaign *domain.SaleCampaign, status string) error {
+	restored := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		res := tx.Model(&domain.SaleCampaign{}).
+			Where("id = ? AND status = ?", campaign.ID, domain.SaleCampaignActive).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"finished_at": now,
+				"updated_at":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		var items []domain.SaleCampaignItem
+		if err := tx.Where("campaign_id = ? AND restored_at IS NULL", campaign.ID).
+			Find(&items).Error; err != nil {
+			return fmt.Errorf("load campaign items: %w", err)
+		}
+
+		for _, item := range items {
+			var product campaignTargetProduct
+			err := tx.Raw("SELECT id, price, old_price FROM products WHERE id = ? FOR UPDATE", item.ProductID).
+				Scan(&product).Error
+			if err != nil {
+				return fmt.Errorf("lock product: %w", err)
+			}
+
+			if product.ID != 0 && math.Abs(product.Price-item.SalePrice) < 0.005 {
+				if err := tx.Model(&domain.Product{}).Where("id = ?", item.ProductID).
+					Updates(map[string]interface{}{
+						"price":      item.OriginalPrice,
+						"old_price":  item.OriginalOldPrice,
+						"updated_at": now,
+					}).Error; err != nil {
+					return fmt.Errorf("restore price: %w", err)
+				}
+
+				if err := tx.Create(&domain.PriceHistory{
+					ProductID:   item.ProductID,
+					OldPrice:    &product.Price,
+					NewPrice:    item.OriginalPrice,
+					OldOldPrice: product.OldPrice,
+					NewOldPrice: item.OriginalOldPrice,
+					Reason:      domain.PriceChangeCampaignEnd,
+					CampaignID:  &campaign.ID,
+				}).Error; err != nil {
+					return fmt.Errorf("record price history: %w", err)
+				}
+				restored++
+			} else {
+				s.log.Warn("product price changed during sale campaign, not restoring",
+					zap.Int("campaignId", campaign.ID),
+					zap.Int("productId", item.ProductID),
+				)
+			}
+
+			if err := tx.Model(&domain.SaleCampaignItem{}).Where("id = ?", item.ID).
+				Update("restored_at", now).Error; err != nil {
+				return fmt.Errorf("mark campaign item restored: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateProductCache(ctx)
+	s.log.Info("sale campaign ended",
+		zap.Int("id", campaign.ID),
+		zap.String("status", status),
+		zap.Int("restored", restored),
+	)
+	return nil
+}
+
+func (s *SaleCampaignService) invalidateProductCache(ctx context.Context) {
+	if err := s.cache.DeleteByPrefix(ctx, productCachePrefix); err != nil {
+		s.log.Warn("failed to invalidate product cache", zap.Error(err))
+	}
+	invalidateSEOCache(ctx, s.cache, s.log)
+}
+
+func validateSaleCampaign(c *domain.SaleCampaign) error {
+	if len(c.ProductIDs) == 0 && len(c.CategoryIDs) == 0 {
+		return domain.ErrSaleCampaignEmpty
+	}
+	if c.DiscountType == "percent" && c.DiscountValue >= 100 {
+		return domain.ErrSaleCampaignInvalidDiscount
+	}
+	if c.EndsAt != nil && !c.EndsAt.After(c.StartsAt) {
+		return domain.ErrSaleCampaignInvalidPeriod
+	}
+	return nil
+}
+
+// idsOrZero keeps "IN ?" valid for an empty list (product and category IDs start at 1).
+func idsOrZero(ids []int) []int {
+	if len(ids) == 0 {
+		return []int{0}
+	}
+	return ids
+}
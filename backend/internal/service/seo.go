@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+const (
+	seoCachePrefix       = "seo:"
+	sitemapIndexKey      = seoCachePrefix + "sitemap:index"
+	sitemapPagePrefix    = seoCachePrefix + "sitemap:page:"
+	structuredDataPrefix = seoCachePrefix + "product:"
+	seoCacheTTL          = 1 * time.Hour
+
+	// sitemapChunkSize is the number of URLs per sitemap file. The protocol allows
+	// 50 000, smaller files are cheaper to regenerate and easier for crawlers.
+	sitemapChunkSize = 10000
+
+	sitemapXMLNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	shopName     = "АВАНГАРД"
+)
+
+var ErrSitemapPageNotFound = errors.New("sitemap page not found")
+
+// staticPages are storefront pages that exist regardless of catalog data.
+var staticPages = []string{"/", "/catalog", "/custom-order"}
+
+// SEOService generates sitemap.xml, robots.txt and schema.org structured data
+// for the storefront.
+type SEOService struct {
+	db      *gorm.DB
+	repo    domain.ProductRepository
+	cache   *cache.Store
+	siteURL string
+	log     *zap.Logger
+}
+
+// NewSEOService creates a new SEO service. siteURL is the public storefront URL.
+func NewSEOService(db *gorm.DB, repo domain.ProductRepository, cache *cache.Store, siteURL string, log *zap.Logger) *SEOService {
+	return &SEOService{
+		db:      db,
+		repo:    repo,
+		cache:   cache,
+		siteURL: strings.TrimRight(siteURL, "/"),
+		log:     log,
+	}
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name       `xml:"urlset"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	XMLNS    string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// Sitemap returns /sitemap.xml: a single urlset, or a sitemap index pointing to
+// /sitemaps/N.xml when the catalog does not fit in one file.
+func (s *SEOService) Sitemap(ctx context.Context) ([]byte, error) {
+	var cached string
+	if found, err := s.cache.Get(ctx, sitemapIndexKey, &cached); err == nil && found {
+		return []byte(cached), nil
+	}
+
+	entries, err := s.sitemapEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if len(entries) <= sitemapChunkSize {
+		body, err = marshalSitemap(sitemapURLSet{XMLNS: sitemapXMLNS, URLs: entries})
+	} else {
+		index := sitemapIndex{XMLNS: sitemapXMLNS}
+		for page := 1; (page-1)*sitemapChunkSize < len(entries); page++ {
+			index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+				Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", s.siteURL, page),
+				LastMod: latestLastMod(sitemapChunk(entries, page)),
+			})
+		}
+		body, err = marshalSitemap(index)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, sitemapIndexKey, string(body), seoCacheTTL); err != nil {
+		s.log.Warn("failed to cache sitemap", zap.Error(err))
+	}
+	return body, nil
+}
+
+// SitemapPage returns /sitemaps/{page}.xml of a split sitemap (pages start at 1).
+func (s *SEOService) SitemapPage(ctx context.Context, page int) ([]byte, error) {
+	key := fmt.Sprintf("%s%d", sitemapPagePrefix, page)
+	var cached string
+	if found, err := s.cache.Get(ctx, key, &cached); err == nil && found {
+		return []byte(cached), nil
+	}
+
+	entries, err := s.sitemapEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chunk := sitemapChunk(entries, page)
+	if len(chunk) == 0 {
+		return nil, ErrSitemapPageNotFound
+	}
+
+	body, err := marshalSitemap(sitemapURLSet{XMLNS: sitemapXMLNS, URLs: chunk})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, key, string(body), seoCacheTTL); err != nil {
+		s.log.Warn("failed to cache sitemap page", zap.Int("page", page), zap.Error(err))
+	}
+	return body, nil
+}
+
+// Robots returns robots.txt for the storefront.
+func (s *SEOService) Robots() string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	b.WriteString("Allow: /\n")
+	for _, path := range []string{"/cart", "/checkout", "/order", "/orders", "/profile", "/login", "/register"} {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + s.siteURL + "/sitemap.xml\n")
+	return b.String()
+}
+
+// sitemapEntries lists static pages, active categories and active products, in a stable order.
+func (s *SEOService) sitemapEntries(ctx context.Context) ([]sitemapEntry, error) {
+	type row struct {
+		Slug      string
+		UpdatedAt time.Time
+	}
+
+	entries := make([]sitemapEntry, 0, len(staticPages))
+	for _, path := range staticPages {
+		entries = append(entries, sitemapEntry{Loc: s.siteURL + path})
+	}
+
+	var categories []row
+	if err := s.db.WithContext(ctx).Model(&domain.Category{}).
+		Select("slug, updated_at").
+		Where("is_active = true").
+		Order("id").
+		Scan(&categories).Error; err != nil {
+		return nil, fmt.Errorf("list categories for sitemap: %w", err)
+	}
+	for _, c := range categories {
+		entries = append(entries, sitemapEntry{
+			Loc:     s.siteURL + "/catalog?category=" + url.QueryEscape(c.Slug),
+			LastMod: c.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	var products []row
+	if err := s.db.WithContext(ctx).Model(&domain.Product{}).
+		Select("slug, updated_at").
+		Where("is_active = true").
+		Order("id").
+		Scan(&products).Error; err != nil {
+		return nil, fmt.Errorf("list products for sitemap: %w", err)
+	}
+	for _, p := range products {
+		entries = append(entries, sitemapEntry{
+			Loc:     s.siteURL + "/product/" + url.PathEscape(p.Slug),
+			LastMod: p.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return entries, nil
+}
+
+func sitemapChunk(entries []sitemapEntry, page int) []sitemapEntry {
+	start := (page - 1) * sitemapChunkSize
+	if page < 1 || start >= len(entries) {
+		return nil
+	}
+	return entries[start:min(start+sitemapChunkSize, len(entries))]
+}
+
+// latestLastMod returns the most recent lastmod of the entries (RFC3339 strings sort lexically in UTC).
+func latestLastMod(entries []sitemapEntry) string {
+	latest := ""
+	for _, e := range entries {
+		if e.LastMod > latest {
+			latest = e.LastMod
+		}
+	}
+	return latest
+}
+
+func marshalSitemap(v interface{}) ([]byte, error) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal sitemap: %w", err)
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ProductJSONLD is schema.org Product markup (JSON-LD) for a product page.
+type ProductJSONLD struct {
+	Context         string           `json:"@context"`
+	Type            string           `json:"@type"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description,omitempty"`
+	SKU             string           `json:"sku,omitempty"`
+	Image           []string         `json:"image,omitempty"`
+	Category        string           `json:"category,omitempty"`
+	URL             string           `json:"url"`
+	Brand           *schemaThing     `json:"brand,omitempty"`
+	Offers          schemaOffer      `json:"offers"`
+	AggregateRating *schemaAggRating `json:"aggregateRating,omitempty"`
+}
+
+type schemaThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type schemaOffer struct {
+	Type          string       `json:"@type"`
+	URL           string       `json:"url"`
+	Price         string       `json:"price"`
+	PriceCurrency string       `json:"priceCurrency"`
+	Availability  string       `json:"availability"`
+	ItemCondition string       `json:"itemCondition"`
+	Seller        *schemaThing `json:"seller,omitempty"`
+}
+
+type schemaAggRating struct {
+	Type        string `json:"@type"`
+	RatingValue string `json:"ratingValue"`
+	ReviewCount int    `json:"reviewCount"`
+	BestRating  string `json:"bestRating"`
+	WorstRating string `json:"worstRating"`
+}
+
+// ProductStructuredData returns schema.org Product JSON-LD for an active product.
+func (s *SEOService) ProductStructuredData(ctx context.Context, slug string) (*ProductJSONLD, error) {
+	key := structuredDataPrefix + slug
+	var cached ProductJSONLD
+	if found, err := s.cache.Get(ctx, key, &cached); err == nil && found {
+		return &cached, nil
+	}
+
+	product, err := s.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsActive {
+		return nil, domain.ErrProductNotFound
+	}
+
+	productURL := s.siteURL + "/product/" + url.PathEscape(product.Slug)
+	ld := &ProductJSONLD{
+		Context: "https://schema.org",
+		Type:    "Product",
+		Name:    product.Name,
+		URL:     productURL,
+		Brand:   &schemaThing{Type: "Brand", Name: shopName},
+		Offers: schemaOffer{
+			Type:          "Offer",
+			URL:           productURL,
+			Price:         fmt.Sprintf("%.2f", product.Price),
+			PriceCurrency: "RUB",
+			Availability:  schemaAvailability(product),
+			ItemCondition: "https://schema.org/NewCondition",
+			Seller:        &schemaThing{Type: "Organization", Name: shopName},
+		},
+	}
+	if product.ShortDescription != nil && *product.ShortDescription != "" {
+		ld.Description = *product.ShortDescription
+	} else if product.Description != nil {
+		ld.Description = *product.Description
+	}
+	if product.SKU != nil {
+		ld.SKU = *product.SKU
+	}
+	if product.Category != nil {
+		ld.Category = product.Category.Name
+	}
+	for _, img := range product.Images {
+		if img.URLLarge != nil {
+			ld.Image = append(ld.Image, *img.URLLarge)
+		} else {
+			ld.Image = append(ld.Image, img.URL)
+		}
+	}
+	if product.ReviewsCount > 0 {
+		ld.AggregateRating = &schemaAggRating{
+			Type:        "AggregateRating",
+			RatingValue: fmt.Sprintf("%.1f", product.Rating),
+			ReviewCount: product.ReviewsCount,
+			BestRating:  "5",
+			WorstRating: "1",
+		}
+	}
+
+	if err := s.cache.Set(ctx, key, ld, seoCacheTTL); err != nil {
+		s.log.Warn("failed to cache structured data", zap.String("slug", slug), zap.Error(err))
+	}
+	return ld, nil
+}
+
+func schemaAvailability(p *domain.Product) string {
+	switch {
+	case p.IsDigital, p.StockQuantity > 0:
+		return "https://schema.org/InStock"
+	case p.FulfillmentMode == domain.FulfillmentMadeToOrder || p.FulfillmentMode == domain.FulfillmentHybrid:
+		return "https://schema.org/MadeToOrder"
+	default:
+		return "https://schema.org/OutOfStock"
+	}
+}
+
+// invalidateSEOCache drops cached sitemaps and structured data after catalog changes.
+func invalidateSEOCache(ctx context.Context, store *cache.Store, log *zap.Logger) {
+	if err := store.DeleteByPrefix(ctx, seoCachePrefix); err != nil {
+		log.Warn("failed to invalidate seo cache", zap.Error(err))
+	}
+}
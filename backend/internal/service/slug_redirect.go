@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// SlugRedirectService keeps the slug history of products and categories and
+// resolves old slugs to current ones.
+type SlugRedirectService struct {
+	repo        domain.SlugRedirectRepository
+	productRepo domain.ProductRepository
+	catRepo     domain.CategoryRepository
+	log         *zap.Logger
+}
+
+// NewSlugRedirectService creates a new slug redirect service.
+func NewSlugRedirectService(repo domain.SlugRedirectRepository, productRepo domain.ProductRepository, catRepo domain.CategoryRepository, log *zap.Logger) *SlugRedirectService {
+	return &SlugRedirectService{repo: repo, productRepo: productRepo, catRepo: catRepo, log: log}
+}
+
+// Record stores oldSlug after a rename. The new slug stops being a redirect
+// if it was one, since a live entity now owns it.
+func (s *SlugRedirectService) Record(ctx context.Context, entityType string, entityID int, oldSlug, newSlug string) {
+	if oldSlug == "" || oldSlug == newSlug {
+		return
+	}
+	if err := s.repo.Save(ctx, entityType, entityID, oldSlug); err != nil {
+		s.log.Warn("failed to save slug redirect",
+			zap.String("type", entityType), zap.Int("id", entityID), zap.String("slug", oldSlug), zap.Error(err))
+		return
+	}
+	if err := s.repo.DeleteBySlug(ctx, entityType, newSlug); err != nil {
+		s.log.Warn("failed to drop shadowed slug redirect", zap.String("slug", newSlug), zap.Error(err))
+	}
+	s.log.Info("slug redirect saved",
+		zap.String("type", entityType), zap.Int("id", entityID), zap.String("from", oldSlug), zap.String("to", newSlug))
+}
+
+// Resolve returns the current slug for an old one, or ErrSlugRedirectNotFound.
+func (s *SlugRedirectService) Resolve(ctx context.Context, entityType, slug string) (string, error) {
+	redirect, err := s.repo.FindByOldSlug(ctx, entityType, slug)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := s.currentSlug(ctx, redirect.EntityType, redirect.EntityID)
+	if err != nil {
+		return "", err
+	}
+	if current == slug {
+		return "", domain.ErrSlugRedirectNotFound
+	}
+
+	if err := s.repo.RecordHit(ctx, redirect.ID); err != nil {
+		s.log.Warn("failed to record slug redirect hit", zap.Int("id", redirect.ID), zap.Error(err))
+	}
+	return current, nil
+}
+
+func (s *SlugRedirectService) currentSlug(ctx context.Context, entityType string, id int) (string, error) {
+	switch entityType {
+	case domain.SlugEntityProduct:
+		p, err := s.productRepo.FindByID(ctx, id)
+		if errors.Is(err, domain.ErrProductNotFound) {
+			return "", domain.ErrSlugRedirectNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		return p.Slug, nil
+	case domain.SlugEntityCategory:
+		c, err := s.catRepo.FindByID(ctx, id)
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			return "", domain.ErrSlugRedirectNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		return c.Slug, nil
+	}
+	return "", fmt.Errorf("unknown slug entity type %q", entityType)
+}
+
+// List returns redirects for the admin view with the current slug of each entity.
+func (s *SlugRedirectService) List(ctx context.Context, filter domain.SlugRedirectFilter) ([]domain.SlugRedirect, int64, error) {
+	redirects, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var productIDs []int
+	for _, r := range redirects {
+		if r.EntityType == domain.SlugEntityProduct {
+			productIDs = append(productIDs, r.EntityID)
+		}
+	}
+	productSlugs := make(map[int]string, len(productIDs))
+	if len(productIDs) > 0 {
+		products, err := s.productRepo.FindByIDs(ctx, productIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, p := range products {
+			productSlugs[p.ID] = p.Slug
+		}
+	}
+
+	for i := range redirects {
+		r := &redirects[i]
+		switch r.EntityType {
+		case domain.SlugEntityProduct:
+			r.CurrentSlug = productSlugs[r.EntityID]
+		case domain.SlugEntityCategory:
+			if c, err := s.catRepo.FindByID(ctx, r.EntityID); err == nil {
+				r.CurrentSlug = c.Slug
+			}
+		}
+	}
+	return redirects, total, nil
+}
+
+// Delete removes a single redirect.
+func (s *SlugRedirectService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Cleanup removes redirects nobody followed for the given number of days.
+func (s *SlugRedirectService) Cleanup(ctx context.Context, unusedDays int) (int64, error) {
+	since := time.Now().AddDate(0, 0, -unusedDays)
+	deleted, err := s.repo.DeleteUnusedSince(ctx, since)
+	if err != nil {
+		return 0, fmt.Errorf("cleanup slug redirects: %w", err)
+	}
+	s.log.Info("slug redirects cleaned up", zap.Int("unusedDays", unusedDays), zap.Int64("deleted", deleted))
+	return deleted, nil
+}
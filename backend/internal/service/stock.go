@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// StockReceiptInput represents units put on the shelf: a delivery from a
+// supplier ("receipt") or printed stock ("production").
+type StockReceiptInput struct {
+	Type      string  `json:"type" binding:"omitempty,oneof=receipt production"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
+	Reference *string `json:"reference" binding:"omitempty,max=100"`
+	Comment   *string `json:"comment"`
+	// CreatedBy is set by the handler from the JWT context, not from JSON.
+	CreatedBy *int `json:"-"`
+}
+
+// StockAdjustmentInput corrects stock after a count, damage or loss. Either
+// Quantity (the change, negative for write-offs) or Actual (the counted stock)
+// is given; Comment explains the correction.
+type StockAdjustmentInput struct {
+	Quantity *int   `json:"quantity"`
+	Actual   *int   `json:"actual" binding:"omitempty,min=0"`
+	Comment  string `json:"comment" binding:"required,max=500"`
+	// CreatedBy is set by the handler from the JWT context, not from JSON.
+	CreatedBy *int `json:"-"`
+}
+
+// StockService keeps the stock ledger: every change of Product.StockQuantity
+// is a movement saying why and by whom it was made.
+type StockService struct {
+	repo        domain.StockMovementRepository
+	productRepo domain.ProductRepository
+	db          *gorm.DB
+	cache       *cache.Store
+	stockAlerts *StockAlertService
+	log         *zap.Logger
+}
+
+// NewStockService creates a new stock service.
+func NewStockService(
+	repo domain.StockMovementRepository,
+	productRepo domain.ProductRepository,
+	db *gorm.DB,
+	cache *cache.Store,
+	log *zap.Logger,
+) *StockService {
+	return &StockService{
+		repo:        repo,
+		productRepo: productRepo,
+		db:          db,
+		cache:       cache,
+		log:         log,
+	}
+}
+
+// SetStockAlertService enables back-in-stock notifications when stock is replenished.
+func (s *StockService) SetStockAlertService(sa *StockAlertService) {
+	s.stockAlerts = sa
+}
+
+// moveStock changes the product's stock by m.Quantity and records the
+// movement within tx. Stock never goes below zero: such a movement fails with
+// ErrInsufficientStock, as does one for a missing product.
+func moveStock(tx *gorm.DB, m *domain.StockMovement) error {
+	var balance int
+	res := tx.Raw(`UPDATE products SET stock_quantity = stock_quantity + ?
+		WHERE id = ? AND stock_quantity + ? >= 0 RETURNING stock_quantity`,
+		m.Quantity, m.ProductID, m.Quantity).Scan(&balance)
+	if res.Error != nil {
+		return fmt.Errorf("update stock: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInsufficientStock
+	}
+
+	m.Balance = balance
+	if err := tx.Create(m).Error; err != nil {
+		return fmt.Errorf("record stock movement: %w", err)
+	}
+	return nil
+}
+
+// Receive puts units of a product on the shelf.
+func (s *StockService) Receive(ctx context.Context, productID int, input StockReceiptInput) (*domain.StockMovement, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	m := &domain.StockMovement{
+		ProductID: productID,
+		Type:      domain.StockMovementReceipt,
+		Quantity:  input.Quantity,
+		Reference: trimmedOrNil(input.Reference),
+		Comment:   trimmedOrNil(input.Comment),
+		CreatedBy: input.CreatedBy,
+	}
+	if input.Type != "" {
+		m.Type = input.Type
+	}
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return moveStock(tx, m)
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("stock received",
+		zap.Int("productId", productID),
+		zap.String("type", m.Type),
+		zap.Int("quantity", m.Quantity),
+		zap.Int("balance", m.Balance),
+	)
+	s.invalidateCache(ctx)
+	s.Changed(m)
+	return m, nil
+}
+
+// Adjust corrects the stock of a product by hand.
+func (s *StockService) Adjust(ctx context.Context, productID int, input StockAdjustmentInput) (*domain.StockMovement, error) {
+	if (input.Quantity == nil) == (input.Actual == nil) {
+		return nil, domain.ErrInvalidStockMovement
+	}
+	comment := strings.TrimSpace(input.Comment)
+
+	var m *domain.StockMovement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current int
+		res := tx.Raw(`SELECT stock_quantity FROM products WHERE id = ? FOR UPDATE`, productID).Scan(&current)
+		if res.Error != nil {
+			return fmt.Errorf("load stock: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrProductNotFound
+		}
+
+		delta := 0
+		if input.Quantity != nil {
+			delta = *input.Quantity
+		} else {
+			delta = *input.Actual - current
+		}
+		if delta == 0 {
+			return domain.ErrInvalidStockMovement
+		}
+
+		m = &domain.StockMovement{
+			ProductID: productID,
+			Type:      domain.StockMovementAdjustment,
+			Quantity:  delta,
+			Comment:   &comment,
+			CreatedBy: input.CreatedBy,
+		}
+		return moveStock(tx, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("stock adjusted",
+		zap.Int("productId", productID),
+		zap.Int("quantity", m.Quantity),
+		zap.Int("balance", m.Balance),
+	)
+	s.invalidateCache(ctx)
+	s.Changed(m)
+	return m, nil
+}
+
+// History returns the movements of a product, newest first.
+func (s *StockService) History(ctx context.Context, filter domain.StockMovementFilter) ([]domain.StockMovement, int64, error) {
+	if _, err := s.productRepo.FindByID(ctx, filter.ProductID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Changed is called with committed movements: subscribers of products that
+// came back in stock are notified in the background.
+func (s *StockService) Changed(movements ...*domain.StockMovement) {
+	if s == nil || s.stockAlerts == nil {
+		return
+	}
+
+	var restocked []*domain.StockMovement
+	for _, m := range movements {
+		if m.Quantity > 0 && m.Balance-m.Quantity <= 0 {
+			restocked = append(restocked, m)
+		}
+	}
+	if len(restocked) > 0 {
+		go func() {
+			bgCtx := context.Background()
+			for _, m := range restocked {
+				s.stockAlerts.HandleStockChange(bgCtx, m.ProductID, m.Balance-m.Quantity, m.Balance)
+			}
+		}()
+	}
+}
+
+func (s *StockService) invalidateCache(ctx context.Context) {
+	if err := s.cache.DeleteByPrefix(ctx, productCachePrefix); err != nil {
+		s.log.Warn("failed to invalidate product cache", zap.Error(err))
+	}
+	invalidateSEOCache(ctx, s.cache, s.log)
+}
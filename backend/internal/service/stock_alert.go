@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// stockAlertBatchSize limits how many subscriptions are loaded and notified at once.
+const stockAlertBatchSize = 50
+
+// StockAlertService manages "notify me when available" subscriptions and sends
+// back-in-stock notifications by email or Telegram.
+type StockAlertService struct {
+	repo         domain.StockSubscriptionRepository
+	productRepo  domain.ProductRepository
+	userRepo     domain.UserRepository
+	emailService *EmailService
+	notifier     domain.BackInStockNotifier
+	appURL       string
+	log          *zap.Logger
+}
+
+// NewStockAlertService creates a new stock alert service.
+func NewStockAlertService(
+	repo domain.StockSubscriptionRepository,
+	productRepo domain.ProductRepository,
+	userRepo domain.UserRepository,
+	appURL string,
+	log *zap.Logger,
+) *StockAlertService {
+	return &StockAlertService{
+		repo:        repo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		appURL:      strings.TrimRight(appURL, "/"),
+		log:         log,
+	}
+}
+
+// SetEmailService enables email notifications.
+func (s *StockAlertService) SetEmailService(es *EmailService) {
+	s.emailService = es
+}
+
+// SetNotifier enables Telegram notifications.
+func (s *StockAlertService) SetNotifier(n domain.BackInStockNotifier) {
+	s.notifier = n
+}
+
+// SubscribeInput represents a back-in-stock subscription request.
+type SubscribeInput struct {
+	ProductID int    `json:"productId" binding:"required,min=1"`
+	Channel   string `json:"channel" binding:"required,oneof=email telegram"`
+	Email     string `json:"email" binding:"omitempty,email"`
+}
+
+// Subscribe creates a back-in-stock subscription. Guests may subscribe by email;
+// Telegram requires an authenticated user with a linked Telegram account.
+// Subscribing again returns the existing subscription.
+func (s *StockAlertService) Subscribe(ctx context.Context, userID *int, input SubscribeInput) (*domain.StockSubscription, error) {
+	product, err := s.productRepo.FindByID(ctx, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsActive {
+		return nil, domain.ErrProductNotFound
+	}
+	if product.StockQuantity > 0 || product.IsDigital || product.FulfillmentMode != domain.FulfillmentInStock {
+		return nil, domain.ErrProductInStock
+	}
+
+	var user *domain.User
+	if userID != nil {
+		user, err = s.userRepo.FindByID(ctx, *userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &domain.StockSubscription{
+		ProductID: product.ID,
+		UserID:    userID,
+		Channel:   input.Channel,
+	}
+
+	switch input.Channel {
+	case domain.StockChannelEmail:
+		email := strings.TrimSpace(input.Email)
+		if email == "" && user != nil && user.Email != nil {
+			email = *user.Email
+		}
+		if email == "" {
+			return nil, domain.ErrSubscriptionContact
+		}
+		email = strings.ToLower(email)
+		sub.Email = &email
+	case domain.StockChannelTelegram:
+		if user == nil || user.TelegramID == nil {
+			return nil, domain.ErrSubscriptionContact
+		}
+		sub.TelegramID = user.TelegramID
+	}
+
+	created, err := s.repo.Create(ctx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("create stock subscription: %w", err)
+	}
+
+	if created {
+		s.log.Info("stock subscription created", zap.Int("productId", product.ID), zap.String("channel", sub.Channel))
+	}
+	return sub, nil
+}
+
+// ListMine returns the user's active subscriptions.
+func (s *StockAlertService) ListMine(ctx context.Context, userID int) ([]domain.StockSubscription, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// Unsubscribe deletes one of the user's subscriptions.
+func (s *StockAlertService) Unsubscribe(ctx context.Context, userID, id int) error {
+	return s.repo.DeleteForUser(ctx, id, userID)
+}
+
+// HandleStockChange notifies subscribers when a product's stock goes from zero to positive.
+func (s *StockAlertService) HandleStockChange(ctx context.Context, productID, prevQty, newQty int) {
+	if prevQty > 0 || newQty <= 0 {
+		return
+	}
+	if err := s.NotifyBackInStock(ctx, productID); err != nil {
+		s.log.Error("back-in-stock notification failed", zap.Int("productId", productID), zap.Error(err))
+	}
+}
+
+// NotifyBackInStock sends notifications to all subscribers of a product in batches
+// and deletes delivered subscriptions. Failed ones are kept for the next restock.
+func (s *StockAlertService) NotifyBackInStock(ctx context.Context, productID int) error {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if !product.IsActive {
+		return nil
+	}
+
+	productURL := fmt.Sprintf("%s/product/%s", s.appURL, product.Slug)
+	sent, failed, afterID := 0, 0, 0
+	for {
+		subs, err := s.repo.ListByProductID(ctx, productID, afterID, stockAlertBatchSize)
+		if err != nil {
+			return fmt.Errorf("list stock subscriptions: %w", err)
+		}
+		if len(subs) == 0 {
+			break
+		}
+
+		delivered := make([]int, 0, len(subs))
+		for _, sub := range subs {
+			afterID = sub.ID
+			if err := s.deliver(ctx, &sub, product, productURL); err != nil {
+				failed++
+				s.log.Warn("failed to deliver back-in-stock notification",
+					zap.Int("subscriptionId", sub.ID), zap.String("channel", sub.Channel), zap.Error(err))
+				continue
+			}
+			delivered = append(delivered, sub.ID)
+		}
+
+		if err := s.repo.DeleteByIDs(ctx, delivered); err != nil {
+			return fmt.Errorf("delete notified subscriptions: %w", err)
+		}
+		sent += len(delivered)
+
+		if len(subs) < stockAlertBatchSize {
+			break
+		}
+	}
+
+	if sent > 0 || failed > 0 {
+		s.log.Info("back-in-stock notifications sent",
+			zap.Int("productId", productID), zap.Int("sent", sent), zap.Int("failed", failed))
+	}
+	return nil
+}
+
+func (s *StockAlertService) deliver(ctx context.Context, sub *domain.StockSubscription, product *domain.Product, productURL string) error {
+	switch sub.Channel {
+	case domain.StockChannelEmail:
+		if s.emailService == nil {
+			return fmt.Errorf("email not configured")
+		}
+		if sub.Email == nil {
+			return domain.ErrSubscriptionContact
+		}
+		return s.emailService.SendBackInStock(*sub.Email, product, productURL)
+	case domain.StockChannelTelegram:
+		if s.notifier == nil {
+			return fmt.Errorf("telegram bot not configured")
+		}
+		if sub.TelegramID == nil {
+			return domain.ErrSubscriptionContact
+		}
+		return s.notifier.NotifyBackInStock(ctx, *sub.TelegramID, product)
+	}
+	return fmt.Errorf("unknown channel %q", sub.Channel)
+}
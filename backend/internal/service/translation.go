@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/cache"
+	"github.com/brown/3d-print-shop/internal/config"
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// Category translations are read on every catalog request, so the whole
+// locale is cached as one map next to the category tree.
+const categoryTranslationsCachePrefix = "i18n:categories:"
+
+// TranslationService serves catalog text in the visitor's language. Missing
+// translations, or single missing fields, fall back to the default locale.
+type TranslationService struct {
+	repo       domain.TranslationRepository
+	products   domain.ProductRepository
+	categories domain.CategoryRepository
+	content    domain.ContentBlockRepository
+	cfg        config.I18nConfig
+	cache      *cache.Store
+	log        *zap.Logger
+}
+
+// NewTranslationService creates a new translation service.
+func NewTranslationService(
+	repo domain.TranslationRepository,
+	products domain.ProductRepository,
+	categories domain.CategoryRepository,
+	content domain.ContentBlockRepository,
+	cfg config.I18nConfig,
+	cache *cache.Store,
+	log *zap.Logger,
+) *TranslationService {
+	return &TranslationService{
+		repo:       repo,
+		products:   products,
+		categories: categories,
+		content:    content,
+		cfg:        cfg,
+		cache:      cache,
+		log:        log,
+	}
+}
+
+// Locales returns the supported locales, the default one first.
+func (s *TranslationService) Locales() []string {
+	return s.cfg.Locales
+}
+
+// translated reports whether text in locale differs from the base catalog data.
+func (s *TranslationService) translated(locale string) bool {
+	return locale != "" && locale != s.cfg.DefaultLocale
+}
+
+// TranslateProducts replaces product and category text with the locale's translation.
+func (s *TranslationService) TranslateProducts(ctx context.Context, products []domain.Product, locale string) {
+	if !s.translated(locale) || len(products) == 0 {
+		return
+	}
+
+	ids := make([]int, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	rows, err := s.repo.FindForEntities(ctx, domain.TranslationEntityProduct, ids, locale)
+	if err != nil {
+		s.log.Warn("failed to load product translations", zap.String("locale", locale), zap.Error(err))
+	}
+	byID := make(map[int]domain.TranslationFields, len(rows))
+	for _, t := range rows {
+		byID[t.EntityID] = t.Fields
+	}
+	catFields := s.categoryTranslations(ctx, locale)
+
+	for i := range products {
+		p := &products[i]
+		if f, ok := byID[p.ID]; ok {
+			applyText(&p.Name, f["name"])
+			applyOptionalText(&p.ShortDescription, f["shortDescription"])
+			applyOptionalText(&p.Description, f["description"])
+		}
+		if p.Category != nil {
+			applyCategoryFields(p.Category, catFields[p.Category.ID])
+		}
+	}
+}
+
+// TranslateProduct replaces the text of a single product.
+func (s *TranslationService) TranslateProduct(ctx context.Context, product *domain.Product, locale string) {
+	items := []domain.Product{*product}
+	s.TranslateProducts(ctx, items, locale)
+	*product = items[0]
+}
+
+// TranslateCategories replaces category text, children included.
+func (s *TranslationService) TranslateCategories(ctx context.Context, categories []domain.Category, locale string) {
+	if !s.translated(locale) || len(categories) == 0 {
+		return
+	}
+	fields := s.categoryTranslations(ctx, locale)
+	var walk func(cats []domain.Category)
+	walk = func(cats []domain.Category) {
+		for i := range cats {
+			applyCategoryFields(&cats[i], fields[cats[i].ID])
+			walk(cats[i].Children)
+		}
+	}
+	walk(categories)
+}
+
+// TranslateCategory replaces the text of a single category.
+func (s *TranslationService) TranslateCategory(ctx context.Context, category *domain.Category, locale string) {
+	items := []domain.Category{*category}
+	s.TranslateCategories(ctx, items, locale)
+	*category = items[0]
+}
+
+// TranslateContent returns the locale's version of a content block, or data
+// when the block has no translation.
+func (s *TranslationService) TranslateContent(ctx context.Context, blockID int, data json.RawMessage, locale string) json.RawMessage {
+	if !s.translated(locale) {
+		return data
+	}
+	t, err := s.repo.Find(ctx, domain.TranslationEntityContentBlock, blockID, locale)
+	if err != nil {
+		if !errors.Is(err, domain.ErrTranslationNotFound) {
+			s.log.Warn("failed to load content translation", zap.Int("blockId", blockID), zap.Error(err))
+		}
+		return data
+	}
+	if len(t.Data) == 0 {
+		return data
+	}
+	return t.Data
+}
+
+func (s *TranslationService) categoryTranslations(ctx context.Context, locale string) map[int]domain.TranslationFields {
+	key := categoryTranslationsCachePrefix + locale
+
+	var fields map[int]domain.TranslationFields
+	if found, err := s.cache.Get(ctx, key, &fields); err == nil && found {
+		return fields
+	}
+
+	rows, err := s.repo.FindAllForLocale(ctx, domain.TranslationEntityCategory, locale)
+	if err != nil {
+		s.log.Warn("failed to load category translations", zap.String("locale", locale), zap.Error(err))
+		return nil
+	}
+	fields = make(map[int]domain.TranslationFields, len(rows))
+	for _, t := range rows {
+		fields[t.EntityID] = t.Fields
+	}
+	if err := s.cache.Set(ctx, key, fields, categoryCacheTTL); err != nil {
+		s.log.Warn("failed to cache category translations", zap.String("locale", locale), zap.Error(err))
+	}
+	return fields
+}
+
+func applyCategoryFields(c *domain.Category, f domain.TranslationFields) {
+	if f == nil {
+		return
+	}
+	applyText(&c.Name, f["name"])
+	applyOptionalText(&c.Description, f["description"])
+	applyOptionalText(&c.MetaTitle, f["metaTitle"])
+	applyOptionalText(&c.MetaDescription, f["metaDescription"])
+	applyOptionalText(&c.H1, f["h1"])
+}
+
+func applyText(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func applyOptionalText(dst **string, value string) {
+	if value != "" {
+		*dst = &value
+	}
+}
+
+// ContentBlockID resolves a content block slug for the translation endpoints.
+func (s *TranslationService) ContentBlockID(ctx context.Context, slug string) (int, error) {
+	block, err := s.content.FindBySlug(ctx, slug)
+	if err != nil {
+		return 0, fmt.Errorf("find content block %q: %w", slug, err)
+	}
+	return block.ID, nil
+}
+
+// List returns all translations of an entity.
+func (s *TranslationService) List(ctx context.Context, entityType string, entityID int) ([]domain.Translation, error) {
+	if err := s.checkEntity(ctx, entityType, entityID); err != nil {
+		return nil, err
+	}
+	translations, err := s.repo.FindByEntity(ctx, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("list translations: %w", err)
+	}
+	return translations, nil
+}
+
+// SaveTranslationInput is the text of an entity in one locale. Products and
+// categories use Fields, content blocks use Data.
+type SaveTranslationInput struct {
+	EntityType string
+	EntityID   int
+	Locale     string
+	Fields     domain.TranslationFields
+	Data       json.RawMessage
+}
+
+// Save creates or replaces a translation. The default locale is edited on the
+// entity itself and cannot be translated.
+func (s *TranslationService) Save(ctx context.Context, input SaveTranslationInput) (*domain.Translation, error) {
+	if !s.translated(input.Locale) || !slices.Contains(s.cfg.Locales, input.Locale) {
+		return nil, domain.ErrUnsupportedLocale
+	}
+	if err := s.checkEntity(ctx, input.EntityType, input.EntityID); err != nil {
+		return nil, err
+	}
+
+	t := &domain.Translation{
+		EntityType: input.EntityType,
+		EntityID:   input.EntityID,
+		Locale:     input.Locale,
+		Fields:     domain.TranslationFields{},
+		UpdatedAt:  time.Now(),
+	}
+	if input.EntityType == domain.TranslationEntityContentBlock {
+		if len(input.Data) == 0 || !json.Valid(input.Data) {
+			return nil, domain.ErrTranslationField
+		}
+		t.Data = input.Data
+	} else {
+		allowed := domain.TranslatableFields[input.EntityType]
+		for field, value := range input.Fields {
+			if !slices.Contains(allowed, field) {
+				return nil, fmt.Errorf("%w: %s", domain.ErrTranslationField, field)
+			}
+			if value != "" {
+				t.Fields[field] = value
+			}
+		}
+	}
+
+	if err := s.repo.Upsert(ctx, t); err != nil {
+		return nil, fmt.Errorf("save translation: %w", err)
+	}
+	s.invalidate(ctx, input.EntityType, input.Locale)
+
+	s.log.Info("translation saved",
+		zap.String("entityType", t.EntityType), zap.Int("entityId", t.EntityID), zap.String("locale", t.Locale))
+	return t, nil
+}
+
+// Delete removes a translation; the entity falls back to the default locale.
+func (s *TranslationService) Delete(ctx context.Context, entityType string, entityID int, locale string) error {
+	if err := s.repo.Delete(ctx, entityType, entityID, locale); err != nil {
+		return err
+	}
+	s.invalidate(ctx, entityType, locale)
+
+	s.log.Info("translation deleted",
+		zap.String("entityType", entityType), zap.Int("entityId", entityID), zap.String("locale", locale))
+	return nil
+}
+
+func (s *TranslationService) checkEntity(ctx context.Context, entityType string, entityID int) error {
+	var err error
+	switch entityType {
+	case domain.TranslationEntityProduct:
+		_, err = s.products.FindByID(ctx, entityID)
+	case domain.TranslationEntityCategory:
+		_, err = s.categories.FindByID(ctx, entityID)
+	case domain.TranslationEntityContentBlock:
+		// Content blocks are resolved by slug in ContentBlockID.
+	default:
+		return fmt.Errorf("unknown translation entity %q", entityType)
+	}
+	return err
+}
+
+func (s *TranslationService) invalidate(ctx context.Context, entityType, locale string) {
+	switch entityType {
+	case domain.TranslationEntityCategory:
+		if err := s.cache.Delete(ctx, categoryTranslationsCachePrefix+locale); err != nil {
+			s.log.Warn("failed to invalidate category translations cache", zap.Error(err))
+		}
+		// Product lists embed category names.
+		fallthrough
+	case domain.TranslationEntityProduct:
+		if err := s.cache.DeleteByPrefix(ctx, productCachePrefix); err != nil {
+			s.log.Warn("failed to invalidate product cache", zap.Error(err))
+		}
+	case domain.TranslationEntityContentBlock:
+		if err := s.cache.DeleteByPrefix(ctx, contentCachePrefix); err != nil {
+			s.log.Warn("failed to invalidate content cache", zap.Error(err))
+		}
+	}
+}
@@ -53,6 +53,7 @@ type ProfileResponse struct {
 	Role          string  `json:"role"`
 	ReferralCode  string  `json:"referralCode,omitempty"`
 	BonusBalance  float64 `json:"bonusBalance"`
+	CartReminders bool    `json:"cartReminders"`
 	CreatedAt     string  `json:"createdAt"`
 }
 
@@ -69,6 +70,8 @@ type UpdateProfileInput struct {
 	LastName  *string `json:"lastName"`
 	Phone     *string `json:"phone"`
 	Email     *string `json:"email"`
+	// CartReminders turns abandoned cart reminders on or off.
+	CartReminders *bool `json:"cartReminders"`
 }
 
 func (s *UserService) UpdateProfile(ctx context.Context, userID int, input UpdateProfileInput) (*ProfileResponse, error) {
@@ -108,6 +111,9 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID int, input Updat
 	if input.Phone != nil {
 		user.Phone = input.Phone
 	}
+	if input.CartReminders != nil {
+		user.CartRemindersOptOut = !*input.CartReminders
+	}
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, fmt.Errorf("update user: %w", err)
@@ -239,5 +245,6 @@ func userToProfile(u *domain.User) *ProfileResponse {
 		p.ReferralCode = *u.ReferralCode
 	}
 	p.BonusBalance = u.BonusBalance
+	p.CartReminders = !u.CartRemindersOptOut
 	return p
 }
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/brown/3d-print-shop/internal/domain"
+)
+
+// WishlistService manages per-user wishlists.
+type WishlistService struct {
+	repo        domain.WishlistRepository
+	productRepo domain.ProductRepository
+	log         *zap.Logger
+}
+
+// NewWishlistService creates a new wishlist service.
+func NewWishlistService(repo domain.WishlistRepository, productRepo domain.ProductRepository, log *zap.Logger) *WishlistService {
+	return &WishlistService{repo: repo, productRepo: productRepo, log: log}
+}
+
+// List returns the user's wishlist, newest first.
+func (s *WishlistService) List(ctx context.Context, userID int) ([]domain.WishlistItem, error) {
+	return s.repo.ListByUserID(ctx, userID)
+}
+
+// Add puts an active product into the user's wishlist. Adding it twice is a no-op.
+func (s *WishlistService) Add(ctx context.Context, userID, productID int) error {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if !product.IsActive {
+		return domain.ErrProductNotFound
+	}
+
+	item := &domain.WishlistItem{UserID: userID, ProductID: productID}
+	if err := s.repo.Add(ctx, item); err != nil {
+		return fmt.Errorf("add wishlist item: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a product from the user's wishlist.
+func (s *WishlistService) Remove(ctx context.Context, userID, productID int) error {
+	return s.repo.Remove(ctx, userID, productID)
+}
@@ -5,11 +5,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"path"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"go.uber.org/zap"
 
 	"github.com/brown/3d-print-shop/internal/config"
@@ -70,6 +73,61 @@ func (s *S3Client) Upload(ctx context.Context, key string, data []byte, contentT
 	return url, nil
 }
 
+// UploadPrivate uploads data with a private ACL. Such objects are served only
+// through presigned URLs (see PresignGet).
+func (s *S3Client) UploadPrivate(ctx context.Context, key string, reader io.Reader, contentType string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("read upload data: %w", err)
+	}
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+		ACL:         types.ObjectCannedACLPrivate,
+	})
+	if err != nil {
+		return fmt.Errorf("s3 put private object %q: %w", key, err)
+	}
+
+	s.log.Debug("s3 uploaded private", zap.String("key", key), zap.Int("size", len(data)))
+	return nil
+}
+
+// Download reads a whole object from S3.
+func (s *S3Client) Download(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 get object %q: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("s3 read object %q: %w", key, err)
+	}
+	return data, nil
+}
+
+// PresignGet returns a temporary URL for downloading a private object.
+// fileName is sent as the attachment name in Content-Disposition.
+func (s *S3Client) PresignGet(ctx context.Context, key, fileName string, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(s.client)
+	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String("attachment; filename*=UTF-8''" + url.PathEscape(fileName)),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("s3 presign %q: %w", key, err)
+	}
+	return req.URL, nil
+}
+
 // Delete removes a single object from S3.
 func (s *S3Client) Delete(ctx context.Context, key string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -3,6 +3,8 @@ package telegram
 import (
 	"context"
 	"fmt"
+	"html"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -55,7 +57,11 @@ func (b *Bot) NotifyOrderStatusChanged(ctx context.Context, order *domain.Order)
 	case "delivered":
 		text = fmt.Sprintf("\U0001F389 <b>Заказ %s доставлен!</b>\n\nСпасибо за покупку! Будем рады видеть вас снова.", order.OrderNumber)
 	case "cancelled":
-		text = fmt.Sprintf("\u274C <b>Заказ %s отменён</b>\n\nЕсли у вас есть вопросы, свяжитесь с нами через /help.", order.OrderNumber)
+		text = fmt.Sprintf("\u274C <b>Заказ %s отменён</b>", order.OrderNumber)
+		if order.CancelReason != nil && *order.CancelReason != "" {
+			text += fmt.Sprintf("\n\nПричина: %s", html.EscapeString(*order.CancelReason))
+		}
+		text += "\n\nЕсли у вас есть вопросы, свяжитесь с нами через /help."
 	default:
 		return nil
 	}
@@ -65,6 +71,87 @@ func (b *Bot) NotifyOrderStatusChanged(ctx context.Context, order *domain.Order)
 	return nil
 }
 
+// NotifyDigitalDownloads sends download links of a paid digital order to the customer.
+func (b *Bot) NotifyDigitalDownloads(ctx context.Context, order *domain.Order, links []domain.DownloadLink) error {
+	chatID, err := b.resolveUserChatID(ctx, order)
+	if err != nil {
+		return err
+	}
+	if chatID == 0 || len(links) == 0 {
+		return nil
+	}
+
+	text := fmt.Sprintf("\U0001F4E5 <b>Файлы заказа %s готовы!</b>\n", order.OrderNumber)
+	for _, l := range links {
+		name := "Файл"
+		if l.File != nil {
+			name = l.File.FileName
+		}
+		if l.Product != nil {
+			name = l.Product.Name + " — " + name
+		}
+		text += fmt.Sprintf("\n<a href=\"%s\">%s</a>", l.URL, html.EscapeString(name))
+	}
+	text += fmt.Sprintf("\n\nКаждую ссылку можно использовать до %d раз до %s. Ссылки также есть в личном кабинете в разделе «Мои загрузки».",
+		links[0].MaxDownloads, links[0].ExpiresAt.Format("02.01.2006 15:04"))
+
+	b.send(chatID, text)
+	b.log.Info("sent digital downloads notification", zap.String("order", order.OrderNumber), zap.Int64("chatID", chatID))
+	return nil
+}
+
+// NotifyBackInStock tells a subscriber that a product is available again.
+func (b *Bot) NotifyBackInStock(ctx context.Context, telegramID int64, product *domain.Product) error {
+	if telegramID == 0 {
+		return nil
+	}
+
+	text := fmt.Sprintf(
+		"\U0001F514 <b>Снова в наличии!</b>\n\n%s — %s\n\n<a href=\"%s/product/%s\">Перейти к товару</a>",
+		html.EscapeString(product.Name),
+		formatPrice(product.Price),
+		b.webAppURL,
+		product.Slug,
+	)
+
+	b.send(telegramID, text)
+	b.log.Info("sent back-in-stock notification", zap.Int("productID", product.ID), zap.Int64("chatID", telegramID))
+	return nil
+}
+
+// NotifyAbandonedCart reminds a customer about the products left in the cart.
+func (b *Bot) NotifyAbandonedCart(ctx context.Context, telegramID int64, msg *domain.CartReminderMessage) error {
+	if telegramID == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\U0001F6D2 <b>Вы кое-что забыли в корзине</b>\n\n")
+	for _, item := range msg.Items {
+		fmt.Fprintf(&sb, "• %s × %d\n", html.EscapeString(item.Product.Name), item.Quantity)
+	}
+	fmt.Fprintf(&sb, "\nСумма: <b>%s</b>", formatPrice(msg.Total))
+	if p := msg.Promo; p != nil {
+		discount := formatPrice(p.DiscountValue)
+		if p.DiscountType == "percent" {
+			discount = fmt.Sprintf("%g%%", p.DiscountValue)
+		}
+		fmt.Fprintf(&sb, "\n\n\U0001F381 Ваш промокод на %s: <code>%s</code>", discount, html.EscapeString(p.Code))
+		if p.ExpiresAt != nil {
+			fmt.Fprintf(&sb, "\nДействует до %s, один раз.", p.ExpiresAt.Format("02.01.2006 15:04"))
+		}
+	}
+
+	b.sendWithKeyboard(telegramID, sb.String(), inlineKeyboard{
+		InlineKeyboard: [][]inlineButton{
+			{{Text: "Вернуться в корзину", URL: msg.CartURL}},
+			{{Text: "Не напоминать", URL: msg.UnsubscribeURL}},
+		},
+	})
+	b.log.Info("sent cart reminder", zap.Int64("chatID", telegramID))
+	return nil
+}
+
 // resolveUserChatID finds the Telegram chat ID for the order's user.
 func (b *Bot) resolveUserChatID(ctx context.Context, order *domain.Order) (int64, error) {
 	if order.UserID == nil {
@@ -133,6 +220,8 @@ func deliveryMethodText(method string) string {
 		return "Самовывоз"
 	case "courier":
 		return "Курьер"
+	case domain.DeliveryMethodDigital:
+		return "Скачивание файлов"
 	default:
 		return method
 	}
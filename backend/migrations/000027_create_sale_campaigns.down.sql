@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS sale_campaign_items;
+DROP TABLE IF EXISTS sale_campaigns;
@@ -0,0 +1,50 @@
+CREATE TABLE sale_campaigns (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percent', 'fixed')),
+    discount_value DECIMAL(10,2) NOT NULL CHECK (discount_value > 0),
+    product_ids JSONB NOT NULL DEFAULT '[]',
+    category_ids JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'active', 'finished', 'cancelled')),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    activated_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sale_campaigns_status ON sale_campaigns(status);
+CREATE INDEX idx_sale_campaigns_starts_at ON sale_campaigns(starts_at);
+
+-- Цены товаров до запуска кампании: по ним восстанавливаем Price/OldPrice при завершении.
+CREATE TABLE sale_campaign_items (
+    id SERIAL PRIMARY KEY,
+    campaign_id INTEGER NOT NULL REFERENCES sale_campaigns(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    original_price DECIMAL(10,2) NOT NULL,
+    original_old_price DECIMAL(10,2),
+    sale_price DECIMAL(10,2) NOT NULL,
+    restored_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (campaign_id, product_id)
+);
+
+CREATE INDEX idx_sale_campaign_items_product_id ON sale_campaign_items(product_id);
+
+CREATE TABLE price_history (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    old_price DECIMAL(10,2),
+    new_price DECIMAL(10,2) NOT NULL,
+    old_old_price DECIMAL(10,2),
+    new_old_price DECIMAL(10,2),
+    reason VARCHAR(30) NOT NULL,
+    campaign_id INTEGER REFERENCES sale_campaigns(id) ON DELETE SET NULL,
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_price_history_product_id ON price_history(product_id, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE orders ALTER COLUMN estimated_delivery TYPE VARCHAR(50);
+ALTER TABLE order_items DROP COLUMN IF EXISTS production_quantity;
+ALTER TABLE products DROP COLUMN IF EXISTS fulfillment_mode;
@@ -0,0 +1,7 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS fulfillment_mode VARCHAR(20) NOT NULL DEFAULT 'in_stock'
+    CHECK (fulfillment_mode IN ('in_stock', 'made_to_order', 'hybrid'));
+
+-- Сколько единиц позиции нужно напечатать (не взято со склада) — из этого считается очередь печати.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS production_quantity INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ALTER COLUMN estimated_delivery TYPE VARCHAR(255);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS download_links;
+DROP TABLE IF EXISTS product_files;
+ALTER TABLE products DROP COLUMN IF EXISTS is_digital;
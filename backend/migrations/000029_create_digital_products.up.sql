@@ -0,0 +1,31 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_digital BOOLEAN NOT NULL DEFAULT false;
+
+-- Файлы цифровых товаров хранятся в S3 приватно, наружу отдаются только через download_links.
+CREATE TABLE product_files (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    s3_key VARCHAR(500) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_files_product_id ON product_files(product_id);
+
+CREATE TABLE download_links (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) UNIQUE NOT NULL,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_file_id INTEGER NOT NULL REFERENCES product_files(id) ON DELETE CASCADE,
+    download_count INTEGER NOT NULL DEFAULT 0,
+    max_downloads INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_downloaded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (order_id, product_file_id)
+);
+
+CREATE INDEX idx_download_links_user_id ON download_links(user_id);
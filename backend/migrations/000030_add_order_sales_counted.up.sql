@@ -0,0 +1,18 @@
+-- Флаг: количества позиций заказа уже учтены в products.sales_count (при оплате или доставке).
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS sales_counted BOOLEAN NOT NULL DEFAULT false;
+
+-- Уже доставленные и оплаченные заказы считаем проданными.
+UPDATE products p SET sales_count = oi.qty
+FROM (
+    SELECT oi.product_id, SUM(oi.quantity) AS qty
+    FROM order_items oi
+    JOIN orders o ON o.id = oi.order_id
+    WHERE oi.product_id IS NOT NULL
+      AND o.status <> 'cancelled'
+      AND (o.is_paid = true OR o.status = 'delivered')
+    GROUP BY oi.product_id
+) oi
+WHERE p.id = oi.product_id;
+
+UPDATE orders SET sales_counted = true
+WHERE status <> 'cancelled' AND (is_paid = true OR status = 'delivered');
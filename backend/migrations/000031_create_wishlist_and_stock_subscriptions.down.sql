@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_subscriptions;
+DROP TABLE IF EXISTS wishlist_items;
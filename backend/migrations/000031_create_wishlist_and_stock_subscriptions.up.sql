@@ -0,0 +1,28 @@
+CREATE TABLE wishlist_items (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, product_id)
+);
+
+CREATE INDEX idx_wishlist_items_user_id ON wishlist_items(user_id);
+
+-- Подписки «сообщить о поступлении». Удаляются после отправки уведомления.
+CREATE TABLE stock_subscriptions (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('email', 'telegram')),
+    email VARCHAR(255),
+    telegram_id BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (
+        (channel = 'email' AND email IS NOT NULL) OR
+        (channel = 'telegram' AND telegram_id IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_stock_subscriptions_product_id ON stock_subscriptions(product_id);
+CREATE UNIQUE INDEX idx_stock_subscriptions_email ON stock_subscriptions(product_id, LOWER(email)) WHERE channel = 'email';
+CREATE UNIQUE INDEX idx_stock_subscriptions_telegram ON stock_subscriptions(product_id, telegram_id) WHERE channel = 'telegram';
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS h1,
+    DROP COLUMN IF EXISTS meta_description,
+    DROP COLUMN IF EXISTS meta_title;
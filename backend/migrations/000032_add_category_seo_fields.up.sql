@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    ADD COLUMN meta_title VARCHAR(255),
+    ADD COLUMN meta_description TEXT,
+    ADD COLUMN h1 VARCHAR(255);
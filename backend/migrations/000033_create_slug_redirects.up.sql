@@ -0,0 +1,13 @@
+-- Старые slug товаров и категорий: по ним отдаём 301 на актуальный адрес.
+CREATE TABLE slug_redirects (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('product', 'category')),
+    entity_id INTEGER NOT NULL,
+    old_slug VARCHAR(255) NOT NULL,
+    hits INTEGER NOT NULL DEFAULT 0,
+    last_hit_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_type, old_slug)
+);
+
+CREATE INDEX idx_slug_redirects_entity ON slug_redirects(entity_type, entity_id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_product_images_status;
+
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS processed_at,
+    DROP COLUMN IF EXISTS processing_started_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS preset_signature,
+    DROP COLUMN IF EXISTS variants,
+    DROP COLUMN IF EXISTS url_webp,
+    DROP COLUMN IF EXISTS source_key,
+    DROP COLUMN IF EXISTS error,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,16 @@
+-- Фоновая обработка изображений: исходник хранится в S3, варианты
+-- (JPEG/PNG + WebP) генерируются воркером по пресетам размеров.
+-- Уже загруженные изображения считаются готовыми.
+ALTER TABLE product_images
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'ready'
+        CHECK (status IN ('pending', 'processing', 'ready', 'failed')),
+    ADD COLUMN error TEXT,
+    ADD COLUMN source_key TEXT,
+    ADD COLUMN url_webp TEXT,
+    ADD COLUMN variants JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN preset_signature VARCHAR(255),
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN processing_started_at TIMESTAMP,
+    ADD COLUMN processed_at TIMESTAMP;
+
+CREATE INDEX idx_product_images_status ON product_images(status) WHERE status <> 'ready';
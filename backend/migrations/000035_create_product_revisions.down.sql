@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_products_inactive_updated_at;
+DROP TABLE IF EXISTS product_revisions;
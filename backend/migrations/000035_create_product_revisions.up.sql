@@ -0,0 +1,17 @@
+-- История правок товара: снимок полей после каждого изменения и список
+-- изменённых полей со старыми и новыми значениями.
+CREATE TABLE product_revisions (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore')),
+    changes JSONB NOT NULL DEFAULT '[]',
+    snapshot JSONB NOT NULL,
+    restored_from INTEGER,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, revision)
+);
+
+-- Корзина: неактивные товары по времени удаления.
+CREATE INDEX idx_products_inactive_updated_at ON products(updated_at DESC) WHERE is_active = false;
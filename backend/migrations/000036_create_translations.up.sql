@@ -0,0 +1,15 @@
+-- Переводы каталога. Для товаров и категорий переводятся отдельные текстовые
+-- поля (fields), для контентных блоков — весь JSON блока (data).
+-- Если перевода нет, отдаётся значение на языке по умолчанию.
+CREATE TABLE translations (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('product', 'category', 'content_block')),
+    entity_id INTEGER NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    fields JSONB NOT NULL DEFAULT '{}',
+    data JSONB,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_type, entity_id, locale)
+);
+
+CREATE INDEX idx_translations_locale ON translations(entity_type, locale);
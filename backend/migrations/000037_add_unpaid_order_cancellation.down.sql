@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_settings;
+DROP INDEX IF EXISTS idx_orders_unpaid_expiry;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancel_reason;
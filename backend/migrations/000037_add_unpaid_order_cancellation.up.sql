@@ -0,0 +1,19 @@
+-- Автоотмена неоплаченных заказов с оплатой картой.
+ALTER TABLE orders
+    ADD COLUMN cancel_reason TEXT,
+    ADD COLUMN cancelled_at TIMESTAMP;
+
+-- Поиск заказов с истёкшей ссылкой на оплату.
+CREATE INDEX idx_orders_unpaid_expiry ON orders(payment_expires_at)
+    WHERE payment_method = 'card' AND is_paid = false AND status = 'new';
+
+-- Настройки заказов (одна строка). unpaid_grace_minutes — сколько ждать
+-- после истечения ссылки на оплату, прежде чем отменить заказ.
+CREATE TABLE order_settings (
+    id INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    unpaid_cancel_enabled BOOLEAN NOT NULL DEFAULT true,
+    unpaid_grace_minutes INTEGER NOT NULL DEFAULT 30 CHECK (unpaid_grace_minutes >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO order_settings DEFAULT VALUES;
@@ -0,0 +1,2 @@
+ALTER TABLE orders ALTER COLUMN order_number TYPE VARCHAR(20);
+DROP TABLE IF EXISTS order_number_counters;
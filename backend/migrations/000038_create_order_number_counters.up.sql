@@ -0,0 +1,17 @@
+-- Счётчики номеров заказов: отдельная серия на каждый день,
+-- обычные и индивидуальные заказы нумеруются независимо.
+CREATE TABLE order_number_counters (
+    series VARCHAR(20) NOT NULL,
+    day DATE NOT NULL,
+    last_value INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (series, day)
+);
+
+-- Префикс и контрольная цифра делают номер длиннее.
+ALTER TABLE orders ALTER COLUMN order_number TYPE VARCHAR(32);
+
+-- Продолжаем сегодняшнюю нумерацию, чтобы не выдать уже занятый номер.
+INSERT INTO order_number_counters (series, day, last_value)
+SELECT 'regular', CURRENT_DATE, COALESCE(MAX(CAST(split_part(order_number, '-', 3) AS INTEGER)), 0)
+FROM orders
+WHERE order_number ~ ('^ORD-' || to_char(CURRENT_DATE, 'YYYYMMDD') || '-[0-9]+$');
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_cart_items_user_updated;
+ALTER TABLE users DROP COLUMN IF EXISTS cart_reminders_opt_out;
+DROP TABLE IF EXISTS cart_reminders;
+DROP TABLE IF EXISTS cart_reminder_campaigns;
@@ -0,0 +1,40 @@
+-- Напоминания о брошенных корзинах.
+-- Кампания задаёт, через сколько часов после последнего изменения корзины
+-- отправить напоминание и нужен ли одноразовый промокод.
+CREATE TABLE cart_reminder_campaigns (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    delay_hours INTEGER NOT NULL CHECK (delay_hours > 0),
+    min_cart_total DECIMAL(10,2) NOT NULL DEFAULT 0,
+    promo_discount_type VARCHAR(20) CHECK (promo_discount_type IN ('percent', 'fixed')),
+    promo_discount_value DECIMAL(10,2),
+    promo_valid_hours INTEGER NOT NULL DEFAULT 72 CHECK (promo_valid_hours > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Отправленные напоминания. token — ссылка для возврата в корзину,
+-- по ней считаются переходы; converted_at — заказ после напоминания.
+CREATE TABLE cart_reminders (
+    id SERIAL PRIMARY KEY,
+    campaign_id INTEGER REFERENCES cart_reminder_campaigns(id) ON DELETE SET NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL CHECK (channel IN ('telegram', 'email')),
+    token VARCHAR(64) NOT NULL UNIQUE,
+    cart_total DECIMAL(10,2) NOT NULL DEFAULT 0,
+    promo_code_id INTEGER REFERENCES promo_codes(id) ON DELETE SET NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    clicked_at TIMESTAMP,
+    converted_at TIMESTAMP,
+    order_id INTEGER REFERENCES orders(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_cart_reminders_user ON cart_reminders(user_id, sent_at DESC);
+CREATE INDEX idx_cart_reminders_campaign ON cart_reminders(campaign_id);
+
+-- Отказ пользователя от напоминаний о корзине.
+ALTER TABLE users ADD COLUMN cart_reminders_opt_out BOOLEAN NOT NULL DEFAULT false;
+
+-- Корзины ищутся по дате последнего изменения.
+CREATE INDEX idx_cart_items_user_updated ON cart_items(user_id, updated_at);
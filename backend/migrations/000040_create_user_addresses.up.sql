@@ -0,0 +1,19 @@
+-- Адресная книга покупателя: адреса курьерской доставки и предпочитаемые
+-- пункты выдачи с контактами получателя. Для каждого типа один адрес по умолчанию.
+CREATE TABLE user_addresses (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('courier', 'pickup_point')),
+    label VARCHAR(100),
+    recipient_name VARCHAR(255),
+    phone VARCHAR(50),
+    city VARCHAR(255),
+    address TEXT,
+    pickup_point_id INTEGER REFERENCES pickup_points(id) ON DELETE SET NULL,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_addresses_user ON user_addresses(user_id);
+CREATE UNIQUE INDEX idx_user_addresses_default ON user_addresses(user_id, type) WHERE is_default;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_changes;
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_amount;
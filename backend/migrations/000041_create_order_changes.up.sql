@@ -0,0 +1,21 @@
+-- Сколько покупатель уже заплатил по заказу. После правки заказа сумма
+-- может разойтись с total_price: тогда выставляется доплата или делается возврат.
+ALTER TABLE orders ADD COLUMN paid_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+UPDATE orders SET paid_amount = total_price WHERE is_paid = true;
+
+-- Журнал правок заказов администратором: изменённые поля со старыми и новыми
+-- значениями и выставленная доплата или возврат.
+CREATE TABLE order_changes (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    old_total DECIMAL(10,2) NOT NULL,
+    new_total DECIMAL(10,2) NOT NULL,
+    payment_action VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (payment_action IN ('none', 'payment_link', 'refund')),
+    payment_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    comment TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_changes_order_id ON order_changes(order_id, created_at DESC);
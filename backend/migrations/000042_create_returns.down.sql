@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS return_requests;
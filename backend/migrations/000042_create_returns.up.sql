@@ -0,0 +1,45 @@
+-- Заявки на возврат товаров из доставленных заказов.
+-- requested → approved | rejected; approved → shipped (покупатель отправил
+-- товар) → received (товар осмотрен) → refunded.
+CREATE TABLE return_requests (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested'
+        CHECK (status IN ('requested', 'approved', 'rejected', 'shipped', 'received', 'refunded')),
+    reason VARCHAR(30) NOT NULL
+        CHECK (reason IN ('defect', 'damaged', 'wrong_item', 'not_as_described', 'changed_mind', 'other')),
+    comment TEXT,
+    photo_urls JSONB NOT NULL DEFAULT '[]',
+    admin_comment TEXT,
+    -- Обратная отправка
+    return_tracking_number VARCHAR(100),
+    shipped_at TIMESTAMP,
+    -- Осмотр
+    inspection_notes TEXT,
+    received_at TIMESTAMP,
+    -- Возврат денег: на карту через платёжного провайдера или бонусами
+    refund_method VARCHAR(20) CHECK (refund_method IN ('payment', 'bonuses')),
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    refunded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_return_requests_order_id ON return_requests(order_id);
+CREATE INDEX idx_return_requests_user_id ON return_requests(user_id);
+CREATE INDEX idx_return_requests_status ON return_requests(status, created_at DESC);
+
+-- Возвращаемые позиции заказа. condition — результат осмотра:
+-- resellable возвращается на склад.
+CREATE TABLE return_items (
+    id SERIAL PRIMARY KEY,
+    return_id INTEGER NOT NULL REFERENCES return_requests(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    condition VARCHAR(20) CHECK (condition IN ('resellable', 'damaged', 'defective')),
+    restocked BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX idx_return_items_return_id ON return_items(return_id);
+CREATE INDEX idx_return_items_order_item_id ON return_items(order_item_id);
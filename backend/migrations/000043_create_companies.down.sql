@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_company_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_reference;
+ALTER TABLE orders DROP COLUMN IF EXISTS company_id;
+DROP TABLE IF EXISTS companies;
@@ -0,0 +1,23 @@
+-- Реквизиты юрлица или ИП, от имени которого пользователь оформляет заказы.
+-- ИНН из 10 цифр — организация (КПП обязателен, ОГРН 13 цифр),
+-- из 12 цифр — ИП (без КПП, ОГРНИП 15 цифр).
+CREATE TABLE companies (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    legal_name VARCHAR(255) NOT NULL,
+    inn VARCHAR(12) NOT NULL,
+    kpp VARCHAR(9),
+    ogrn VARCHAR(15) NOT NULL,
+    legal_address TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_companies_inn ON companies(inn);
+
+-- Заказ юрлица и оплата по счёту: payment_method = 'invoice',
+-- payment_reference — номер платёжного поручения, по которому пришла оплата.
+ALTER TABLE orders ADD COLUMN company_id INTEGER REFERENCES companies(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN payment_reference VARCHAR(100);
+
+CREATE INDEX idx_orders_company_id ON orders(company_id) WHERE company_id IS NOT NULL;
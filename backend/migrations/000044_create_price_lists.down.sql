@@ -0,0 +1,5 @@
+ALTER TABLE companies DROP COLUMN IF EXISTS price_list_id;
+ALTER TABLE users DROP COLUMN IF EXISTS price_list_id;
+DROP TABLE IF EXISTS price_list_items;
+DROP TABLE IF EXISTS price_lists;
+DROP TABLE IF EXISTS product_price_tiers;
@@ -0,0 +1,37 @@
+-- Скидки за количество: цена за единицу при заказе от min_quantity штук.
+-- Для меньших количеств действует products.price.
+CREATE TABLE product_price_tiers (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    min_quantity INTEGER NOT NULL CHECK (min_quantity > 1),
+    price DECIMAL(10,2) NOT NULL CHECK (price > 0),
+    UNIQUE (product_id, min_quantity)
+);
+
+-- Прайс-листы (оптовый, партнёрский), назначаемые пользователям и компаниям.
+-- discount_percent — скидка от розничной цены на товары, которых нет в price_list_items.
+CREATE TABLE price_lists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent < 100),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Цены прайс-листа на конкретные товары, тоже с порогами по количеству.
+CREATE TABLE price_list_items (
+    id SERIAL PRIMARY KEY,
+    price_list_id INTEGER NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    min_quantity INTEGER NOT NULL DEFAULT 1 CHECK (min_quantity > 0),
+    price DECIMAL(10,2) NOT NULL CHECK (price > 0),
+    UNIQUE (price_list_id, product_id, min_quantity)
+);
+
+CREATE INDEX idx_price_list_items_product_id ON price_list_items(product_id);
+
+-- Прайс-лист пользователя важнее прайс-листа его компании.
+ALTER TABLE users ADD COLUMN price_list_id INTEGER REFERENCES price_lists(id) ON DELETE SET NULL;
+ALTER TABLE companies ADD COLUMN price_list_id INTEGER REFERENCES price_lists(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_quantity_check;
+DROP TABLE IF EXISTS stock_movements;
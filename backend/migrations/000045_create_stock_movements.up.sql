@@ -0,0 +1,30 @@
+-- Журнал движения товара. products.stock_quantity — сумма движений товара,
+-- balance — остаток сразу после движения. quantity положительное для прихода,
+-- отрицательное для расхода.
+CREATE TABLE stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL
+        CHECK (type IN ('initial', 'receipt', 'sale', 'cancellation', 'return', 'adjustment', 'production')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    balance INTEGER NOT NULL CHECK (balance >= 0),
+    order_id INTEGER REFERENCES orders(id) ON DELETE SET NULL,
+    return_id INTEGER REFERENCES return_requests(id) ON DELETE SET NULL,
+    reference VARCHAR(100),
+    comment TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, id DESC);
+CREATE INDEX idx_stock_movements_order_id ON stock_movements(order_id) WHERE order_id IS NOT NULL;
+
+-- Остатки на момент запуска журнала — входящее сальдо.
+INSERT INTO stock_movements (product_id, type, quantity, balance, comment)
+SELECT id, 'initial', stock_quantity, stock_quantity, 'Остаток на момент запуска журнала'
+FROM products
+WHERE stock_quantity > 0;
+
+-- Остаток меняется только через журнал и не бывает отрицательным.
+UPDATE products SET stock_quantity = 0 WHERE stock_quantity < 0;
+ALTER TABLE products ADD CONSTRAINT products_stock_quantity_check CHECK (stock_quantity >= 0);
@@ -0,0 +1,5 @@
+UPDATE return_requests SET status = 'received' WHERE status = 'refunding';
+
+ALTER TABLE return_requests DROP CONSTRAINT IF EXISTS return_requests_status_check;
+ALTER TABLE return_requests ADD CONSTRAINT return_requests_status_check
+    CHECK (status IN ('requested', 'approved', 'rejected', 'shipped', 'received', 'refunded'));
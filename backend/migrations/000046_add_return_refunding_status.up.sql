@@ -0,0 +1,6 @@
+-- refunding — возврат на карту отправлен платёжному провайдеру, но ещё не
+-- записан. Заявка в этом статусе не может быть возвращена повторно; если
+-- запись не удалась, её закрывает сотрудник вручную.
+ALTER TABLE return_requests DROP CONSTRAINT IF EXISTS return_requests_status_check;
+ALTER TABLE return_requests ADD CONSTRAINT return_requests_status_check
+    CHECK (status IN ('requested', 'approved', 'rejected', 'shipped', 'received', 'refunding', 'refunded'));
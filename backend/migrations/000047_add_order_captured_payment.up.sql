@@ -0,0 +1,7 @@
+-- Платёж, которым заказ был оплачен. payment_provider_id указывает на последнюю
+-- выданную ссылку (после правки заказа — на доплату), а возврат делается по
+-- исходному платежу.
+ALTER TABLE orders ADD COLUMN captured_payment_id VARCHAR(100);
+
+UPDATE orders SET captured_payment_id = payment_provider_id
+WHERE paid_amount > 0 AND payment_method = 'card' AND payment_provider_id IS NOT NULL;
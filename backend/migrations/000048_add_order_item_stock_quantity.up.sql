@@ -0,0 +1,8 @@
+-- Сколько единиц позиции взято со склада. Столько же возвращается при отмене
+-- и учитывается при правке заказа; цифровые товары и печать под заказ склад
+-- не расходуют.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS stock_quantity INTEGER NOT NULL DEFAULT 0;
+
+UPDATE order_items oi SET stock_quantity = GREATEST(oi.quantity - oi.production_quantity, 0)
+FROM products p
+WHERE p.id = oi.product_id AND p.is_digital = false;
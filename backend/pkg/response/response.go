@@ -30,6 +30,16 @@ type paginatedResponse struct {
 	Meta PaginationMeta `json:"meta"`
 }
 
+type redirectResponse struct {
+	Redirect RedirectHint `json:"redirect"`
+}
+
+// RedirectHint tells the client where a resource has moved.
+type RedirectHint struct {
+	Slug   string `json:"slug"`
+	Status int    `json:"status"`
+}
+
 type PaginationMeta struct {
 	Page       int   `json:"page"`
 	Limit      int   `json:"limit"`
@@ -53,6 +63,15 @@ func Paginated(c *gin.Context, data interface{}, meta PaginationMeta) {
 	c.JSON(http.StatusOK, paginatedResponse{Data: data, Meta: meta})
 }
 
+// MovedPermanently responds with 301, a Location header and a redirect hint the
+// frontend can follow without parsing the header.
+func MovedPermanently(c *gin.Context, location, slug string) {
+	c.Header("Location", location)
+	c.JSON(http.StatusMovedPermanently, redirectResponse{
+		Redirect: RedirectHint{Slug: slug, Status: http.StatusMovedPermanently},
+	})
+}
+
 func Error(c *gin.Context, status int, code string, message string) {
 	c.JSON(status, errorResponse{
 		Error: ErrorBody{Code: code, Message: message},
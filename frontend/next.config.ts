@@ -1,6 +1,19 @@
 import type { NextConfig } from "next";
 
+// Backend origin without the /api/v1 suffix
+const apiOrigin = (
+  process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080/api/v1"
+).replace(/\/api\/v1\/?$/, "");
+
 const nextConfig: NextConfig = {
+  // sitemap.xml and robots.txt are generated by the backend from catalog data
+  async rewrites() {
+    return [
+      { source: "/sitemap.xml", destination: `${apiOrigin}/sitemap.xml` },
+      { source: "/sitemaps/:file", destination: `${apiOrigin}/sitemaps/:file` },
+      { source: "/robots.txt", destination: `${apiOrigin}/robots.txt` },
+    ];
+  },
   images: {
     remotePatterns: [
       {
@@ -1,6 +1,10 @@
 import type { Metadata } from "next";
-import { notFound } from "next/navigation";
-import { getProductBySlug } from "@/lib/api";
+import { notFound, permanentRedirect } from "next/navigation";
+import {
+  getMovedSlug,
+  getProductBySlug,
+  getProductStructuredData,
+} from "@/lib/api";
 import { Breadcrumbs } from "@/components/product/Breadcrumbs";
 import { ProductDetailContent } from "@/components/product/ProductDetailContent";
 
@@ -38,14 +42,31 @@ export default async function ProductPage({ params }: Props) {
   const { slug } = await params;
 
   let product;
+  let movedTo: string | null = null;
   try {
     product = await getProductBySlug(slug);
-  } catch {
-    notFound();
+  } catch (err) {
+    movedTo = getMovedSlug(err);
+    if (!movedTo) notFound();
   }
+  // Renamed product: old links from ads and search engines get a 308
+  if (movedTo) permanentRedirect(`/product/${movedTo}`);
+
+  // JSON-LD is optional: the page must render even if it fails
+  const structuredData = await getProductStructuredData(slug).catch(
+    () => null
+  );
 
   return (
     <div className="mx-auto max-w-7xl px-4 py-8">
+      {structuredData && (
+        <script
+          type="application/ld+json"
+          dangerouslySetInnerHTML={{
+            __html: JSON.stringify(structuredData).replace(/</g, "\\u003c"),
+          }}
+        />
+      )}
       <Breadcrumbs product={product} />
       <ProductDetailContent product={product} />
     </div>
@@ -97,7 +97,26 @@ export async function getProducts(params?: {
 }
 
 export async function getProductBySlug(slug: string): Promise<Product> {
-  const { data } = await api.get<ApiResponse<Product>>(`/products/${slug}`);
+  // Old slugs answer 301 with the new slug; don't follow, let the page redirect
+  const { data } = await api.get<ApiResponse<Product>>(`/products/${slug}`, {
+    maxRedirects: 0,
+  });
+  return data.data;
+}
+
+// getMovedSlug returns the new slug if the request failed with a 301 redirect hint.
+export function getMovedSlug(err: unknown): string | null {
+  if (!axios.isAxiosError(err) || err.response?.status !== 301) return null;
+  const data = err.response.data as { redirect?: { slug?: string } };
+  return data?.redirect?.slug || null;
+}
+
+export async function getProductStructuredData(
+  slug: string
+): Promise<Record<string, unknown>> {
+  const { data } = await api.get<ApiResponse<Record<string, unknown>>>(
+    `/products/${slug}/structured-data`
+  );
   return data.data;
 }
 